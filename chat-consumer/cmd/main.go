@@ -10,11 +10,13 @@ import (
 
 	"github.com/joho/godotenv"
 
+	"github.com/kunal768/cmpe202/chat-consumer/internal/attachment"
 	"github.com/kunal768/cmpe202/chat-consumer/internal/config"
 	"github.com/kunal768/cmpe202/chat-consumer/internal/consumer"
 	"github.com/kunal768/cmpe202/chat-consumer/internal/delivery"
 	"github.com/kunal768/cmpe202/chat-consumer/internal/presence"
 	"github.com/kunal768/cmpe202/chat-consumer/internal/storage"
+	"github.com/kunal768/cmpe202/http-lib/screening"
 )
 
 func main() {
@@ -60,6 +62,35 @@ func main() {
 		}
 	}()
 
+	// Initialize the notifications publisher (optional)
+	var notifier delivery.NotificationPublisher
+	if cfg.NotificationsQueueName != "" {
+		notificationPublisher, err := delivery.NewRabbitMQNotificationPublisher(cfg.RabbitMQURL, cfg.NotificationsQueueName)
+		if err != nil {
+			log.Fatalf("Failed to initialize notification publisher: %v", err)
+		}
+		defer func() {
+			if err := notificationPublisher.Close(); err != nil {
+				log.Printf("Error closing notification publisher: %v", err)
+			}
+		}()
+		notifier = notificationPublisher
+	}
+
+	// Content screening rules come from the same SCREEN_* variables as listing-service
+	screener, err := screening.FromEnv()
+	if err != nil {
+		log.Fatalf("Invalid content screening config: %v", err)
+	}
+
+	// Image messages are checked against the blob store (optional)
+	var attachments attachment.Inspector
+	if cfg.AttachmentBaseURL != "" {
+		attachments = attachment.NewHTTPInspector(cfg.AttachmentBaseURL, cfg.AttachmentPublicBaseURL)
+	} else {
+		log.Println("ATTACHMENT_BASE_URL not set; image messages will be rejected")
+	}
+
 	// Initialize RabbitMQ consumer
 	log.Println("Connecting to RabbitMQ...")
 	messageConsumer, err := consumer.NewMessageConsumer(
@@ -68,6 +99,9 @@ func main() {
 		messageRepo,
 		presenceChecker,
 		messagePublisher,
+		notifier,
+		screener,
+		attachments,
 	)
 	if err != nil {
 		log.Fatalf("Failed to initialize message consumer: %v", err)
@@ -2,8 +2,11 @@ module github.com/kunal768/cmpe202/chat-consumer
 
 go 1.23.0
 
+replace github.com/kunal768/cmpe202/http-lib => ../http-lib
+
 require (
 	github.com/joho/godotenv v1.5.1
+	github.com/kunal768/cmpe202/http-lib v0.0.0
 	github.com/rabbitmq/amqp091-go v1.10.0
 	github.com/redis/go-redis/v9 v9.14.1
 	go.mongodb.org/mongo-driver v1.14.0
@@ -0,0 +1,125 @@
+package attachment
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // register decoders for image.DecodeConfig
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/kunal768/cmpe202/chat-consumer/internal/models"
+)
+
+const (
+	// MaxSize matches the limit listing-service puts on chat upload URLs
+	MaxSize = 10 << 20 // 10 MB
+	// MaxDimension bounds either side of an image in pixels
+	MaxDimension = 8192
+)
+
+// allowedTypes are the content types chat accepts, as sniffed from the blob itself
+var allowedTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+var fileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]*$`)
+
+// ErrInvalid is returned for attachments that must not be sent: not the sender's upload,
+// missing, too large, or not an accepted image
+var ErrInvalid = errors.New("invalid attachment")
+
+// Inspector checks an uploaded attachment and describes it
+type Inspector interface {
+	Inspect(ctx context.Context, senderID, blobName string) (*models.Attachment, error)
+}
+
+// HTTPInspector downloads attachments from the blob store: the Azure container URL, or
+// the listing-service /blobs endpoint in development
+type HTTPInspector struct {
+	baseURL       string // where the consumer fetches blobs
+	publicBaseURL string // what clients are given
+	client        *http.Client
+}
+
+// NewHTTPInspector fetches blobs from baseURL and links them under publicBaseURL
+// (baseURL if empty)
+func NewHTTPInspector(baseURL, publicBaseURL string) *HTTPInspector {
+	if publicBaseURL == "" {
+		publicBaseURL = baseURL
+	}
+	return &HTTPInspector{
+		baseURL:       strings.TrimRight(baseURL, "/"),
+		publicBaseURL: strings.TrimRight(publicBaseURL, "/"),
+		client:        &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Inspect downloads the blob and checks it is an image of an accepted type and size
+// that the sender uploaded. Upload URLs are issued under chat/{userId}/, so a blob
+// outside the sender's prefix belongs to someone else.
+func (i *HTTPInspector) Inspect(ctx context.Context, senderID, blobName string) (*models.Attachment, error) {
+	prefix := "chat/" + senderID + "/"
+	if senderID == "" || !strings.HasPrefix(blobName, prefix) || !fileNamePattern.MatchString(strings.TrimPrefix(blobName, prefix)) {
+		return nil, fmt.Errorf("%w: not an upload of the sender", ErrInvalid)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, i.baseURL+"/"+blobName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch attachment: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("%w: not uploaded", ErrInvalid)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("failed to fetch attachment: status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attachment: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: empty", ErrInvalid)
+	}
+	if len(data) > MaxSize {
+		return nil, fmt.Errorf("%w: larger than %d bytes", ErrInvalid, MaxSize)
+	}
+
+	// Trust the bytes, not the extension or the header the uploader set
+	contentType := http.DetectContentType(data)
+	if !allowedTypes[contentType] {
+		return nil, fmt.Errorf("%w: unsupported type %s", ErrInvalid, contentType)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: not a readable image", ErrInvalid)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width > MaxDimension || cfg.Height > MaxDimension {
+		return nil, fmt.Errorf("%w: %dx%d is outside the allowed dimensions", ErrInvalid, cfg.Width, cfg.Height)
+	}
+
+	return &models.Attachment{
+		BlobName:    blobName,
+		URL:         i.publicBaseURL + "/" + blobName,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+	}, nil
+}
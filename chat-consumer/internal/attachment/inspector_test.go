@@ -0,0 +1,116 @@
+package attachment
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func encodeImage(t *testing.T, format string, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "png":
+		err = png.Encode(&buf, img)
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, nil)
+	case "gif":
+		err = gif.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		t.Fatalf("encode %s: %v", format, err)
+	}
+	return buf.Bytes()
+}
+
+func TestHTTPInspectorInspect(t *testing.T) {
+	blobs := map[string][]byte{
+		"chat/u1/photo.png":     encodeImage(t, "png", 40, 30),
+		"chat/u1/photo.jpg":     encodeImage(t, "jpeg", 16, 16),
+		"chat/u1/anim.gif":      encodeImage(t, "gif", 8, 4),
+		"chat/u1/renamed.png":   []byte("%PDF-1.4 not an image"),
+		"chat/u1/broken.png":    append([]byte("\x89PNG\r\n\x1a\n"), []byte("garbage")...),
+		"chat/u1/wide.png":      encodeImage(t, "png", MaxDimension+1, 1),
+		"chat/u1/empty.png":     {},
+		"chat/u1/huge.png":      make([]byte, MaxSize+1),
+		"chat/u2/someone.png":   encodeImage(t, "png", 10, 10),
+		"chat/u1/flaky.png":     nil, // served as a 500
+		"chat/u1/max-side.jpeg": encodeImage(t, "jpeg", MaxDimension, 1),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := blobs[strings.TrimPrefix(r.URL.Path, "/")]
+		switch {
+		case !ok:
+			http.NotFound(w, r)
+		case data == nil:
+			http.Error(w, "unavailable", http.StatusInternalServerError)
+		default:
+			w.Write(data)
+		}
+	}))
+	defer srv.Close()
+
+	inspector := NewHTTPInspector(srv.URL+"/", "https://cdn.example.com/")
+
+	tests := []struct {
+		name        string
+		senderID    string
+		blob        string
+		wantType    string
+		wantW       int
+		wantH       int
+		wantErr     bool
+		wantInvalid bool
+	}{
+		{"png", "u1", "chat/u1/photo.png", "image/png", 40, 30, false, false},
+		{"jpeg", "u1", "chat/u1/photo.jpg", "image/jpeg", 16, 16, false, false},
+		{"gif", "u1", "chat/u1/anim.gif", "image/gif", 8, 4, false, false},
+		{"largest side allowed", "u1", "chat/u1/max-side.jpeg", "image/jpeg", MaxDimension, 1, false, false},
+		{"other sender's prefix", "u1", "chat/u2/someone.png", "", 0, 0, true, true},
+		{"no sender", "", "chat//photo.png", "", 0, 0, true, true},
+		{"path traversal", "u1", "chat/u1/../u2/someone.png", "", 0, 0, true, true},
+		{"nested path", "u1", "chat/u1/a/photo.png", "", 0, 0, true, true},
+		{"outside chat", "u1", "listings/u1/photo.png", "", 0, 0, true, true},
+		{"not uploaded", "u1", "chat/u1/missing.png", "", 0, 0, true, true},
+		{"empty", "u1", "chat/u1/empty.png", "", 0, 0, true, true},
+		{"too large", "u1", "chat/u1/huge.png", "", 0, 0, true, true},
+		{"sniffed type", "u1", "chat/u1/renamed.png", "", 0, 0, true, true},
+		{"unreadable image", "u1", "chat/u1/broken.png", "", 0, 0, true, true},
+		{"too wide", "u1", "chat/u1/wide.png", "", 0, 0, true, true},
+		{"blob store error", "u1", "chat/u1/flaky.png", "", 0, 0, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			att, err := inspector.Inspect(context.Background(), tt.senderID, tt.blob)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Inspect err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if got := errors.Is(err, ErrInvalid); got != tt.wantInvalid {
+					t.Errorf("errors.Is(%v, ErrInvalid) = %v, want %v", err, got, tt.wantInvalid)
+				}
+				return
+			}
+			if att.ContentType != tt.wantType || att.Width != tt.wantW || att.Height != tt.wantH {
+				t.Errorf("got %s %dx%d, want %s %dx%d", att.ContentType, att.Width, att.Height, tt.wantType, tt.wantW, tt.wantH)
+			}
+			if att.URL != "https://cdn.example.com/"+tt.blob || att.BlobName != tt.blob {
+				t.Errorf("URL = %q, BlobName = %q", att.URL, att.BlobName)
+			}
+			if att.Size != int64(len(blobs[tt.blob])) {
+				t.Errorf("Size = %d, want %d", att.Size, len(blobs[tt.blob]))
+			}
+		})
+	}
+}
@@ -9,10 +9,18 @@ import (
 type Config struct {
 	RabbitMQURL       string
 	RabbitMQQueueName string
-	RedisAddr         string
-	RedisPassword     string
-	RedisDB           int
-	MongoURI          string
+	// NotificationsQueueName is optional; when set, messages to offline users also land in
+	// the recipient's notification inbox
+	NotificationsQueueName string
+	RedisAddr              string
+	RedisPassword          string
+	RedisDB                int
+	MongoURI               string
+	// AttachmentBaseURL is where image attachments are fetched for inspection: the Azure
+	// container URL, or listing-service's /blobs in development. Empty rejects images.
+	// AttachmentPublicBaseURL is the base clients get, if it differs.
+	AttachmentBaseURL       string
+	AttachmentPublicBaseURL string
 }
 
 func getenv(key string) string {
@@ -42,11 +50,15 @@ func getenvOptional(key string) string {
 
 func Load() Config {
 	return Config{
-		RabbitMQURL:       getenv("RABBITMQ_URL"),
-		RabbitMQQueueName: getenv("RABBITMQ_QUEUE_NAME"),
-		RedisAddr:         getenv("REDIS_ADDR"),
-		RedisPassword:     getenvOptional("REDIS_PASSWORD"), // Optional: empty password is valid for Redis
-		RedisDB:           getenvInt("REDIS_DB"),
-		MongoURI:          getenv("MONGO_URI"),
+		RabbitMQURL:            getenv("RABBITMQ_URL"),
+		RabbitMQQueueName:      getenv("RABBITMQ_QUEUE_NAME"),
+		NotificationsQueueName: getenvOptional("RABBITMQ_NOTIFICATIONS_QUEUE"),
+		RedisAddr:              getenv("REDIS_ADDR"),
+		RedisPassword:          getenvOptional("REDIS_PASSWORD"), // Optional: empty password is valid for Redis
+		RedisDB:                getenvInt("REDIS_DB"),
+		MongoURI:               getenv("MONGO_URI"),
+
+		AttachmentBaseURL:       getenvOptional("ATTACHMENT_BASE_URL"),
+		AttachmentPublicBaseURL: getenvOptional("ATTACHMENT_PUBLIC_BASE_URL"),
 	}
 }
@@ -3,6 +3,7 @@ package consumer
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
@@ -10,10 +11,12 @@ import (
 
 	amqp "github.com/rabbitmq/amqp091-go"
 
+	"github.com/kunal768/cmpe202/chat-consumer/internal/attachment"
 	"github.com/kunal768/cmpe202/chat-consumer/internal/delivery"
 	"github.com/kunal768/cmpe202/chat-consumer/internal/models"
 	"github.com/kunal768/cmpe202/chat-consumer/internal/presence"
 	"github.com/kunal768/cmpe202/chat-consumer/internal/storage"
+	"github.com/kunal768/cmpe202/http-lib/screening"
 )
 
 // MessageConsumer handles consuming messages from RabbitMQ
@@ -24,6 +27,9 @@ type MessageConsumer struct {
 	messageRepo         storage.MessageRepository
 	presenceChecker     presence.PresenceChecker
 	messagePublisher    delivery.MessagePublisher
+	notifier            delivery.NotificationPublisher // optional; nil disables inbox notifications
+	screener            screening.Pipeline             // screens new message text before it is stored
+	attachments         attachment.Inspector           // optional; nil rejects image messages
 	mu                  sync.RWMutex
 	closed              bool
 	notificationSent    map[string]time.Time // Track when we last sent notification for a user
@@ -36,6 +42,9 @@ func NewMessageConsumer(
 	messageRepo storage.MessageRepository,
 	presenceChecker presence.PresenceChecker,
 	messagePublisher delivery.MessagePublisher,
+	notifier delivery.NotificationPublisher,
+	screener screening.Pipeline,
+	attachments attachment.Inspector,
 ) (*MessageConsumer, error) {
 	conn, err := amqp.Dial(rabbitMQURL)
 	if err != nil {
@@ -78,6 +87,9 @@ func NewMessageConsumer(
 		messageRepo:      messageRepo,
 		presenceChecker:  presenceChecker,
 		messagePublisher: messagePublisher,
+		notifier:         notifier,
+		screener:         screener,
+		attachments:      attachments,
 		notificationSent: make(map[string]time.Time), // Initialize map to prevent nil map panic
 	}, nil
 }
@@ -127,6 +139,15 @@ func (c *MessageConsumer) processMessage(ctx context.Context, delivery amqp.Deli
 
 	log.Printf("Processing message: %s", delivery.MessageId)
 
+	// The queue also carries read events, which have their own type
+	var envelope struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(delivery.Body, &envelope); err == nil && envelope.Type == "read" {
+		c.processReadEvent(msgCtx, delivery)
+		return
+	}
+
 	// Parse the incoming message
 	var incomingMsg struct {
 		MessageID   string    `json:"messageId"`
@@ -135,6 +156,13 @@ func (c *MessageConsumer) processMessage(ctx context.Context, delivery amqp.Deli
 		Content     string    `json:"content"`
 		Timestamp   time.Time `json:"timestamp"`
 		Type        string    `json:"type"`
+		ListingID   *int64    `json:"listingId,omitempty"`
+		// Attachment arrives as a blob name and is delivered with the inspected metadata
+		Attachment *models.Attachment `json:"attachment,omitempty"`
+		// Listing is the preview of a listing card
+		Listing *models.ListingCard `json:"listing,omitempty"`
+		// Warnings tell the recipient which content rules the message matched
+		Warnings []screening.Finding `json:"warnings,omitempty"`
 	}
 
 	if err := json.Unmarshal(delivery.Body, &incomingMsg); err != nil {
@@ -149,6 +177,14 @@ func (c *MessageConsumer) processMessage(ctx context.Context, delivery amqp.Deli
 		log.Printf("Failed to check for existing message %s: %v", incomingMsg.MessageID, err)
 	}
 
+	// A message the recipient has already read (e.g. in the REST history while it was
+	// waiting to be republished) needs no delivery
+	if existingMsg != nil && existingMsg.Status == models.StatusRead {
+		log.Printf("Message %s was already read, skipping delivery", incomingMsg.MessageID)
+		c.ackMessage(delivery)
+		return
+	}
+
 	// Determine initial status: if message exists and is UNDELIVERED, keep it as UNDELIVERED
 	// Otherwise, set to SENT (new message)
 	initialStatus := models.StatusSent
@@ -169,14 +205,71 @@ func (c *MessageConsumer) processMessage(ctx context.Context, delivery amqp.Deli
 		Content:     incomingMsg.Content,
 		Timestamp:   incomingMsg.Timestamp,
 		Type:        incomingMsg.Type,
+		ListingID:   incomingMsg.ListingID,
 		Status:      initialStatus,
 		CreatedAt:   time.Now().UTC(),
 		UpdatedAt:   time.Now().UTC(),
 	}
 
-	// If message exists, preserve its CreatedAt
+	// If message exists, preserve its CreatedAt, screening outcome and attachment
 	if existingMsg != nil {
 		chatMsg.CreatedAt = existingMsg.CreatedAt
+		chatMsg.Screening = existingMsg.Screening
+		chatMsg.Flagged = existingMsg.Flagged
+		chatMsg.Attachment = existingMsg.Attachment
+		incomingMsg.Attachment = existingMsg.Attachment
+		chatMsg.Listing = existingMsg.Listing
+		incomingMsg.Listing = existingMsg.Listing
+	} else {
+		if incomingMsg.Type != models.TypeListingCard {
+			incomingMsg.Listing = nil
+		}
+		chatMsg.Listing = incomingMsg.Listing
+
+		// Attachments are inspected once, like screening; an image that fails is never
+		// stored or delivered
+		if incomingMsg.Type == models.TypeImage {
+			att, err := c.inspectAttachment(msgCtx, incomingMsg.SenderID, incomingMsg.Attachment)
+			if err != nil && !errors.Is(err, attachment.ErrInvalid) {
+				// The blob store couldn't be reached; try the message once more, then
+				// give up so an outage doesn't hold up the queue
+				log.Printf("Failed to inspect attachment of message %s: %v", incomingMsg.MessageID, err)
+				if !delivery.Redelivered {
+					c.nackMessage(delivery)
+					return
+				}
+				c.sendBlockedNotification(msgCtx, incomingMsg.SenderID, incomingMsg.MessageID, incomingMsg.RecipientID, "the image could not be checked; please send it again")
+				c.ackMessage(delivery)
+				return
+			}
+			if err != nil {
+				log.Printf("Image message %s from %s rejected: %v", incomingMsg.MessageID, incomingMsg.SenderID, err)
+				c.sendBlockedNotification(msgCtx, incomingMsg.SenderID, incomingMsg.MessageID, incomingMsg.RecipientID, err.Error())
+				c.ackMessage(delivery)
+				return
+			}
+			chatMsg.Attachment = att
+		}
+		incomingMsg.Attachment = chatMsg.Attachment
+
+		// New messages are screened once; blocked ones are never stored or delivered
+		screened := c.screener.Screen(msgCtx, incomingMsg.Content)
+		if screened.Blocked() {
+			log.Printf("Message %s from %s blocked by content screening: %s", incomingMsg.MessageID, incomingMsg.SenderID, screening.Reasons(screened.Findings))
+			c.sendBlockedNotification(msgCtx, incomingMsg.SenderID, incomingMsg.MessageID, incomingMsg.RecipientID, screening.Reasons(screened.With(screening.ActionBlock)))
+			c.ackMessage(delivery)
+			return
+		}
+		chatMsg.Screening = screened.Findings
+		chatMsg.Flagged = screened.Flagged()
+		if chatMsg.Flagged {
+			log.Printf("Message %s from %s flagged for review: %s", chatMsg.MessageID, chatMsg.SenderID, screening.Reasons(screened.With(screening.ActionFlag)))
+		}
+	}
+	for _, f := range chatMsg.Screening {
+		if f.Action == screening.ActionWarn {
+			incomingMsg.Warnings = append(incomingMsg.Warnings, f)
+		}
 	}
 
 	// Save message to MongoDB with appropriate status
@@ -272,6 +365,9 @@ func (c *MessageConsumer) processMessage(ctx context.Context, delivery amqp.Deli
 				c.nackMessage(delivery) // Don't ack if save fails
 				return
 			}
+			if !wasUndelivered {
+				c.sendNewMessageNotification(msgCtx, chatMsg)
+			}
 		}
 	} else {
 		// User is offline, mark as undelivered
@@ -283,6 +379,9 @@ func (c *MessageConsumer) processMessage(ctx context.Context, delivery amqp.Deli
 			c.nackMessage(delivery) // Don't ack if save fails
 			return
 		}
+		if !wasUndelivered {
+			c.sendNewMessageNotification(msgCtx, chatMsg)
+		}
 		c.ackMessage(delivery)
 		return
 	}
@@ -291,6 +390,52 @@ func (c *MessageConsumer) processMessage(ctx context.Context, delivery amqp.Deli
 	c.ackMessage(delivery)
 }
 
+// processReadEvent marks the messages a user has seen as READ and sends the sender a
+// read_receipt over their Redis channel. An event that names an unknown message can
+// never succeed, so it is dropped; storage errors are retried.
+func (c *MessageConsumer) processReadEvent(ctx context.Context, delivery amqp.Delivery) {
+	var event models.ReadEvent
+	if err := json.Unmarshal(delivery.Body, &event); err != nil || event.ReaderID == "" || event.MessageID == "" {
+		log.Printf("Dropping malformed read event: %s", string(delivery.Body))
+		c.ackMessage(delivery)
+		return
+	}
+
+	readAt := time.Now().UTC()
+	senderID, messageIDs, err := c.messageRepo.MarkReadUpTo(ctx, event.ReaderID, event.MessageID, readAt)
+	if errors.Is(err, storage.ErrMessageNotFound) {
+		log.Printf("Dropping read event from %s for unknown message %s", event.ReaderID, event.MessageID)
+		c.ackMessage(delivery)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to mark messages read for %s: %v", event.ReaderID, err)
+		c.nackMessage(delivery)
+		return
+	}
+	c.ackMessage(delivery)
+	if len(messageIDs) == 0 {
+		return
+	}
+
+	// The receipt is best effort: an offline sender sees READ in the history instead
+	receipt := models.ReadReceipt{
+		Type:        "read_receipt",
+		RecipientID: senderID,
+		ReaderID:    event.ReaderID,
+		MessageIDs:  messageIDs,
+		ReadAt:      readAt,
+	}
+	receiptBytes, err := json.Marshal(receipt)
+	if err != nil {
+		log.Printf("Failed to marshal read receipt: %v", err)
+		return
+	}
+	if _, err := c.messagePublisher.PublishToUser(ctx, senderID, receiptBytes); err != nil {
+		log.Printf("Failed to publish read receipt to user %s: %v", senderID, err)
+	}
+}
+
 // ackMessage acknowledges a message
 func (c *MessageConsumer) ackMessage(delivery amqp.Delivery) {
 	if err := delivery.Ack(false); err != nil {
@@ -376,6 +521,80 @@ func (c *MessageConsumer) sendUndeliveredNotification(ctx context.Context, recip
 	log.Printf("Notification sent to user %s: %d conversations with undelivered messages", recipientID, count)
 }
 
+// inspectAttachment checks the blob an image message references
+func (c *MessageConsumer) inspectAttachment(ctx context.Context, senderID string, ref *models.Attachment) (*models.Attachment, error) {
+	if ref == nil || ref.BlobName == "" {
+		return nil, fmt.Errorf("%w: no blob referenced", attachment.ErrInvalid)
+	}
+	if c.attachments == nil {
+		return nil, fmt.Errorf("%w: image messages are not enabled", attachment.ErrInvalid)
+	}
+	return c.attachments.Inspect(ctx, senderID, ref.BlobName)
+}
+
+// sendBlockedNotification tells the sender over their live connection that a message
+// was rejected (by content screening, or because its attachment failed inspection) and
+// was not delivered. The hub routes notifications by recipientId and forwards only their
+// data, so the details go in data.
+func (c *MessageConsumer) sendBlockedNotification(ctx context.Context, senderID, messageID, recipientID, reason string) {
+	notification := map[string]interface{}{
+		"type":        "notification",
+		"subType":     "message_blocked",
+		"recipientId": senderID,
+		"data": map[string]interface{}{
+			"messageId":   messageID,
+			"otherUserId": recipientID,
+			"reason":      reason,
+		},
+	}
+
+	notificationBytes, err := json.Marshal(notification)
+	if err != nil {
+		log.Printf("Failed to marshal notification: %v", err)
+		return
+	}
+	if _, err := c.messagePublisher.PublishToUser(ctx, senderID, notificationBytes); err != nil {
+		log.Printf("Failed to publish blocked message notification to user %s: %v", senderID, err)
+	}
+}
+
+// sendNewMessageNotification adds a "new message" notification to the inbox of a recipient
+// who could not receive msg live. The dedupe key keeps redeliveries from notifying twice.
+func (c *MessageConsumer) sendNewMessageNotification(ctx context.Context, msg *models.ChatMessage) {
+	if c.notifier == nil {
+		return
+	}
+
+	preview := msg.Content
+	if msg.Type == models.TypeImage && preview == "" {
+		preview = "Sent a photo"
+	}
+	if runes := []rune(preview); len(runes) > 100 {
+		preview = string(runes[:100]) + "…"
+	}
+	data, err := json.Marshal(map[string]string{
+		"messageId": msg.MessageID,
+		"senderId":  msg.SenderID,
+	})
+	if err != nil {
+		log.Printf("Failed to marshal notification data for message %s: %v", msg.MessageID, err)
+		return
+	}
+	dedupeKey := fmt.Sprintf("new_message:%s", msg.MessageID)
+
+	req := delivery.NotificationRequest{
+		UserID:    msg.RecipientID,
+		Type:      "new_message",
+		Title:     "You have a new message",
+		Body:      &preview,
+		Data:      data,
+		DedupeKey: &dedupeKey,
+	}
+	if err := c.notifier.PublishNotification(ctx, req); err != nil {
+		log.Printf("Failed to publish new message notification for message %s: %v", msg.MessageID, err)
+	}
+}
+
 // Close closes the consumer and connections
 func (c *MessageConsumer) Close() error {
 	c.mu.Lock()
@@ -0,0 +1,116 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// NotificationRequest asks the orchestrator to add a notification to a user's inbox.
+// It mirrors notifications.NotificationRequest in the orchestrator.
+type NotificationRequest struct {
+	UserID    string          `json:"userId"`
+	Type      string          `json:"type"`
+	Title     string          `json:"title"`
+	Body      *string         `json:"body,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	DedupeKey *string         `json:"dedupeKey,omitempty"`
+}
+
+// NotificationPublisher defines the interface for publishing inbox notifications
+type NotificationPublisher interface {
+	PublishNotification(ctx context.Context, req NotificationRequest) error
+	Close() error
+}
+
+// RabbitMQNotificationPublisher publishes notification requests to the notifications queue
+type RabbitMQNotificationPublisher struct {
+	conn      *amqp.Connection
+	channel   *amqp.Channel
+	queueName string
+	mu        sync.Mutex
+}
+
+// NewRabbitMQNotificationPublisher creates a new notification publisher
+func NewRabbitMQNotificationPublisher(rabbitMQURL, queueName string) (*RabbitMQNotificationPublisher, error) {
+	conn, err := amqp.Dial(rabbitMQURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open channel: %w", err)
+	}
+
+	// Declare the queue to ensure it exists
+	_, err = channel.QueueDeclare(
+		queueName, // name
+		true,      // durable
+		false,     // delete when unused
+		false,     // exclusive
+		false,     // no-wait
+		nil,       // arguments
+	)
+	if err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("failed to declare queue: %w", err)
+	}
+
+	return &RabbitMQNotificationPublisher{
+		conn:      conn,
+		channel:   channel,
+		queueName: queueName,
+	}, nil
+}
+
+// PublishNotification publishes a notification request to the queue
+func (p *RabbitMQNotificationPublisher) PublishNotification(ctx context.Context, req NotificationRequest) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification request: %w", err)
+	}
+
+	// amqp channels are not safe for concurrent publishing
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	err = p.channel.PublishWithContext(ctx,
+		"",          // exchange
+		p.queueName, // routing key
+		false,       // mandatory
+		false,       // immediate
+		amqp.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			Body:         body,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to publish notification for user %s: %w", req.UserID, err)
+	}
+	return nil
+}
+
+// Close closes the channel and connection
+func (p *RabbitMQNotificationPublisher) Close() error {
+	var err error
+	if p.channel != nil {
+		if closeErr := p.channel.Close(); closeErr != nil {
+			err = closeErr
+		}
+	}
+	if p.conn != nil {
+		if closeErr := p.conn.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	log.Println("Notification publisher closed")
+	return err
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/kunal768/cmpe202/http-lib/screening"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -15,8 +16,15 @@ const (
 	StatusSent        MessageStatus = "SENT"
 	StatusDelivered   MessageStatus = "DELIVERED"
 	StatusUndelivered MessageStatus = "UNDELIVERED"
+	StatusRead        MessageStatus = "READ" // the recipient has seen it
 )
 
+// TypeImage messages carry an Attachment; their content is an optional caption
+const TypeImage = "image"
+
+// TypeListingCard messages open a listing thread and carry its ListingCard preview
+const TypeListingCard = "listing_card"
+
 // ChatMessage represents a chat message with delivery status
 type ChatMessage struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
@@ -29,6 +37,58 @@ type ChatMessage struct {
 	Status      MessageStatus      `bson:"status" json:"status"`           // delivery status
 	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`     // when message was first created
 	UpdatedAt   time.Time          `bson:"updatedAt" json:"updatedAt"`     // when status was last updated
+
+	// ListingID is the listing the conversation is about; nil for the general thread
+	ListingID *int64 `bson:"listingId,omitempty" json:"listingId,omitempty"`
+
+	// Attachment is the inspected upload of an "image" message
+	Attachment *Attachment `bson:"attachment,omitempty" json:"attachment,omitempty"`
+
+	// Listing is the preview a "listing_card" message shows, captured when the thread opened
+	Listing *ListingCard `bson:"listing,omitempty" json:"listing,omitempty"`
+
+	// ReadAt is when the recipient read the message (status READ)
+	ReadAt *time.Time `bson:"readAt,omitempty" json:"readAt,omitempty"`
+
+	// Screening holds the content rules the message matched; Flagged marks it for moderator review
+	Screening []screening.Finding `bson:"screening,omitempty" json:"screening,omitempty"`
+	Flagged   bool                `bson:"flagged,omitempty" json:"flagged,omitempty"`
+}
+
+// Attachment describes an uploaded image as found in the blob store, not as the sender
+// claimed it
+type Attachment struct {
+	BlobName    string `bson:"blobName" json:"blobName"`
+	URL         string `bson:"url" json:"url"`
+	ContentType string `bson:"contentType" json:"contentType"`
+	Size        int64  `bson:"size" json:"size"`
+	Width       int    `bson:"width,omitempty" json:"width,omitempty"`
+	Height      int    `bson:"height,omitempty" json:"height,omitempty"`
+}
+
+// ListingCard is what a listing thread shows of its listing
+type ListingCard struct {
+	Title     string `bson:"title" json:"title"`
+	Thumbnail string `bson:"thumbnail,omitempty" json:"thumbnail,omitempty"`
+}
+
+// ReadEvent is queued by events-server when a user views a conversation: every message
+// up to and including MessageID that the other user sent them has been read
+type ReadEvent struct {
+	Type      string    `json:"type"` // "read"
+	ReaderID  string    `json:"readerId"`
+	MessageID string    `json:"messageId"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ReadReceipt tells a sender which of their messages were read. It is published to the
+// sender's channel, so RecipientID is the sender.
+type ReadReceipt struct {
+	Type        string    `json:"type"` // "read_receipt"
+	RecipientID string    `json:"recipientId"`
+	ReaderID    string    `json:"readerId"`
+	MessageIDs  []string  `json:"messageIds"`
+	ReadAt      time.Time `json:"readAt"`
 }
 
 // NewChatMessage creates a new ChatMessage with server-generated fields
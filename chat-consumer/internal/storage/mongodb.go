@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"time"
@@ -13,6 +14,9 @@ import (
 	"github.com/kunal768/cmpe202/chat-consumer/internal/models"
 )
 
+// ErrMessageNotFound is returned when a message doesn't exist or isn't addressed to the user
+var ErrMessageNotFound = errors.New("message not found")
+
 // MessageRepository defines the interface for message persistence
 type MessageRepository interface {
 	SaveMessage(ctx context.Context, msg *models.ChatMessage) error
@@ -20,6 +24,7 @@ type MessageRepository interface {
 	GetMessageByID(ctx context.Context, messageID string) (*models.ChatMessage, error)
 	GetUndeliveredCount(ctx context.Context, recipientID string) (int, error)
 	GetConversationsWithUndeliveredCount(ctx context.Context, recipientID string) (int, error)
+	MarkReadUpTo(ctx context.Context, readerID, messageID string, readAt time.Time) (string, []string, error)
 	Close() error
 }
 
@@ -63,6 +68,10 @@ func NewMongoMessageRepository(mongoURI string) (*MongoMessageRepository, error)
 		{
 			Keys: bson.D{{Key: "timestamp", Value: -1}},
 		},
+		{
+			// Conversation threads: one per pair of users and listing
+			Keys: bson.D{{Key: "senderId", Value: 1}, {Key: "recipientId", Value: 1}, {Key: "listingId", Value: 1}},
+		},
 	}
 
 	_, err = collection.Indexes().CreateMany(ctx, indexes)
@@ -82,6 +91,21 @@ func NewMongoMessageRepository(mongoURI string) (*MongoMessageRepository, error)
 func (r *MongoMessageRepository) SaveMessage(ctx context.Context, msg *models.ChatMessage) error {
 	// Use upsert to handle both insert and update cases
 	filter := bson.M{"messageId": msg.MessageID}
+	onInsert := bson.M{
+		"createdAt": msg.CreatedAt,
+		"screening": msg.Screening,
+		"flagged":   msg.Flagged,
+	}
+	// A message never moves to another thread
+	if msg.ListingID != nil {
+		onInsert["listingId"] = *msg.ListingID
+	}
+	if msg.Attachment != nil {
+		onInsert["attachment"] = msg.Attachment
+	}
+	if msg.Listing != nil {
+		onInsert["listing"] = msg.Listing
+	}
 	update := bson.M{
 		"$set": bson.M{
 			"messageId":   msg.MessageID,
@@ -93,9 +117,8 @@ func (r *MongoMessageRepository) SaveMessage(ctx context.Context, msg *models.Ch
 			"status":      msg.Status,
 			"updatedAt":   msg.UpdatedAt,
 		},
-		"$setOnInsert": bson.M{
-			"createdAt": msg.CreatedAt,
-		},
+		// The screening outcome is decided once, when the message is first stored
+		"$setOnInsert": onInsert,
 	}
 
 	opts := options.Update().SetUpsert(true)
@@ -179,6 +202,53 @@ func (r *MongoMessageRepository) GetConversationsWithUndeliveredCount(ctx contex
 	return len(senderIDs), nil
 }
 
+// MarkReadUpTo marks messageID and every earlier message from the same sender to readerID
+// in the same thread (the listing's, or the direct one) as READ. It returns the sender and the IDs of the messages it changed; messages that
+// were already read are left alone.
+func (r *MongoMessageRepository) MarkReadUpTo(ctx context.Context, readerID, messageID string, readAt time.Time) (string, []string, error) {
+	upTo, err := r.GetMessageByID(ctx, messageID)
+	if err != nil {
+		return "", nil, err
+	}
+	if upTo == nil || upTo.RecipientID != readerID {
+		return "", nil, ErrMessageNotFound
+	}
+
+	filter := bson.M{
+		"senderId":    upTo.SenderID,
+		"recipientId": readerID,
+		"timestamp":   bson.M{"$lte": upTo.Timestamp},
+		"status":      bson.M{"$ne": models.StatusRead},
+		"listingId":   bson.M{"$exists": false},
+	}
+	if upTo.ListingID != nil {
+		filter["listingId"] = *upTo.ListingID
+	}
+	ids, err := r.collection.Distinct(ctx, "messageId", filter)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to find unread messages: %w", err)
+	}
+	if len(ids) == 0 {
+		return upTo.SenderID, nil, nil
+	}
+
+	messageIDs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if s, ok := id.(string); ok {
+			messageIDs = append(messageIDs, s)
+		}
+	}
+	_, err = r.collection.UpdateMany(ctx,
+		bson.M{"messageId": bson.M{"$in": messageIDs}, "status": bson.M{"$ne": models.StatusRead}},
+		bson.M{"$set": bson.M{"status": models.StatusRead, "readAt": readAt, "updatedAt": readAt}},
+	)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to mark messages read: %w", err)
+	}
+	log.Printf("Marked %d messages from %s to %s as READ", len(messageIDs), upTo.SenderID, readerID)
+	return upTo.SenderID, messageIDs, nil
+}
+
 // Close closes the MongoDB connection
 func (r *MongoMessageRepository) Close() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
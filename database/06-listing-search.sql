@@ -0,0 +1,12 @@
+-- 1) Full-text search vector for listings.
+-- Title terms weigh more than description terms ('A' > 'B'). The column is generated,
+-- so Postgres keeps it in sync on every INSERT/UPDATE of title or description.
+ALTER TABLE listings
+  ADD COLUMN IF NOT EXISTS search_vector tsvector
+  GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'B')
+  ) STORED;
+
+-- 2) GIN index so @@ matches don't scan the whole table
+CREATE INDEX IF NOT EXISTS idx_listings_search_vector ON listings USING GIN (search_vector);
@@ -0,0 +1,50 @@
+-- 1) Enum for offer lifecycle
+-- PENDING   -> waiting on the other party
+-- ACCEPTED  -> listing moved to PENDING
+-- REJECTED  -> turned down by the other party
+-- COUNTERED -> replaced by a counter offer (see parent_offer_id)
+-- DECLINED  -> closed automatically because a competing offer was accepted, or the sale
+--              that followed an ACCEPTED offer was cancelled
+-- EXPIRED   -> expires_at passed while still PENDING
+DO $$ BEGIN
+  CREATE TYPE OFFER_STATUS AS ENUM ('PENDING','ACCEPTED','REJECTED','COUNTERED','DECLINED','EXPIRED');
+EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+
+-- 2) Offers made on listings. A negotiation is a chain of offers linked by parent_offer_id;
+-- created_by_user_id tells whose turn it is (the other party responds).
+CREATE TABLE IF NOT EXISTS offers (
+  id BIGSERIAL PRIMARY KEY,
+  listing_id INTEGER NOT NULL,
+  buyer_user_id UUID NOT NULL,
+  seller_user_id UUID NOT NULL,
+  created_by_user_id UUID NOT NULL,
+  parent_offer_id BIGINT,
+  amount INTEGER NOT NULL CHECK (amount > 0), -- smallest unit (e.g., cents)
+  message TEXT,
+  status OFFER_STATUS NOT NULL DEFAULT 'PENDING',
+  expires_at TIMESTAMPTZ NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  responded_at TIMESTAMPTZ,
+
+  CONSTRAINT fk_offer_listing FOREIGN KEY (listing_id)
+    REFERENCES listings(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_offer_buyer FOREIGN KEY (buyer_user_id)
+    REFERENCES users(user_id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_offer_seller FOREIGN KEY (seller_user_id)
+    REFERENCES users(user_id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_offer_parent FOREIGN KEY (parent_offer_id)
+    REFERENCES offers(id)
+    ON DELETE SET NULL
+);
+
+-- 3) Helpful indexes
+CREATE INDEX IF NOT EXISTS idx_offers_listing_status ON offers(listing_id, status);
+CREATE INDEX IF NOT EXISTS idx_offers_buyer ON offers(buyer_user_id);
+CREATE INDEX IF NOT EXISTS idx_offers_seller ON offers(seller_user_id);
+CREATE INDEX IF NOT EXISTS idx_offers_pending_expiry ON offers(expires_at) WHERE status = 'PENDING';
+-- A buyer keeps at most one open negotiation per listing (a counter replaces its parent)
+CREATE UNIQUE INDEX IF NOT EXISTS uq_offers_pending_buyer ON offers(listing_id, buyer_user_id) WHERE status = 'PENDING';
@@ -0,0 +1,45 @@
+-- 1) Enum for the two-sided sale flow
+-- PENDING_CONFIRMATION -> seller marked the listing sold to a buyer, waiting on the buyer
+-- COMPLETED            -> buyer confirmed; listing is SOLD
+-- CANCELLED            -> buyer declined or seller withdrew before confirmation
+DO $$ BEGIN
+  CREATE TYPE TRANSACTION_STATUS AS ENUM ('PENDING_CONFIRMATION','COMPLETED','CANCELLED');
+EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+
+-- 2) Completed (and in-flight) sales of listings
+CREATE TABLE IF NOT EXISTS transactions (
+  id BIGSERIAL PRIMARY KEY,
+  listing_id INTEGER NOT NULL,
+  seller_user_id UUID NOT NULL,
+  buyer_user_id UUID NOT NULL,
+  offer_id BIGINT,                     -- accepted offer the price came from, if any
+  final_price INTEGER NOT NULL CHECK (final_price >= 0), -- smallest unit (e.g., cents)
+  status TRANSACTION_STATUS NOT NULL DEFAULT 'PENDING_CONFIRMATION',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  completed_at TIMESTAMPTZ,
+  cancelled_at TIMESTAMPTZ,
+
+  CONSTRAINT fk_transaction_listing FOREIGN KEY (listing_id)
+    REFERENCES listings(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_transaction_seller FOREIGN KEY (seller_user_id)
+    REFERENCES users(user_id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_transaction_buyer FOREIGN KEY (buyer_user_id)
+    REFERENCES users(user_id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_transaction_offer FOREIGN KEY (offer_id)
+    REFERENCES offers(id)
+    ON DELETE SET NULL,
+  CONSTRAINT chk_transaction_parties CHECK (seller_user_id <> buyer_user_id)
+);
+
+-- 3) A listing can only be sold once: at most one live (non-cancelled) transaction per listing
+CREATE UNIQUE INDEX IF NOT EXISTS uq_transactions_live_listing
+  ON transactions(listing_id) WHERE status <> 'CANCELLED';
+
+-- 4) Helpful indexes
+CREATE INDEX IF NOT EXISTS idx_transactions_seller ON transactions(seller_user_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_buyer ON transactions(buyer_user_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_status ON transactions(status);
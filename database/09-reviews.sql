@@ -0,0 +1,36 @@
+-- 1) Enum for which side of the sale left the review
+DO $$ BEGIN
+  CREATE TYPE REVIEWER_ROLE AS ENUM ('BUYER','SELLER');
+EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+
+-- 2) Ratings left by each party of a completed sale about the other party
+CREATE TABLE IF NOT EXISTS reviews (
+  id BIGSERIAL PRIMARY KEY,
+  transaction_id BIGINT NOT NULL,
+  listing_id INTEGER NOT NULL,
+  reviewer_user_id UUID NOT NULL,
+  reviewee_user_id UUID NOT NULL,
+  reviewer_role REVIEWER_ROLE NOT NULL,
+  rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+  comment TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT fk_review_transaction FOREIGN KEY (transaction_id)
+    REFERENCES transactions(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_review_listing FOREIGN KEY (listing_id)
+    REFERENCES listings(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_review_reviewer FOREIGN KEY (reviewer_user_id)
+    REFERENCES users(user_id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_review_reviewee FOREIGN KEY (reviewee_user_id)
+    REFERENCES users(user_id)
+    ON DELETE CASCADE,
+  -- each side reviews a sale at most once
+  CONSTRAINT uq_review_transaction_reviewer UNIQUE (transaction_id, reviewer_user_id)
+);
+
+-- 3) Helpful indexes
+CREATE INDEX IF NOT EXISTS idx_reviews_reviewee ON reviews(reviewee_user_id);
+CREATE INDEX IF NOT EXISTS idx_reviews_listing ON reviews(listing_id);
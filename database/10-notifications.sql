@@ -0,0 +1,23 @@
+-- 1) Notifications persisted per user so offline users still see them.
+-- type is the notification subType pushed over WebSocket (e.g. 'saved_search');
+-- dedupe_key keeps redelivered events from notifying twice.
+CREATE TABLE IF NOT EXISTS notifications (
+  id BIGSERIAL PRIMARY KEY,
+  user_id UUID NOT NULL,
+  type VARCHAR(50) NOT NULL,
+  title VARCHAR(255) NOT NULL,
+  body TEXT,
+  data JSONB NOT NULL DEFAULT '{}'::jsonb,
+  dedupe_key VARCHAR(255),
+  read_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT fk_notification_user FOREIGN KEY (user_id)
+    REFERENCES users(user_id)
+    ON DELETE CASCADE,
+  CONSTRAINT uq_notification_dedupe UNIQUE (user_id, dedupe_key)
+);
+
+-- 2) Helpful indexes
+CREATE INDEX IF NOT EXISTS idx_notifications_user_created ON notifications(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_user_unread ON notifications(user_id) WHERE read_at IS NULL;
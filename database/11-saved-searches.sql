@@ -0,0 +1,24 @@
+-- 1) Named searches users want to be alerted about. Columns mirror the listing
+-- feed filters; NULL / empty means "any".
+CREATE TABLE IF NOT EXISTS saved_searches (
+  id BIGSERIAL PRIMARY KEY,
+  user_id UUID NOT NULL,
+  name VARCHAR(100) NOT NULL,
+  query TEXT,                                  -- full-text query (websearch syntax)
+  keywords TEXT[] NOT NULL DEFAULT '{}',       -- matched with ILIKE, any keyword
+  category LISTING_CATEGORY,
+  min_price INTEGER CHECK (min_price >= 0),    -- smallest unit (e.g., cents)
+  max_price INTEGER CHECK (max_price >= 0),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT fk_saved_search_user FOREIGN KEY (user_id)
+    REFERENCES users(user_id)
+    ON DELETE CASCADE,
+  CONSTRAINT uq_saved_search_name UNIQUE (user_id, name),
+  CONSTRAINT chk_saved_search_price CHECK (min_price IS NULL OR max_price IS NULL OR min_price <= max_price)
+);
+
+-- 2) Helpful indexes
+CREATE INDEX IF NOT EXISTS idx_saved_searches_user ON saved_searches(user_id);
+CREATE INDEX IF NOT EXISTS idx_saved_searches_category ON saved_searches(category);
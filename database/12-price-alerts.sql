@@ -0,0 +1,7 @@
+-- 1) Per-listing opt-out for price-drop notifications on saved listings
+ALTER TABLE saved_listings
+  ADD COLUMN IF NOT EXISTS price_alerts BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- 2) Price-drop fan-out looks up the savers of one listing who still want alerts
+CREATE INDEX IF NOT EXISTS idx_saved_listings_price_alerts
+  ON saved_listings(listing_id) WHERE price_alerts;
@@ -0,0 +1,11 @@
+-- 1) Listing expiry. The listing-service expiry worker assigns expires_at to AVAILABLE
+-- listings, warns the seller ahead of time (expiry_warned_at) and archives them once
+-- expired (expired_at). Sellers renew a listing to push expires_at out again.
+ALTER TABLE listings
+  ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS expiry_warned_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS expired_at TIMESTAMPTZ; -- set only when archived by the worker
+
+-- 2) The worker only scans live listings
+CREATE INDEX IF NOT EXISTS idx_listings_available_expiry
+  ON listings(expires_at) WHERE status = 'AVAILABLE';
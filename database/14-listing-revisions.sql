@@ -0,0 +1,113 @@
+-- 1) Immutable edit history for listings. Every INSERT/UPDATE/DELETE on listings that
+-- touches a tracked field (title, description, price, category, status) appends a
+-- revision with a field-level diff and a snapshot of the listing after the change.
+-- listing_id has no FK so history outlives a deleted listing.
+CREATE TABLE IF NOT EXISTS listing_revisions (
+  id BIGSERIAL PRIMARY KEY,
+  listing_id INTEGER NOT NULL,
+  revision INTEGER NOT NULL,              -- 1, 2, 3... per listing
+  action VARCHAR(20) NOT NULL,            -- BASELINE, CREATED, UPDATED, DELETED
+  changed_by_user_id UUID,                -- NULL for system changes (e.g. the expiry worker)
+  changes JSONB NOT NULL DEFAULT '{}'::jsonb,   -- {"price": {"old": 100, "new": 80}, ...}
+  snapshot JSONB NOT NULL,                -- tracked fields after the change (before, for DELETED)
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT uq_listing_revision UNIQUE (listing_id, revision)
+);
+
+-- 2) Revisions are append-only
+CREATE OR REPLACE FUNCTION forbid_listing_revision_changes() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'listing revisions are immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_listing_revisions_immutable ON listing_revisions;
+CREATE TRIGGER trg_listing_revisions_immutable
+  BEFORE UPDATE OR DELETE ON listing_revisions
+  FOR EACH ROW EXECUTE FUNCTION forbid_listing_revision_changes();
+
+-- 3) Record a revision for every listing mutation. The application attributes a change by
+-- running it in a transaction after SELECT set_config('app.actor_user_id', <user id>, true).
+CREATE OR REPLACE FUNCTION record_listing_revision() RETURNS trigger AS $$
+DECLARE
+  old_fields JSONB := '{}'::jsonb;
+  new_fields JSONB := '{}'::jsonb;
+  diff JSONB;
+  target_id INTEGER;
+  next_revision INTEGER;
+BEGIN
+  IF TG_OP <> 'INSERT' THEN
+    old_fields := jsonb_build_object('title', OLD.title, 'description', OLD.description,
+      'price', OLD.price, 'category', OLD.category, 'status', OLD.status);
+    target_id := OLD.id;
+  END IF;
+  IF TG_OP <> 'DELETE' THEN
+    new_fields := jsonb_build_object('title', NEW.title, 'description', NEW.description,
+      'price', NEW.price, 'category', NEW.category, 'status', NEW.status);
+    target_id := NEW.id;
+  END IF;
+
+  SELECT COALESCE(jsonb_object_agg(k, jsonb_build_object('old', old_fields -> k, 'new', new_fields -> k)), '{}'::jsonb)
+  INTO diff
+  FROM unnest(ARRAY['title', 'description', 'price', 'category', 'status']) AS k
+  WHERE old_fields -> k IS DISTINCT FROM new_fields -> k;
+
+  -- Bookkeeping-only updates (expiry timestamps, updated_at...) are not revisions
+  IF TG_OP = 'UPDATE' AND diff = '{}'::jsonb THEN
+    RETURN NULL;
+  END IF;
+
+  SELECT COALESCE(MAX(revision), 0) + 1 INTO next_revision
+  FROM listing_revisions WHERE listing_id = target_id;
+
+  INSERT INTO listing_revisions (listing_id, revision, action, changed_by_user_id, changes, snapshot)
+  VALUES (
+    target_id,
+    next_revision,
+    CASE TG_OP WHEN 'INSERT' THEN 'CREATED' WHEN 'UPDATE' THEN 'UPDATED' ELSE 'DELETED' END,
+    NULLIF(current_setting('app.actor_user_id', true), '')::uuid,
+    diff,
+    CASE WHEN TG_OP = 'DELETE' THEN old_fields ELSE new_fields END
+  );
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_listings_revision ON listings;
+CREATE TRIGGER trg_listings_revision
+  AFTER INSERT OR UPDATE OR DELETE ON listings
+  FOR EACH ROW EXECUTE FUNCTION record_listing_revision();
+
+-- 4) Listings that predate the history get a baseline revision
+INSERT INTO listing_revisions (listing_id, revision, action, snapshot)
+SELECT l.id, 1, 'BASELINE', jsonb_build_object('title', l.title, 'description', l.description,
+  'price', l.price, 'category', l.category, 'status', l.status)
+FROM listings l
+WHERE NOT EXISTS (SELECT 1 FROM listing_revisions r WHERE r.listing_id = l.id);
+
+-- 5) Flags remember the revision that was current when they were filed, so moderators
+-- see what the listing said at the time
+ALTER TABLE flagged_listings
+  ADD COLUMN IF NOT EXISTS listing_revision_id BIGINT REFERENCES listing_revisions(id) ON DELETE SET NULL;
+
+CREATE OR REPLACE FUNCTION set_flag_listing_revision() RETURNS trigger AS $$
+BEGIN
+  IF NEW.listing_revision_id IS NULL THEN
+    SELECT id INTO NEW.listing_revision_id
+    FROM listing_revisions
+    WHERE listing_id = NEW.listing_id
+    ORDER BY revision DESC
+    LIMIT 1;
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_flagged_listings_revision ON flagged_listings;
+CREATE TRIGGER trg_flagged_listings_revision
+  BEFORE INSERT ON flagged_listings
+  FOR EACH ROW EXECUTE FUNCTION set_flag_listing_revision();
+
+-- 6) Helpful indexes
+CREATE INDEX IF NOT EXISTS idx_listing_revisions_actor ON listing_revisions(changed_by_user_id);
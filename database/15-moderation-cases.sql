@@ -0,0 +1,69 @@
+-- 1) Moderation cases group the open flags on one listing so a single admin works them.
+-- OPEN          -> in the queue, unassigned
+-- UNDER_REVIEW  -> claimed by assigned_admin_id until claim_expires_at
+-- RESOLVED / DISMISSED -> closed; every flag in the case was closed with it
+CREATE TABLE IF NOT EXISTS moderation_cases (
+  id BIGSERIAL PRIMARY KEY,
+  listing_id INTEGER NOT NULL,
+  status FLAG_STATUS NOT NULL DEFAULT 'OPEN',
+  assigned_admin_id UUID,
+  claimed_at TIMESTAMPTZ,
+  claim_expires_at TIMESTAMPTZ,
+  resolution_notes TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  resolved_at TIMESTAMPTZ,
+
+  CONSTRAINT fk_case_listing FOREIGN KEY (listing_id)
+    REFERENCES listings(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_case_admin FOREIGN KEY (assigned_admin_id)
+    REFERENCES users(user_id)
+    ON DELETE SET NULL
+);
+
+-- 2) At most one live case per listing; new flags join it
+CREATE UNIQUE INDEX IF NOT EXISTS uq_moderation_cases_live_listing
+  ON moderation_cases(listing_id) WHERE status IN ('OPEN', 'UNDER_REVIEW');
+
+ALTER TABLE flagged_listings
+  ADD COLUMN IF NOT EXISTS case_id BIGINT REFERENCES moderation_cases(id) ON DELETE SET NULL;
+
+-- 3) Open flags are attached to their listing's live case (opening one if needed)
+CREATE OR REPLACE FUNCTION set_flag_case() RETURNS trigger AS $$
+BEGIN
+  IF NEW.case_id IS NULL AND NEW.status IN ('OPEN', 'UNDER_REVIEW') THEN
+    INSERT INTO moderation_cases (listing_id)
+    VALUES (NEW.listing_id)
+    ON CONFLICT (listing_id) WHERE status IN ('OPEN', 'UNDER_REVIEW')
+    DO UPDATE SET updated_at = NOW()
+    RETURNING id INTO NEW.case_id;
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_flagged_listings_case ON flagged_listings;
+CREATE TRIGGER trg_flagged_listings_case
+  BEFORE INSERT ON flagged_listings
+  FOR EACH ROW EXECUTE FUNCTION set_flag_case();
+
+-- 4) Existing open flags get cases
+INSERT INTO moderation_cases (listing_id)
+SELECT DISTINCT fl.listing_id
+FROM flagged_listings fl
+WHERE fl.status IN ('OPEN', 'UNDER_REVIEW') AND fl.case_id IS NULL
+ON CONFLICT (listing_id) WHERE status IN ('OPEN', 'UNDER_REVIEW') DO NOTHING;
+
+UPDATE flagged_listings fl
+SET case_id = c.id
+FROM moderation_cases c
+WHERE c.listing_id = fl.listing_id
+  AND c.status IN ('OPEN', 'UNDER_REVIEW')
+  AND fl.status IN ('OPEN', 'UNDER_REVIEW')
+  AND fl.case_id IS NULL;
+
+-- 5) Helpful indexes
+CREATE INDEX IF NOT EXISTS idx_flagged_listings_case ON flagged_listings(case_id);
+CREATE INDEX IF NOT EXISTS idx_moderation_cases_status ON moderation_cases(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_moderation_cases_admin ON moderation_cases(assigned_admin_id);
@@ -0,0 +1,38 @@
+-- 1) Account states
+-- ACTIVE    -> normal use
+-- SUSPENDED -> locked out until suspended_until (NULL = until an admin lifts it)
+-- BANNED    -> locked out permanently
+DO $$ BEGIN
+  CREATE TYPE ACCOUNT_STATUS AS ENUM ('ACTIVE','SUSPENDED','BANNED');
+EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+
+ALTER TABLE users
+  ADD COLUMN IF NOT EXISTS account_status ACCOUNT_STATUS NOT NULL DEFAULT 'ACTIVE',
+  ADD COLUMN IF NOT EXISTS suspended_until TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS status_reason TEXT,
+  ADD COLUMN IF NOT EXISTS status_changed_by UUID,
+  ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMPTZ;
+
+-- 2) A user is restricted while banned, or suspended with no end or an end in the future.
+-- Suspensions lapse on their own; nothing needs to flip them back to ACTIVE.
+CREATE OR REPLACE FUNCTION account_is_restricted(status ACCOUNT_STATUS, until TIMESTAMPTZ) RETURNS BOOLEAN AS $$
+  SELECT status = 'BANNED' OR (status = 'SUSPENDED' AND (until IS NULL OR until > now()));
+$$ LANGUAGE sql STABLE;
+
+CREATE INDEX IF NOT EXISTS idx_users_restricted ON users(user_id) WHERE account_status <> 'ACTIVE';
+
+-- 3) Audit trail of every admin action on an account. user_id has no FK so the
+-- trail outlives a deleted user.
+CREATE TABLE IF NOT EXISTS account_status_events (
+  id BIGSERIAL PRIMARY KEY,
+  user_id UUID NOT NULL,
+  action VARCHAR(20) NOT NULL,             -- SUSPEND, UNSUSPEND, BAN
+  previous_status ACCOUNT_STATUS NOT NULL,
+  new_status ACCOUNT_STATUS NOT NULL,
+  suspended_until TIMESTAMPTZ,
+  reason TEXT,
+  admin_user_id UUID,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_status_events_user ON account_status_events(user_id, created_at DESC);
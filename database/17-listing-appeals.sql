@@ -0,0 +1,44 @@
+-- 1) Appeal lifecycle
+-- PENDING    -> waiting in the appeals queue
+-- UPHELD     -> the original decision stands
+-- OVERTURNED -> the decision was reversed and the listing reinstated
+DO $$ BEGIN
+  CREATE TYPE APPEAL_STATUS AS ENUM ('PENDING','UPHELD','OVERTURNED');
+EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+
+-- 2) A seller's appeal against a RESOLVED flag on their listing. One appeal per decision.
+CREATE TABLE IF NOT EXISTS listing_appeals (
+  id BIGSERIAL PRIMARY KEY,
+  flag_id BIGINT NOT NULL,
+  listing_id INTEGER NOT NULL,
+  seller_user_id UUID NOT NULL,
+  justification TEXT NOT NULL,
+  status APPEAL_STATUS NOT NULL DEFAULT 'PENDING',
+  reviewer_user_id UUID,                   -- admin who decided the appeal
+  decision_notes TEXT,                     -- shown to the seller
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  decided_at TIMESTAMPTZ,
+
+  CONSTRAINT uq_listing_appeal_flag UNIQUE (flag_id),
+  CONSTRAINT fk_appeal_flag FOREIGN KEY (flag_id)
+    REFERENCES flagged_listings(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_appeal_listing FOREIGN KEY (listing_id)
+    REFERENCES listings(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_appeal_seller FOREIGN KEY (seller_user_id)
+    REFERENCES users(user_id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_appeal_reviewer FOREIGN KEY (reviewer_user_id)
+    REFERENCES users(user_id)
+    ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_listing_appeals_status ON listing_appeals(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_listing_appeals_seller ON listing_appeals(seller_user_id);
+
+-- 3) The final decision is kept on the flag it overrides as well
+ALTER TABLE flagged_listings
+  ADD COLUMN IF NOT EXISTS appeal_status APPEAL_STATUS,
+  ADD COLUMN IF NOT EXISTS appeal_decided_at TIMESTAMPTZ;
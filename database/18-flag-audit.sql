@@ -0,0 +1,19 @@
+-- 1) Audit trail of admin decisions made through bulk moderation. One row per flag
+-- changed; rows from the same request share bulk_action_id. flag_id and listing_id have
+-- no FK so the trail outlives deleted flags and listings.
+CREATE TABLE IF NOT EXISTS flag_audit_events (
+  id BIGSERIAL PRIMARY KEY,
+  bulk_action_id UUID NOT NULL,
+  flag_id BIGINT NOT NULL,
+  listing_id INTEGER NOT NULL,
+  action VARCHAR(30) NOT NULL,             -- DISMISS, RESOLVE, RESOLVE_AND_ARCHIVE
+  previous_status FLAG_STATUS NOT NULL,
+  new_status FLAG_STATUS NOT NULL,
+  listing_archived BOOLEAN NOT NULL DEFAULT FALSE,
+  notes TEXT,
+  admin_user_id UUID,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_flag_audit_events_flag ON flag_audit_events(flag_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_flag_audit_events_bulk ON flag_audit_events(bulk_action_id);
@@ -0,0 +1,7 @@
+-- 1) Campus email verification. A user is verified once email_verified_at is set;
+-- unverified users can browse but not list items or chat.
+-- Accounts that existed before verification was introduced are treated as verified.
+DO $$ BEGIN
+  ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMPTZ;
+  UPDATE users SET email_verified_at = created_at;
+EXCEPTION WHEN duplicate_column THEN NULL; END $$;
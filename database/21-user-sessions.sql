@@ -0,0 +1,21 @@
+-- 1) Login sessions, one per device. Replaces user_login_auth, which kept a single
+-- refresh token per user so every login signed out the user's other devices.
+-- refresh_token_id is the jti of the only refresh token the session currently accepts;
+-- presenting an older one means it was stolen and replayed, and revokes the session.
+CREATE TABLE IF NOT EXISTS user_sessions (
+  session_id UUID PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+  refresh_token_id UUID NOT NULL,
+  user_agent TEXT,
+  ip_address TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  last_used_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  expires_at TIMESTAMPTZ NOT NULL,
+  revoked_at TIMESTAMPTZ,
+  revoked_reason VARCHAR(40)               -- LOGOUT, USER_REVOKED, REFRESH_TOKEN_REUSE, PASSWORD_CHANGED, ACCOUNT_RESTRICTED
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user_active ON user_sessions(user_id, last_used_at DESC) WHERE revoked_at IS NULL;
+
+-- 2) Existing refresh tokens stop working; users sign in again once
+DROP TABLE IF EXISTS user_login_auth;
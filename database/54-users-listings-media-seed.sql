@@ -15,12 +15,13 @@ new_users AS (
     CASE WHEN gs = 1 THEN '0' ELSE '1' END AS role,
     jsonb_build_object(
       'Email', 'user' || gs || '@sjsu.edu'
-    ) AS contact
+    ) AS contact,
+    NOW() AS email_verified_at
   FROM generate_series(1, 50) AS gs
 ),
 
 added_users_id AS (
-  INSERT INTO users (user_name, email, role, contact)
+  INSERT INTO users (user_name, email, role, contact, email_verified_at)
   SELECT * FROM new_users
   ON CONFLICT (email) DO NOTHING
   RETURNING user_id
@@ -27,7 +27,7 @@ import (
 // readConnection starts an HTTP listener that upgrades requests to gobwas/ws
 // websockets. Each upgraded connection is handled in its own goroutine.
 // onConnection and onClose are hooks you can customize.
-func readConnection(hub *wsx.Hub, pres presence.PresenceStore, authc auth.AuthClient, msgService *message.MessageService, cfg config.Config) {
+func readConnection(hub *wsx.Hub, pres presence.PresenceStore, authc auth.AuthClient, msgService *message.MessageService, events delivery.EventPublisher, cfg config.Config) {
 	addr := cfg.Port
 	if addr == "" {
 		log.Fatal("PORT is not set")
@@ -55,7 +55,7 @@ func readConnection(hub *wsx.Hub, pres presence.PresenceStore, authc auth.AuthCl
 		}
 		log.Printf("WebSocket upgrade successful from %s", r.RemoteAddr)
 		// handle each websocket connection concurrently
-		go handleConn(conn, hub, pres, authc, msgService, cfg)
+		go handleConn(conn, hub, pres, authc, msgService, events, cfg)
 	})
 
 	// HTTP API endpoint for sending messages to WebSocket clients
@@ -122,14 +122,14 @@ func readConnection(hub *wsx.Hub, pres presence.PresenceStore, authc auth.AuthCl
 
 // handleConn provides a minimal read loop using wsutil. Replace the loop body
 // with your application logic. onConnection and onClose are invoked for lifecycle.
-func handleConn(conn net.Conn, hub *wsx.Hub, pres presence.PresenceStore, authc auth.AuthClient, msgService *message.MessageService, cfg config.Config) {
+func handleConn(conn net.Conn, hub *wsx.Hub, pres presence.PresenceStore, authc auth.AuthClient, msgService *message.MessageService, events delivery.EventPublisher, cfg config.Config) {
 	onConnection(conn)
 	defer func() {
 		onClose(conn)
 		conn.Close()
 	}()
 
-	client := wsx.NewClient(conn, hub, pres, authc, msgService)
+	client := wsx.NewClient(conn, hub, pres, authc, msgService, events)
 	client.Serve(context.Background())
 }
 
@@ -159,12 +159,22 @@ func main() {
 
 	// Wire dependencies
 	pres := presence.NewRedisPresenceStore(cfg.RedisAddr, cfg.RedisPassword, cfg.RedisDB, cfg.PresenceTTLSeconds)
-	authc := auth.OrchestratorClient{BaseURL: cfg.OrchestratorBaseURL, HTTPTimeout: 5 * time.Second}
+
+	// Tokens are verified locally against the orchestrator's signing keys unless
+	// AUTH_VERIFY_MODE=remote asks for a round trip per connection. Tokens signed with
+	// the orchestrator's shared secret (no kid) are always checked remotely.
+	var authc auth.AuthClient = auth.OrchestratorClient{BaseURL: cfg.OrchestratorBaseURL, HTTPTimeout: 5 * time.Second}
+	if cfg.AuthVerifyMode != "remote" {
+		authc = auth.NewLocalVerifier(cfg.OrchestratorBaseURL, cfg.JWKSURL, pres.Client, authc)
+	}
 
 	// Initialize Redis message subscriber
 	subscriber := delivery.NewRedisMessageSubscriber(cfg.RedisAddr, cfg.RedisPassword, cfg.RedisDB)
 	hub := wsx.NewHub(subscriber)
 
+	// Typing indicators skip the queue and are published to the recipient directly
+	eventPublisher := delivery.NewRedisEventPublisher(cfg.RedisAddr, cfg.RedisPassword, cfg.RedisDB)
+
 	// Initialize RabbitMQ publisher
 	publisher, err := queue.NewRabbitMQPublisher(cfg.RabbitMQURL, cfg.RabbitMQQueueName)
 	if err != nil {
@@ -192,7 +202,7 @@ func main() {
 	log.Printf("RabbitMQ queue: %s", cfg.RabbitMQQueueName)
 
 	// Start server in goroutine
-	go readConnection(hub, pres, authc, msgService, cfg)
+	go readConnection(hub, pres, authc, msgService, eventPublisher, cfg)
 
 	// Wait for shutdown signal
 	<-ctx.Done()
@@ -203,6 +213,11 @@ func main() {
 		log.Printf("Error closing message service: %v", err)
 	}
 
+	log.Println("Closing Redis event publisher...")
+	if err := eventPublisher.Close(); err != nil {
+		log.Printf("Error closing Redis event publisher: %v", err)
+	}
+
 	log.Println("Closing Redis subscriber...")
 	if err := subscriber.Close(); err != nil {
 		log.Printf("Error closing Redis subscriber: %v", err)
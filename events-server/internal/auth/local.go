@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	httplib "github.com/kunal768/cmpe202/http-lib"
+	"github.com/redis/go-redis/v9"
+)
+
+// LocalVerifier checks access tokens against the orchestrator's published keys instead
+// of calling /api/events/verify for every connection. It applies the same checks as
+// that route: a valid token for userID, a verified email, and a session that hasn't
+// been signed out. The email check reads the token's ev claim, so a user who has just
+// verified their address needs a refreshed token before connecting.
+type LocalVerifier struct {
+	BaseURL string
+	Keys    *httplib.KeySet
+	// Fallback verifies tokens without a kid, which the orchestrator signs with its
+	// shared secret until it is given a signing key
+	Fallback AuthClient
+	// Revoked sessions are read from the keys the orchestrator writes to Redis
+	// (revoked_session:{id}, see orchestrator/internal/sessions); nil skips the check
+	Redis *redis.Client
+}
+
+// NewLocalVerifier creates a verifier that loads keys from jwksURL, defaulting to the
+// orchestrator's /.well-known/jwks.json, and hands tokens without a kid to fallback
+func NewLocalVerifier(baseURL, jwksURL string, rdb *redis.Client, fallback AuthClient) LocalVerifier {
+	if jwksURL == "" {
+		jwksURL = baseURL + "/.well-known/jwks.json"
+	}
+	keys := httplib.NewKeySet(nil)
+	keys.UseJWKS(jwksURL)
+	return LocalVerifier{BaseURL: baseURL, Keys: keys, Fallback: fallback, Redis: rdb}
+}
+
+func (v LocalVerifier) Verify(ctx context.Context, userID string, bearerToken string) error {
+	claims, err := v.Keys.Parse(bearerToken)
+	if errors.Is(err, httplib.ErrNoKeyID) && v.Fallback != nil {
+		return v.Fallback.Verify(ctx, userID, bearerToken)
+	}
+	if err != nil {
+		return fmt.Errorf("auth verify failed: %w", err)
+	}
+	if tokenType, _ := claims["type"].(string); tokenType != "access" {
+		return fmt.Errorf("auth verify failed: invalid token type")
+	}
+	if sub, _ := claims["userId"].(string); sub != userID {
+		return fmt.Errorf("auth verify failed: token does not belong to user")
+	}
+	if verified, _ := claims["ev"].(bool); !verified {
+		return fmt.Errorf("auth verify failed: email not verified")
+	}
+
+	sessionID, _ := claims["sid"].(string)
+	if sessionID != "" && v.Redis != nil {
+		n, err := v.Redis.Exists(ctx, "revoked_session:"+sessionID).Result()
+		if err != nil {
+			// Same as the orchestrator: an unreachable cache doesn't lock everyone out
+			log.Printf("session revocation check failed for %s; continuing: %v", sessionID, err)
+		} else if n > 0 {
+			return fmt.Errorf("auth verify failed: session revoked")
+		}
+	}
+	return nil
+}
+
+func (v LocalVerifier) GetBaseURL() string {
+	return v.BaseURL
+}
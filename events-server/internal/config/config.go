@@ -16,6 +16,10 @@ type Config struct {
 	SkipAuth            bool
 	RabbitMQURL         string
 	RabbitMQQueueName   string
+	// AuthVerifyMode is "local" (check tokens against the orchestrator's JWKS) or
+	// "remote" (call /api/events/verify for every connection)
+	AuthVerifyMode string
+	JWKSURL        string
 }
 
 func getenv(key string) string {
@@ -65,5 +69,7 @@ func Load() Config {
 		PresenceTTLSeconds:  getenvInt("PRESENCE_TTL_SECONDS"),
 		RabbitMQURL:         getenv("RABBITMQ_URL"),
 		RabbitMQQueueName:   getenv("RABBITMQ_QUEUE_NAME"),
+		AuthVerifyMode:      getenvOptional("AUTH_VERIFY_MODE"),
+		JWKSURL:             getenvOptional("JWT_JWKS_URL"), // Optional: defaults to the orchestrator's JWKS
 	}
 }
@@ -0,0 +1,49 @@
+package delivery
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// EventPublisher pushes ephemeral events (typing, ...) straight to a user's channel,
+// where every events-server instance holding one of their connections picks them up
+type EventPublisher interface {
+	PublishToUser(ctx context.Context, userID string, event []byte) error
+	Close() error
+}
+
+// RedisEventPublisher implements EventPublisher on the user:{id}:messages channels
+type RedisEventPublisher struct {
+	client *redis.Client
+}
+
+// NewRedisEventPublisher creates a new Redis event publisher
+func NewRedisEventPublisher(addr, password string, db int) *RedisEventPublisher {
+	return &RedisEventPublisher{
+		client: redis.NewClient(&redis.Options{
+			Addr:     addr,
+			Password: password,
+			DB:       db,
+		}),
+	}
+}
+
+// PublishToUser publishes an event to a user's channel. Nobody listening is not an
+// error: the event only matters to connections that are open right now.
+func (r *RedisEventPublisher) PublishToUser(ctx context.Context, userID string, event []byte) error {
+	channel := fmt.Sprintf("user:%s:messages", userID)
+	if err := r.client.Publish(ctx, channel, event).Err(); err != nil {
+		return fmt.Errorf("failed to publish event to user %s: %w", userID, err)
+	}
+	return nil
+}
+
+// Close closes the Redis client
+func (r *RedisEventPublisher) Close() error {
+	if err := r.client.Close(); err != nil {
+		return fmt.Errorf("failed to close Redis client: %w", err)
+	}
+	return nil
+}
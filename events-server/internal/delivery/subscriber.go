@@ -50,6 +50,8 @@ func (r *RedisMessageSubscriber) Subscribe(ctx context.Context, userID string, m
 		return fmt.Errorf("subscriber is closed")
 	}
 
+	// The Hub subscribes once per user no matter how many devices connect, so this only
+	// happens if an Unsubscribe was missed
 	// Check if already subscribed - if so, unsubscribe the old one first
 	if oldPubsub, exists := r.subs[userID]; exists {
 		log.Printf("[Subscriber] User %s already has active subscription, closing old subscription before creating new one", userID)
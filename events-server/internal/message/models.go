@@ -15,10 +15,30 @@ type ChatMessage struct {
 	Content     string    `json:"content"`     // message text
 	Timestamp   time.Time `json:"timestamp"`   // server timestamp
 	Type        string    `json:"type"`        // "text" (extensible for images/files)
+
+	// ListingID scopes the message to the thread about one listing; nil is the
+	// general thread between the two users
+	ListingID *int64 `json:"listingId,omitempty"`
+	// Attachment is set on "image" messages; chat-consumer checks the blob and adds its metadata
+	Attachment *Attachment `json:"attachment,omitempty"`
+}
+
+// Attachment references an uploaded blob
+type Attachment struct {
+	BlobName string `json:"blobName"`
+}
+
+// ReadEvent records that a user has read a conversation up to MessageID. It shares the
+// chat queue; chat-consumer tells it apart by its type.
+type ReadEvent struct {
+	Type      string    `json:"type"` // "read"
+	ReaderID  string    `json:"readerId"`
+	MessageID string    `json:"messageId"`
+	Timestamp time.Time `json:"timestamp"`
 }
 
 // NewChatMessage creates a new ChatMessage with server-generated fields
-func NewChatMessage(senderID, recipientID, content string) *ChatMessage {
+func NewChatMessage(senderID, recipientID, content string, listingID *int64) *ChatMessage {
 	messageID, err := generateMessageID()
 	if err != nil {
 		// log error
@@ -32,6 +52,7 @@ func NewChatMessage(senderID, recipientID, content string) *ChatMessage {
 		Content:     content,
 		Timestamp:   time.Now().UTC(),
 		Type:        "text",
+		ListingID:   listingID,
 	}
 }
 
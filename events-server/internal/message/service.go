@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/kunal768/cmpe202/events-server/internal/queue"
 )
@@ -23,7 +24,8 @@ func NewMessageService(publisher queue.MessagePublisher) *MessageService {
 }
 
 // EnqueueChatMessage validates, enriches, and publishes a chat message
-func (s *MessageService) EnqueueChatMessage(ctx context.Context, senderID, recipientID, content string) error {
+// listingID is optional and places the message in that listing's thread.
+func (s *MessageService) EnqueueChatMessage(ctx context.Context, senderID, recipientID, content string, listingID *int64) error {
 	// Validate input
 	if senderID == "" {
 		return fmt.Errorf("senderID cannot be empty")
@@ -34,9 +36,46 @@ func (s *MessageService) EnqueueChatMessage(ctx context.Context, senderID, recip
 	if content == "" {
 		return fmt.Errorf("content cannot be empty")
 	}
+	if listingID != nil && *listingID <= 0 {
+		return fmt.Errorf("listingID must be positive")
+	}
 
 	// Create chat message with server-generated fields
-	chatMsg := NewChatMessage(senderID, recipientID, content)
+	chatMsg := NewChatMessage(senderID, recipientID, content, listingID)
+	if chatMsg == nil {
+		return fmt.Errorf("failed to create chat message")
+	}
+	return s.publishChatMessage(ctx, chatMsg)
+}
+
+// EnqueueImageMessage publishes an image message. The caption is optional; the blob is
+// validated by chat-consumer, which rejects the message if it isn't an acceptable image
+// uploaded by the sender.
+func (s *MessageService) EnqueueImageMessage(ctx context.Context, senderID, recipientID, blobName, caption string, listingID *int64) error {
+	if senderID == "" {
+		return fmt.Errorf("senderID cannot be empty")
+	}
+	if recipientID == "" {
+		return fmt.Errorf("recipientID cannot be empty")
+	}
+	if blobName == "" {
+		return fmt.Errorf("blobName cannot be empty")
+	}
+	if listingID != nil && *listingID <= 0 {
+		return fmt.Errorf("listingID must be positive")
+	}
+
+	chatMsg := NewChatMessage(senderID, recipientID, caption, listingID)
+	if chatMsg == nil {
+		return fmt.Errorf("failed to create chat message")
+	}
+	chatMsg.Type = "image"
+	chatMsg.Attachment = &Attachment{BlobName: blobName}
+	return s.publishChatMessage(ctx, chatMsg)
+}
+
+func (s *MessageService) publishChatMessage(ctx context.Context, chatMsg *ChatMessage) error {
+	senderID, recipientID := chatMsg.SenderID, chatMsg.RecipientID
 
 	// Marshal to JSON
 	messageBytes, err := json.Marshal(chatMsg)
@@ -55,6 +94,34 @@ func (s *MessageService) EnqueueChatMessage(ctx context.Context, senderID, recip
 	return nil
 }
 
+// EnqueueReadEvent publishes that readerID has read everything up to messageID
+func (s *MessageService) EnqueueReadEvent(ctx context.Context, readerID, messageID string) error {
+	if readerID == "" {
+		return fmt.Errorf("readerID cannot be empty")
+	}
+	if messageID == "" {
+		return fmt.Errorf("messageID cannot be empty")
+	}
+
+	eventBytes, err := json.Marshal(ReadEvent{
+		Type:      "read",
+		ReaderID:  readerID,
+		MessageID: messageID,
+		Timestamp: time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal read event: %w", err)
+	}
+
+	if err := s.publisher.Publish(ctx, eventBytes); err != nil {
+		log.Printf("Failed to publish read event from %s: %v", readerID, err)
+		return fmt.Errorf("failed to queue read event: %w", err)
+	}
+
+	log.Printf("Read event queued: %s read up to %s", readerID, messageID)
+	return nil
+}
+
 // Close gracefully closes the message service
 func (s *MessageService) Close() error {
 	if s.publisher != nil {
@@ -12,6 +12,7 @@ import (
 
 	"github.com/gobwas/ws/wsutil"
 	"github.com/kunal768/cmpe202/events-server/internal/auth"
+	"github.com/kunal768/cmpe202/events-server/internal/delivery"
 	"github.com/kunal768/cmpe202/events-server/internal/message"
 	"github.com/kunal768/cmpe202/events-server/internal/presence"
 )
@@ -23,13 +24,15 @@ type Client struct {
 	Presence presence.PresenceStore
 	Auth     auth.AuthClient
 	Messages *message.MessageService
+	Events   delivery.EventPublisher // typing indicators go straight to the recipient's channel
 
+	typing             typingTracker
 	undeliveredFetched atomic.Bool // Track if we've already fetched undelivered messages for this connection
 	refreshCancel      context.CancelFunc // Cancel function for presence refresh loop
 }
 
-func NewClient(conn net.Conn, hub *Hub, store presence.PresenceStore, authc auth.AuthClient, msgService *message.MessageService) *Client {
-	return &Client{conn: conn, Hub: hub, Presence: store, Auth: authc, Messages: msgService}
+func NewClient(conn net.Conn, hub *Hub, store presence.PresenceStore, authc auth.AuthClient, msgService *message.MessageService, events delivery.EventPublisher) *Client {
+	return &Client{conn: conn, Hub: hub, Presence: store, Auth: authc, Messages: msgService, Events: events}
 }
 
 func (c *Client) Close(ctx context.Context) {
@@ -39,9 +42,12 @@ func (c *Client) Close(ctx context.Context) {
 		c.refreshCancel = nil
 	}
 	
+	// Clear the typing indicators this connection left running
+	c.stopAllTyping()
+
+	// Hub.Unregister only marks the user offline when this was their last device
 	if c.ID != "" {
-		_ = c.Presence.SetOffline(ctx, c.ID)
-		c.Hub.Unregister(c.ID)
+		c.Hub.Unregister(ctx, c)
 	}
 	_ = c.conn.Close()
 }
@@ -90,20 +96,7 @@ func (c *Client) Serve(ctx context.Context) {
 	c.sendAuthAck("success", c.ID, "")
 	log.Printf("User %s authenticated, sending auth_ack", c.ID)
 
-	// Set presence FIRST before registering with hub
-	// This ensures presence is set before any messages can arrive
-	setOnlineStart := time.Now()
-	log.Printf("[TIMING] [%s] Starting SetOnline at %v (before Hub.Register)", c.ID, setOnlineStart)
-	if err := c.Presence.SetOnline(ctx, c.ID); err != nil {
-		log.Printf("[TIMING] [%s] Failed to set user %s online: %v (took %v)", c.ID, c.ID, err, time.Since(setOnlineStart))
-		// Continue anyway - presence might be set by refresh loop
-	} else {
-		setOnlineDuration := time.Since(setOnlineStart)
-		log.Printf("[TIMING] [%s] SetOnline completed successfully in %v", c.ID, setOnlineDuration)
-		log.Printf("User %s marked online in Redis (before subscription)", c.ID)
-	}
-
-	// Now register with hub (subscribes to Redis pub/sub)
+	// Register with hub: marks the user online, then subscribes to Redis pub/sub
 	// Hub.Register now waits for subscription confirmation before returning
 	registerStart := time.Now()
 	log.Printf("[TIMING] [%s] Starting Hub.Register at %v", c.ID, registerStart)
@@ -163,11 +156,31 @@ func (c *Client) Serve(ctx context.Context) {
 					log.Printf("Failed to refresh presence for %s: %v", c.ID, err)
 				}
 			}
+			// Sending the message ends the typing indicator for that conversation
+			c.stopTyping(chatMsg.RecipientID)
 			// Enqueue chat message (fire-and-forget)
-			if err := c.Messages.EnqueueChatMessage(ctx, c.ID, chatMsg.RecipientID, chatMsg.Msg); err != nil {
+			var err error
+			if chatMsg.Attachment != nil {
+				err = c.Messages.EnqueueImageMessage(ctx, c.ID, chatMsg.RecipientID, chatMsg.Attachment.BlobName, chatMsg.Msg, chatMsg.ListingID)
+			} else {
+				err = c.Messages.EnqueueChatMessage(ctx, c.ID, chatMsg.RecipientID, chatMsg.Msg, chatMsg.ListingID)
+			}
+			if err != nil {
 				log.Printf("Failed to enqueue chat message from %s: %v", c.ID, err)
 				// Continue serving client even if message queuing fails
 			}
+		case "typing_start":
+			typingMsg := payload.(TypingMessage)
+			if typingMsg.RecipientID != c.ID {
+				c.startTyping(typingMsg.RecipientID)
+			}
+		case "typing_stop":
+			c.stopTyping(payload.(TypingMessage).RecipientID)
+		case "read":
+			readMsg := payload.(ReadMessage)
+			if err := c.Messages.EnqueueReadEvent(ctx, c.ID, readMsg.MessageID); err != nil {
+				log.Printf("Failed to enqueue read event from %s: %v", c.ID, err)
+			}
 		default:
 			log.Printf("Unknown message type from %s: %s", c.ID, kind)
 		}
@@ -184,6 +197,9 @@ func (c *Client) SendMessage(msg []byte) error {
 		Content     string    `json:"content"`
 		Timestamp   time.Time `json:"timestamp"`
 		Type        string    `json:"type"`
+		ListingID   *int64    `json:"listingId,omitempty"`
+		// Attachment metadata is filled in by chat-consumer and passed through as is
+		Attachment json.RawMessage `json:"attachment,omitempty"`
 	}
 
 	if err := json.Unmarshal(msg, &messageData); err != nil {
@@ -258,6 +274,15 @@ func (c *Client) SendNotification(notification NotificationMessage) error {
 	return nil
 }
 
+// SendEvent writes a server event payload to the client unchanged
+func (c *Client) SendEvent(event []byte) error {
+	if err := wsutil.WriteServerText(c.conn, event); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+	log.Printf("Event sent to user %s", c.ID)
+	return nil
+}
+
 // triggerUndeliveredMessagesFetch calls orchestrator to fetch and republish undelivered messages
 // This is called after user is marked as online in Redis and subscription is confirmed
 // It only runs once per connection (tracked by undeliveredFetched flag set via CompareAndSwap in Serve())
@@ -11,9 +11,14 @@ import (
 	"github.com/kunal768/cmpe202/events-server/internal/delivery"
 )
 
+// Hub tracks the open connections of each user. A user can be connected from several
+// devices at once; the Redis subscription for a user is shared by all of them, so it
+// is opened with the first connection and closed with the last. Presence follows the
+// same rule: the user is ONLINE while any device is connected.
 type Hub struct {
 	mu              sync.RWMutex
-	clients         map[string]*Client
+	subMu           sync.Mutex // serializes presence and subscription changes so they follow the connection count
+	clients         map[string]map[*Client]struct{}
 	subscriber      delivery.MessageSubscriber
 	recentMessages  map[string]time.Time // Track recently sent messages by messageId to prevent duplicates
 	recentMessagesMu sync.RWMutex        // Mutex for recentMessages map
@@ -21,72 +26,143 @@ type Hub struct {
 
 func NewHub(subscriber delivery.MessageSubscriber) *Hub {
 	return &Hub{
-		clients:        make(map[string]*Client),
+		clients:        make(map[string]map[*Client]struct{}),
 		subscriber:     subscriber,
 		recentMessages: make(map[string]time.Time), // Initialize map to prevent nil map panic
 	}
 }
 
 func (h *Hub) Register(c *Client) {
+	h.subMu.Lock()
+
+	// Set presence FIRST before adding the connection
+	// This ensures presence is set before any messages can arrive
+	setOnlineStart := time.Now()
+	if err := c.Presence.SetOnline(context.Background(), c.ID); err != nil {
+		log.Printf("[TIMING] [%s] Failed to set user %s online: %v (took %v)", c.ID, c.ID, err, time.Since(setOnlineStart))
+		// Continue anyway - presence might be set by refresh loop
+	} else {
+		log.Printf("[TIMING] [%s] SetOnline completed successfully in %v", c.ID, time.Since(setOnlineStart))
+	}
+
 	h.mu.Lock()
-	h.clients[c.ID] = c
+	conns, ok := h.clients[c.ID]
+	if !ok {
+		conns = make(map[*Client]struct{})
+		h.clients[c.ID] = conns
+	}
+	conns[c] = struct{}{}
+	count := len(conns)
 	h.mu.Unlock()
 
-	// Subscribe to messages for this user and wait for confirmation
-	if h.subscriber != nil {
-		// Channel to signal subscription confirmation
-		confirmed := make(chan struct{})
-		
-		// Subscribe with confirmation callback
-		if err := h.subscriber.Subscribe(context.Background(), c.ID, h.handleMessage, func() {
-			// Subscription confirmed - signal that we're ready
-			close(confirmed)
-		}); err != nil {
-			log.Printf("Failed to subscribe to messages for user %s: %v", c.ID, err)
-			return
+	// Other devices of this user already share a subscription
+	if h.subscriber == nil || count > 1 {
+		h.subMu.Unlock()
+		if count > 1 {
+			log.Printf("User %s connected from another device (%d connections)", c.ID, count)
 		}
+		return
+	}
 
-		// Wait for subscription confirmation (with timeout)
-		waitStart := time.Now()
-		select {
-		case <-confirmed:
-			waitDuration := time.Since(waitStart)
-			log.Printf("[TIMING] [%s] Subscription confirmed for user %s, ready to receive messages (waited %v)", c.ID, c.ID, waitDuration)
-		case <-time.After(5 * time.Second):
-			waitDuration := time.Since(waitStart)
-			log.Printf("[TIMING] [%s] Warning: Subscription confirmation timeout for user %s (proceeding anyway, waited %v)", c.ID, c.ID, waitDuration)
-		}
+	// Subscribe to messages for this user and wait for confirmation
+	// Channel to signal subscription confirmation
+	confirmed := make(chan struct{})
+
+	// Subscribe with confirmation callback
+	err := h.subscriber.Subscribe(context.Background(), c.ID, h.handleMessage, func() {
+		// Subscription confirmed - signal that we're ready
+		close(confirmed)
+	})
+	h.subMu.Unlock()
+	if err != nil {
+		log.Printf("Failed to subscribe to messages for user %s: %v", c.ID, err)
+		return
+	}
+
+	// Wait for subscription confirmation (with timeout)
+	waitStart := time.Now()
+	select {
+	case <-confirmed:
+		waitDuration := time.Since(waitStart)
+		log.Printf("[TIMING] [%s] Subscription confirmed for user %s, ready to receive messages (waited %v)", c.ID, c.ID, waitDuration)
+	case <-time.After(5 * time.Second):
+		waitDuration := time.Since(waitStart)
+		log.Printf("[TIMING] [%s] Warning: Subscription confirmation timeout for user %s (proceeding anyway, waited %v)", c.ID, c.ID, waitDuration)
 	}
 }
 
-func (h *Hub) Unregister(userID string) {
+// Unregister removes one connection of a user. Closing their last connection marks the
+// user offline and unsubscribes from their messages; it reports whether it did.
+func (h *Hub) Unregister(ctx context.Context, c *Client) bool {
+	h.subMu.Lock()
+	defer h.subMu.Unlock()
+
 	h.mu.Lock()
-	delete(h.clients, userID)
+	conns := h.clients[c.ID]
+	delete(conns, c)
+	left := len(conns)
+	if left == 0 {
+		delete(h.clients, c.ID)
+	}
 	h.mu.Unlock()
 
+	if left > 0 {
+		log.Printf("User %s disconnected one device (%d connections left)", c.ID, left)
+		return false
+	}
+
+	_ = c.Presence.SetOffline(ctx, c.ID)
+
 	// Unsubscribe from messages for this user
 	if h.subscriber != nil {
-		if err := h.subscriber.Unsubscribe(context.Background(), userID); err != nil {
-			log.Printf("Failed to unsubscribe from messages for user %s: %v", userID, err)
+		if err := h.subscriber.Unsubscribe(context.Background(), c.ID); err != nil {
+			log.Printf("Failed to unsubscribe from messages for user %s: %v", c.ID, err)
 		}
 	}
+	return true
 }
 
-func (h *Hub) Get(userID string) (*Client, bool) {
+// Get returns the open connections of a user
+func (h *Hub) Get(userID string) ([]*Client, bool) {
 	h.mu.RLock()
-	c, ok := h.clients[userID]
-	h.mu.RUnlock()
-	return c, ok
+	defer h.mu.RUnlock()
+	conns := h.clients[userID]
+	if len(conns) == 0 {
+		return nil, false
+	}
+	out := make([]*Client, 0, len(conns))
+	for c := range conns {
+		out = append(out, c)
+	}
+	return out, true
 }
 
-// SendMessageToUser sends a message to a specific user via WebSocket if they are connected
-func (h *Hub) SendMessageToUser(userID string, message []byte) error {
-	client, exists := h.Get(userID)
+// fanOut calls send for every connection of a user. It fails only if no connection
+// took the message, so one dead device doesn't cost the others theirs.
+func (h *Hub) fanOut(userID string, send func(*Client) error) error {
+	clients, exists := h.Get(userID)
 	if !exists {
 		return fmt.Errorf("user %s is not connected", userID)
 	}
+	var lastErr error
+	delivered := 0
+	for _, c := range clients {
+		if err := send(c); err != nil {
+			log.Printf("[Hub] Failed to deliver to a connection of user %s: %v", userID, err)
+			lastErr = err
+			continue
+		}
+		delivered++
+	}
+	if delivered == 0 {
+		return lastErr
+	}
+	return nil
+}
 
-	return client.SendMessage(message)
+// SendMessageToUser sends a message to every connection of a user
+func (h *Hub) SendMessageToUser(userID string, message []byte) error {
+	return h.fanOut(userID, func(c *Client) error { return c.SendMessage(message) })
 }
 
 // handleMessage processes incoming messages from Redis pub/sub
@@ -96,17 +172,17 @@ func (h *Hub) handleMessage(msg []byte) error {
 	
 	// First, check if this is a notification message
 	var notificationCheck struct {
-		Type        string `json:"type"`
-		SubType     string `json:"subType"`
-		Count       int    `json:"count"`
-		RecipientID string `json:"recipientId"`
+		Type        string          `json:"type"`
+		SubType     string          `json:"subType"`
+		Count       int             `json:"count"`
+		RecipientID string          `json:"recipientId"`
+		Data        json.RawMessage `json:"data"`
 	}
 	
 	if err := json.Unmarshal(msg, &notificationCheck); err == nil && notificationCheck.Type == "notification" {
 		// This is a notification message
 		log.Printf("[Hub] Processing notification message for user %s (count: %d)", notificationCheck.RecipientID, notificationCheck.Count)
-		client, exists := h.Get(notificationCheck.RecipientID)
-		if !exists {
+		if _, exists := h.Get(notificationCheck.RecipientID); !exists {
 			log.Printf("[Hub] Client %s not found for notification delivery", notificationCheck.RecipientID)
 			return nil
 		}
@@ -115,8 +191,9 @@ func (h *Hub) handleMessage(msg []byte) error {
 			Type:    notificationCheck.Type,
 			SubType: notificationCheck.SubType,
 			Count:   notificationCheck.Count,
+			Data:    notificationCheck.Data,
 		}
-		if err := client.SendNotification(notifMsg); err != nil {
+		if err := h.fanOut(notificationCheck.RecipientID, func(c *Client) error { return c.SendNotification(notifMsg) }); err != nil {
 			log.Printf("[Hub] Failed to send notification to user %s: %v", notificationCheck.RecipientID, err)
 			return err
 		}
@@ -124,6 +201,19 @@ func (h *Hub) handleMessage(msg []byte) error {
 		return nil
 	}
 
+	// Server events (offers, ...) carry their own payload and are forwarded unchanged
+	if serverEventTypes[notificationCheck.Type] {
+		if _, exists := h.Get(notificationCheck.RecipientID); !exists {
+			log.Printf("[Hub] Client %s not found for %s event delivery", notificationCheck.RecipientID, notificationCheck.Type)
+			return nil
+		}
+		if err := h.fanOut(notificationCheck.RecipientID, func(c *Client) error { return c.SendEvent(msg) }); err != nil {
+			log.Printf("[Hub] Failed to send %s event to user %s: %v", notificationCheck.Type, notificationCheck.RecipientID, err)
+			return err
+		}
+		return nil
+	}
+
 	// Parse the message to get recipient ID (regular message)
 	var messageData struct {
 		MessageID   string `json:"messageId"`
@@ -149,15 +239,14 @@ func (h *Hub) handleMessage(msg []byte) error {
 	}
 
 	// Find the client
-	client, exists := h.Get(messageData.RecipientID)
-	if !exists {
+	if _, exists := h.Get(messageData.RecipientID); !exists {
 		log.Printf("[Hub] Client %s not found for message delivery (may have disconnected)", messageData.RecipientID)
 		return nil // Not an error, client might have disconnected
 	}
 
-	// Send the message to the client
+	// Send the message to every device of the user
 	sendStart := time.Now()
-	if err := client.SendMessage(msg); err != nil {
+	if err := h.SendMessageToUser(messageData.RecipientID, msg); err != nil {
 		log.Printf("[TIMING] [Hub] Failed to send message %s to user %s via WebSocket: %v (took %v, total from hub receive: %v)", messageData.MessageID, messageData.RecipientID, err, time.Since(sendStart), time.Since(hubReceiveTime))
 		return err
 	}
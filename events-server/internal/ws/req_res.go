@@ -27,6 +27,29 @@ type ChatMessage struct {
 	Type        string `json:"type"`        // "chat"
 	RecipientID string `json:"recipientId"` // target user
 	Msg         string `json:"msg"`         // message content
+
+	// ListingID is optional: the listing this conversation is about
+	ListingID *int64 `json:"listingId,omitempty"`
+	// Attachment makes this an image message; Msg is then an optional caption
+	Attachment *ChatAttachment `json:"attachment,omitempty"`
+}
+
+// ChatAttachment references an image uploaded through the orchestrator's upload URL
+type ChatAttachment struct {
+	BlobName string `json:"blobName"`
+}
+
+// TypingMessage starts or stops the typing indicator shown to RecipientID
+type TypingMessage struct {
+	Type        string `json:"type"`        // "typing_start" or "typing_stop"
+	RecipientID string `json:"recipientId"` // who is being typed to
+}
+
+// ReadMessage is sent when the user views a conversation: everything the other user
+// sent them up to and including MessageID has been read
+type ReadMessage struct {
+	Type      string `json:"type"`      // "read"
+	MessageID string `json:"messageId"` // latest message the user has seen
 }
 
 // AuthAckMessage is sent by server to acknowledge authentication
@@ -39,9 +62,18 @@ type AuthAckMessage struct {
 
 // NotificationMessage is sent by server to notify clients of events
 type NotificationMessage struct {
-	Type    string `json:"type"`    // "notification"
-	SubType string `json:"subType"` // "inbox"
-	Count   int    `json:"count"`   // number of undelivered messages
+	Type    string          `json:"type"`           // "notification"
+	SubType string          `json:"subType"`        // "inbox", "saved_search", ...
+	Count   int             `json:"count"`          // number of undelivered messages / unread notifications
+	Data    json.RawMessage `json:"data,omitempty"` // the persisted notification, for non-inbox subtypes
+}
+
+// serverEventTypes are event payloads published by other services (e.g. the orchestrator)
+// that the hub forwards to the client as-is
+var serverEventTypes = map[string]bool{
+	"offer":        true, // offer created/accepted/rejected/countered/declined
+	"read_receipt": true, // the recipient read some of the user's messages
+	"typing":       true, // someone started/stopped typing to the user
 }
 
 func ParseMessage(b []byte) (string, any, error) {
@@ -68,10 +100,32 @@ func ParseMessage(b []byte) (string, any, error) {
 			return "", nil, err
 		}
 		// Validate required fields
-		if m.RecipientID == "" || m.Msg == "" {
+		if m.Attachment != nil {
+			if m.RecipientID == "" || m.Attachment.BlobName == "" {
+				return "", nil, fmt.Errorf("image message missing required fields: recipientId and attachment.blobName")
+			}
+		} else if m.RecipientID == "" || m.Msg == "" {
 			return "", nil, fmt.Errorf("chat message missing required fields: recipientId and msg")
 		}
 		return env.Type, m, nil
+	case "typing_start", "typing_stop":
+		var m TypingMessage
+		if err := json.Unmarshal(b, &m); err != nil {
+			return "", nil, err
+		}
+		if m.RecipientID == "" {
+			return "", nil, fmt.Errorf("typing message missing required field: recipientId")
+		}
+		return env.Type, m, nil
+	case "read":
+		var m ReadMessage
+		if err := json.Unmarshal(b, &m); err != nil {
+			return "", nil, err
+		}
+		if m.MessageID == "" {
+			return "", nil, fmt.Errorf("read message missing required field: messageId")
+		}
+		return env.Type, m, nil
 	default:
 		return env.Type, nil, nil
 	}
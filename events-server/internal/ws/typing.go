@@ -0,0 +1,140 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+)
+
+// Typing indicators are ephemeral: they are published straight to the recipient's
+// Redis channel and never go through RabbitMQ or MongoDB. Clients repeat typing_start
+// while the user keeps typing; repeats are throttled, and an indicator that isn't
+// refreshed or stopped within typingTimeout is stopped by the server.
+const (
+	typingTimeout         = 6 * time.Second
+	typingRefreshInterval = 3 * time.Second // repeated starts within this aren't republished
+	typingEventsPerSecond = 5               // per connection, across all recipients
+)
+
+// TypingEvent is what the recipient's connections receive
+type TypingEvent struct {
+	Type        string `json:"type"` // "typing"
+	SenderID    string `json:"senderId"`
+	RecipientID string `json:"recipientId"`
+	State       string `json:"state"`               // "start" or "stop"
+	TimeoutMs   int64  `json:"timeoutMs,omitempty"` // on start: when the indicator lapses unless refreshed
+}
+
+type typingState struct {
+	sentAt time.Time   // when the last start was published
+	expiry *time.Timer // publishes the stop if the client never does
+}
+
+// typingTracker holds the indicators one connection has running, by recipient
+type typingTracker struct {
+	mu          sync.Mutex
+	active      map[string]*typingState
+	windowStart time.Time
+	windowCount int
+}
+
+// allow applies the per-connection rate limit; callers hold mu
+func (t *typingTracker) allow(now time.Time) bool {
+	if now.Sub(t.windowStart) >= time.Second {
+		t.windowStart = now
+		t.windowCount = 0
+	}
+	if t.windowCount >= typingEventsPerSecond {
+		return false
+	}
+	t.windowCount++
+	return true
+}
+
+func (c *Client) startTyping(recipientID string) {
+	c.typing.mu.Lock()
+	defer c.typing.mu.Unlock()
+	if c.typing.active == nil {
+		c.typing.active = make(map[string]*typingState)
+	}
+
+	now := time.Now()
+	st, ok := c.typing.active[recipientID]
+	if ok && st.expiry.Stop() {
+		st.expiry.Reset(typingTimeout)
+		if now.Sub(st.sentAt) < typingRefreshInterval {
+			return
+		}
+	} else {
+		// Either new, or its timer fired and the stop is on its way; start over
+		ok = false
+	}
+	if !c.typing.allow(now) {
+		return
+	}
+	if !ok {
+		st = &typingState{}
+		st.expiry = time.AfterFunc(typingTimeout, func() { c.expireTyping(recipientID, st) })
+		c.typing.active[recipientID] = st
+	}
+	st.sentAt = now
+	c.publishTyping(recipientID, "start")
+}
+
+func (c *Client) stopTyping(recipientID string) {
+	c.typing.mu.Lock()
+	defer c.typing.mu.Unlock()
+	st, ok := c.typing.active[recipientID]
+	if !ok {
+		return
+	}
+	st.expiry.Stop()
+	delete(c.typing.active, recipientID)
+	c.publishTyping(recipientID, "stop")
+}
+
+// expireTyping stops an indicator whose client went quiet
+func (c *Client) expireTyping(recipientID string, st *typingState) {
+	c.typing.mu.Lock()
+	defer c.typing.mu.Unlock()
+	if c.typing.active[recipientID] != st {
+		return // stopped or restarted meanwhile
+	}
+	delete(c.typing.active, recipientID)
+	c.publishTyping(recipientID, "stop")
+}
+
+// stopAllTyping clears every indicator of a closing connection
+func (c *Client) stopAllTyping() {
+	c.typing.mu.Lock()
+	defer c.typing.mu.Unlock()
+	for recipientID, st := range c.typing.active {
+		st.expiry.Stop()
+		c.publishTyping(recipientID, "stop")
+	}
+	c.typing.active = nil
+}
+
+// publishTyping sends an indicator to the recipient; callers hold typing.mu so starts
+// and stops reach them in order
+func (c *Client) publishTyping(recipientID, state string) {
+	if c.Events == nil {
+		return
+	}
+	event := TypingEvent{Type: "typing", SenderID: c.ID, RecipientID: recipientID, State: state}
+	if state == "start" {
+		event.TimeoutMs = typingTimeout.Milliseconds()
+	}
+	b, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Failed to marshal typing event: %v", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := c.Events.PublishToUser(ctx, recipientID, b); err != nil {
+		log.Printf("Failed to publish typing %s from %s to %s: %v", state, c.ID, recipientID, err)
+	}
+}
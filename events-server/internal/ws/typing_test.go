@@ -0,0 +1,162 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingPublisher keeps every typing event published, in order
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []TypingEvent
+}
+
+func (p *recordingPublisher) PublishToUser(_ context.Context, _ string, event []byte) error {
+	var e TypingEvent
+	if err := json.Unmarshal(event, &e); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, e)
+	return nil
+}
+
+func (p *recordingPublisher) Close() error { return nil }
+
+// states returns "recipient:state" for each event published so far
+func (p *recordingPublisher) states() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]string, 0, len(p.events))
+	for _, e := range p.events {
+		out = append(out, e.RecipientID+":"+e.State)
+	}
+	return out
+}
+
+func newTypingClient(t *testing.T) (*Client, *recordingPublisher) {
+	t.Helper()
+	pub := &recordingPublisher{}
+	c := &Client{ID: "sender", Events: pub}
+	t.Cleanup(c.stopAllTyping)
+	return c, pub
+}
+
+func TestTypingTrackerAllow(t *testing.T) {
+	base := time.Now()
+
+	tests := []struct {
+		name    string
+		offsets []time.Duration
+		want    []bool
+	}{
+		{"under limit", []time.Duration{0, 100, 200}, []bool{true, true, true}},
+		{"over limit in one second", []time.Duration{0, 1, 2, 3, 4, 5, 6}, []bool{true, true, true, true, true, false, false}},
+		{"window resets", []time.Duration{0, 1, 2, 3, 4, 5, 1000}, []bool{true, true, true, true, true, false, true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tr typingTracker
+			for i, off := range tt.offsets {
+				if got := tr.allow(base.Add(off * time.Millisecond)); got != tt.want[i] {
+					t.Errorf("allow #%d at +%dms = %v, want %v", i, off, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTypingStartStop(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps func(c *Client)
+		want  []string
+	}{
+		{"start then stop", func(c *Client) {
+			c.startTyping("bob")
+			c.stopTyping("bob")
+		}, []string{"bob:start", "bob:stop"}},
+		{"repeated starts are throttled", func(c *Client) {
+			c.startTyping("bob")
+			c.startTyping("bob")
+			c.startTyping("bob")
+		}, []string{"bob:start"}},
+		{"stop without start", func(c *Client) {
+			c.stopTyping("bob")
+		}, []string{}},
+		{"stop twice", func(c *Client) {
+			c.startTyping("bob")
+			c.stopTyping("bob")
+			c.stopTyping("bob")
+		}, []string{"bob:start", "bob:stop"}},
+		{"restart after stop", func(c *Client) {
+			c.startTyping("bob")
+			c.stopTyping("bob")
+			c.startTyping("bob")
+		}, []string{"bob:start", "bob:stop", "bob:start"}},
+		{"recipients are independent", func(c *Client) {
+			c.startTyping("bob")
+			c.startTyping("carol")
+			c.stopTyping("bob")
+		}, []string{"bob:start", "carol:start", "bob:stop"}},
+		{"rate limited across recipients", func(c *Client) {
+			for i := 0; i < typingEventsPerSecond+2; i++ {
+				c.startTyping(fmt.Sprintf("r%d", i))
+			}
+		}, []string{"r0:start", "r1:start", "r2:start", "r3:start", "r4:start"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, pub := newTypingClient(t)
+			tt.steps(c)
+			got := pub.states()
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("published %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTypingExpiry(t *testing.T) {
+	c, pub := newTypingClient(t)
+	c.startTyping("bob")
+
+	c.typing.mu.Lock()
+	st := c.typing.active["bob"]
+	c.typing.mu.Unlock()
+	if st == nil {
+		t.Fatal("no active indicator after start")
+	}
+
+	// A stale timer (the indicator was restarted) must not stop the new one
+	c.expireTyping("bob", &typingState{})
+	if got := pub.states(); fmt.Sprint(got) != "[bob:start]" {
+		t.Fatalf("after stale expiry published %v", got)
+	}
+
+	c.expireTyping("bob", st)
+	if got := pub.states(); fmt.Sprint(got) != "[bob:start bob:stop]" {
+		t.Fatalf("after expiry published %v", got)
+	}
+
+	// The client's own stop arriving late is a no-op
+	c.stopTyping("bob")
+	if got := pub.states(); len(got) != 2 {
+		t.Errorf("stop after expiry published %v", got)
+	}
+
+	events := pub.events
+	if events[0].TimeoutMs != typingTimeout.Milliseconds() || events[0].SenderID != "sender" {
+		t.Errorf("start event = %+v", events[0])
+	}
+	if events[1].TimeoutMs != 0 {
+		t.Errorf("stop event has timeout %d", events[1].TimeoutMs)
+	}
+}
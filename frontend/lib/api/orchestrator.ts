@@ -535,6 +535,9 @@ export const orchestratorApi = {
 
     // Build query string from filters
     const params = new URLSearchParams()
+    if (filters?.q) {
+      params.set("q", filters.q)
+    }
     if (filters?.keywords) {
       params.set("keywords", filters.keywords)
     }
@@ -86,6 +86,7 @@ export interface FetchFlaggedListingsResponse {
 }
 
 export interface FetchAllListingsRequest {
+  q?: string
   keywords?: string
   category?: string
   status?: string
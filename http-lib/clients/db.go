@@ -2,6 +2,8 @@ package clients
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -11,3 +13,39 @@ func FetchUserRole(ctx context.Context, dbPool *pgxpool.Pool, userId string) (st
 	err := dbPool.QueryRow(ctx, `SELECT role FROM users WHERE user_id = $1`, userId).Scan(&role)
 	return role, err
 }
+
+// AccountState is a user's role together with whether they may use the API
+type AccountState struct {
+	Role           string
+	Status         string
+	SuspendedUntil *time.Time
+	Reason         *string
+	Restricted     bool // banned, or suspended and the suspension hasn't lapsed
+	EmailVerified  bool // the user confirmed their campus email
+}
+
+// FetchAccountState loads the role, account status and email verification of a user in one query
+func FetchAccountState(ctx context.Context, dbPool *pgxpool.Pool, userId string) (AccountState, error) {
+	var s AccountState
+	err := dbPool.QueryRow(ctx, `
+		SELECT role, account_status, suspended_until, status_reason, account_is_restricted(account_status, suspended_until),
+			email_verified_at IS NOT NULL
+		FROM users WHERE user_id = $1
+	`, userId).Scan(&s.Role, &s.Status, &s.SuspendedUntil, &s.Reason, &s.Restricted, &s.EmailVerified)
+	return s, err
+}
+
+// Message describes a restricted account to its owner
+func (s AccountState) Message() string {
+	msg := "Your account has been banned"
+	if s.Status == "SUSPENDED" {
+		msg = "Your account is suspended"
+		if s.SuspendedUntil != nil {
+			msg += " until " + s.SuspendedUntil.UTC().Format(time.RFC3339)
+		}
+	}
+	if s.Reason != nil && *s.Reason != "" {
+		msg = fmt.Sprintf("%s: %s", msg, *s.Reason)
+	}
+	return msg
+}
@@ -0,0 +1,464 @@
+package httplib
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+/*
+	Access token keys.
+
+	Tokens are signed with one private key (RS256 or EdDSA) and name it in the kid
+	header. Verifiers accept any key in their set, so rotating goes:
+	  1. add the new public key to JWT_VERIFICATION_KEY_FILES everywhere (the JWKS
+	     endpoint starts publishing it)
+	  2. make it JWT_SIGNING_KEY_FILE and move the old key to JWT_VERIFICATION_KEY_FILES
+	  3. drop the old key once tokens signed with it have expired: AccessTokenTTL, or
+	     EMAIL_VERIFICATION_TTL for verification links
+
+	Tokens without a kid are HS256 with JWT_TOKEN_SECRET, which keeps working while a
+	deployment moves off the shared secret.
+*/
+
+// jwtKey is one key of a KeySet. private is only set for the signing key.
+type jwtKey struct {
+	id      string
+	method  jwt.SigningMethod
+	public  crypto.PublicKey
+	private crypto.Signer
+}
+
+// KeySet signs and verifies access tokens
+type KeySet struct {
+	mu      sync.RWMutex
+	signer  *jwtKey
+	keys    map[string]*jwtKey // verification keys by kid, including the signer
+	secret  []byte             // HS256 secret for tokens without a kid
+	jwksURL string             // remote JWKS to load unknown kids from
+	remote  map[string]*jwtKey
+	fetched time.Time
+}
+
+// ErrNoKeyID is returned for an HS256 token (no kid) by a key set without the secret
+var ErrNoKeyID = errors.New("token has no kid")
+
+// jwksRefetchInterval limits how often an unknown kid makes a KeySet re-fetch its JWKS
+const jwksRefetchInterval = time.Minute
+
+// NewKeySet creates a key set that verifies (and, without a signing key, signs) HS256
+// tokens with secret. secret may be empty once every service uses key pairs.
+func NewKeySet(secret []byte) *KeySet {
+	return &KeySet{keys: make(map[string]*jwtKey), secret: secret}
+}
+
+func methodFor(pub crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch pub.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %T (use RSA or Ed25519)", pub)
+}
+
+// KeyID derives a kid from a public key: a prefix of the SHA-256 of its DER encoding
+func KeyID(pub crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return base64.RawURLEncoding.EncodeToString(sum[:12]), nil
+}
+
+// SetSigningKey makes key the key new tokens are signed with. An empty id is derived
+// with KeyID.
+func (ks *KeySet) SetSigningKey(id string, key crypto.Signer) error {
+	method, err := methodFor(key.Public())
+	if err != nil {
+		return err
+	}
+	if id == "" {
+		if id, err = KeyID(key.Public()); err != nil {
+			return err
+		}
+	}
+	k := &jwtKey{id: id, method: method, public: key.Public(), private: key}
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.signer = k
+	ks.keys[id] = k
+	return nil
+}
+
+// AddVerificationKey accepts tokens signed by the private half of pub. An empty id is
+// derived with KeyID.
+func (ks *KeySet) AddVerificationKey(id string, pub crypto.PublicKey) error {
+	method, err := methodFor(pub)
+	if err != nil {
+		return err
+	}
+	if id == "" {
+		if id, err = KeyID(pub); err != nil {
+			return err
+		}
+	}
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.keys[id] = &jwtKey{id: id, method: method, public: pub}
+	return nil
+}
+
+// UseJWKS makes the key set load keys it doesn't know from the JWKS at url
+func (ks *KeySet) UseJWKS(url string) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.jwksURL = url
+}
+
+// Sign signs claims with the signing key, or with the HS256 secret when there is none
+func (ks *KeySet) Sign(claims jwt.MapClaims) (string, error) {
+	ks.mu.RLock()
+	signer, secret := ks.signer, ks.secret
+	ks.mu.RUnlock()
+
+	if signer != nil {
+		token := jwt.NewWithClaims(signer.method, claims)
+		token.Header["kid"] = signer.id
+		return token.SignedString(signer.private)
+	}
+	if len(secret) == 0 {
+		return "", fmt.Errorf("no JWT signing key configured")
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+}
+
+// Parse verifies a token against the key set and returns its claims
+func (ks *KeySet) Parse(tokenString string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, ks.keyFunc, jwt.WithValidMethods([]string{
+		jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg(), jwt.SigningMethodHS256.Alg(),
+	}))
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("invalid claims")
+	}
+	return claims, nil
+}
+
+func (ks *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrNoKeyID
+		}
+		ks.mu.RLock()
+		defer ks.mu.RUnlock()
+		if len(ks.secret) == 0 {
+			return nil, ErrNoKeyID
+		}
+		return ks.secret, nil
+	}
+
+	k := ks.lookup(kid)
+	if k == nil {
+		return nil, fmt.Errorf("unknown kid %q", kid)
+	}
+	// The key decides the algorithm, never the token
+	if token.Method.Alg() != k.method.Alg() {
+		return nil, fmt.Errorf("kid %q does not sign with %s", kid, token.Method.Alg())
+	}
+	return k.public, nil
+}
+
+// lookup finds a verification key, re-fetching the JWKS (at most once a minute) when
+// the kid is unknown, e.g. right after the issuer rotated keys
+func (ks *KeySet) lookup(kid string) *jwtKey {
+	ks.mu.RLock()
+	k := ks.keys[kid]
+	if k == nil {
+		k = ks.remote[kid]
+	}
+	url, fetched := ks.jwksURL, ks.fetched
+	ks.mu.RUnlock()
+	if k != nil || url == "" || time.Since(fetched) < jwksRefetchInterval {
+		return k
+	}
+
+	// Claim the fetch so concurrent lookups don't repeat it, then fetch without the
+	// lock: signing and known kids must not wait on the network
+	ks.mu.Lock()
+	if time.Since(ks.fetched) < jwksRefetchInterval {
+		k = ks.remote[kid]
+		ks.mu.Unlock()
+		return k
+	}
+	ks.fetched = time.Now()
+	ks.mu.Unlock()
+
+	remote, err := fetchJWKS(url)
+	if err != nil {
+		log.Printf("Failed to fetch JWKS from %s: %v", url, err)
+		return nil
+	}
+	ks.mu.Lock()
+	ks.remote = remote
+	ks.mu.Unlock()
+	return remote[kid]
+}
+
+// JWK is a public key in JSON Web Key form
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	N         string `json:"n,omitempty"`   // RSA modulus
+	E         string `json:"e,omitempty"`   // RSA exponent
+	Curve     string `json:"crv,omitempty"` // OKP curve
+	X         string `json:"x,omitempty"`   // OKP public key
+}
+
+// JWKS is a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of the set (the HS256 secret is never published)
+func (ks *KeySet) JWKS() JWKS {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	set := JWKS{Keys: []JWK{}}
+	for _, k := range ks.keys {
+		jwk := JWK{KeyID: k.id, Algorithm: k.method.Alg(), Use: "sig"}
+		switch pub := k.public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// JWKSHandler serves the key set's public keys, e.g. at /.well-known/jwks.json
+func JWKSHandler(ks *KeySet) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		WriteJSON(w, http.StatusOK, ks.JWKS())
+	})
+}
+
+func fetchJWKS(url string) (map[string]*jwtKey, error) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	var set JWKS
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]*jwtKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		pub, err := jwk.publicKey()
+		if err != nil {
+			log.Printf("Skipping JWK %q: %v", jwk.KeyID, err)
+			continue
+		}
+		method, err := methodFor(pub)
+		if err != nil {
+			continue
+		}
+		keys[jwk.KeyID] = &jwtKey{id: jwk.KeyID, method: method, public: pub}
+	}
+	return keys, nil
+}
+
+func (jwk JWK) publicKey() (crypto.PublicKey, error) {
+	switch jwk.KeyType {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent: %w", err)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "OKP":
+		if jwk.Curve != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", jwk.KeyType)
+}
+
+// parsePEMKey reads a PEM private key (PKCS#8, or PKCS#1 RSA) or PKIX public key
+func parsePEMKey(path string) (crypto.PublicKey, crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, nil, fmt.Errorf("%s: no PEM data", path)
+	}
+	switch block.Type {
+	case "PUBLIC KEY":
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		return pub, nil, err
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		return key.Public(), key, nil
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, nil, fmt.Errorf("%s: unsupported private key", path)
+		}
+		return signer.Public(), signer, nil
+	}
+	return nil, nil, fmt.Errorf("%s: unsupported PEM block %q", path, block.Type)
+}
+
+// KeySetFromEnv builds a key set from:
+//   - JWT_TOKEN_SECRET: HS256 secret for tokens without a kid (optional)
+//   - JWT_SIGNING_KEY_FILE, JWT_SIGNING_KEY_ID: PEM private key new tokens are signed
+//     with, and its kid (derived from the key when empty)
+//   - JWT_VERIFICATION_KEY_FILES: comma-separated [kid=]path PEM keys that are still
+//     (or already) accepted
+//   - JWT_JWKS_URL: JWKS to fetch unknown keys from, for services that only verify
+func KeySetFromEnv() (*KeySet, error) {
+	ks := NewKeySet([]byte(os.Getenv("JWT_TOKEN_SECRET")))
+
+	if path := os.Getenv("JWT_SIGNING_KEY_FILE"); path != "" {
+		_, signer, err := parsePEMKey(path)
+		if err != nil {
+			return nil, err
+		}
+		if signer == nil {
+			return nil, fmt.Errorf("JWT_SIGNING_KEY_FILE must hold a private key")
+		}
+		if err := ks.SetSigningKey(os.Getenv("JWT_SIGNING_KEY_ID"), signer); err != nil {
+			return nil, err
+		}
+	}
+	for _, entry := range strings.Split(os.Getenv("JWT_VERIFICATION_KEY_FILES"), ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		id, path := "", entry
+		if i := strings.Index(entry, "="); i >= 0 {
+			id, path = entry[:i], entry[i+1:]
+		}
+		pub, _, err := parsePEMKey(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := ks.AddVerificationKey(id, pub); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if url := os.Getenv("JWT_JWKS_URL"); url != "" {
+		ks.UseJWKS(url)
+	}
+	return ks, nil
+}
+
+var (
+	activeKeys     atomic.Pointer[KeySet]
+	activeKeysOnce sync.Once
+)
+
+// UseKeySet sets the key set GenerateJWT and AuthMiddleWare use. Services that don't
+// call it get KeySetFromEnv the first time a token is signed or verified.
+func UseKeySet(ks *KeySet) {
+	activeKeys.Store(ks)
+}
+
+func keySet() *KeySet {
+	if ks := activeKeys.Load(); ks != nil {
+		return ks
+	}
+	activeKeysOnce.Do(func() {
+		ks, err := KeySetFromEnv()
+		if err != nil {
+			log.Printf("Failed to load JWT keys, falling back to JWT_TOKEN_SECRET only: %v", err)
+			ks = NewKeySet([]byte(os.Getenv("JWT_TOKEN_SECRET")))
+		}
+		// UseKeySet may have won the race while the keys were loading
+		activeKeys.CompareAndSwap(nil, ks)
+	})
+	return activeKeys.Load()
+}
+
+// AccessClaims is what an access token says about its holder
+type AccessClaims struct {
+	UserID        string
+	SessionID     string // empty for tokens issued before sessions
+	EmailVerified bool
+}
+
+// ParseAccessToken verifies an access token and returns its claims
+func ParseAccessToken(tokenString string) (AccessClaims, error) {
+	claims, err := keySet().Parse(tokenString)
+	if err != nil {
+		return AccessClaims{}, err
+	}
+	if tokenType, _ := claims["type"].(string); tokenType != "access" {
+		return AccessClaims{}, fmt.Errorf("invalid token type")
+	}
+	userID, ok := claims["userId"].(string)
+	if !ok {
+		return AccessClaims{}, fmt.Errorf("user ID not found in token")
+	}
+	sessionID, _ := claims["sid"].(string)
+	emailVerified, _ := claims["ev"].(bool)
+	return AccessClaims{UserID: userID, SessionID: sessionID, EmailVerified: emailVerified}, nil
+}
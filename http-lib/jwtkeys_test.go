@@ -0,0 +1,209 @@
+package httplib
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func testClaims() jwt.MapClaims {
+	return jwt.MapClaims{"userId": "u1", "type": "access", "exp": time.Now().Add(time.Minute).Unix()}
+}
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey: %v", err)
+	}
+	return key
+}
+
+func newEd25519Key(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("ed25519.GenerateKey: %v", err)
+	}
+	return key
+}
+
+func TestKeySetParse(t *testing.T) {
+	rsaKey := newRSAKey(t)
+	edKey := newEd25519Key(t)
+	secret := []byte("test-secret")
+
+	issuer := NewKeySet(secret)
+	if err := issuer.SetSigningKey("rsa-1", rsaKey); err != nil {
+		t.Fatalf("SetSigningKey: %v", err)
+	}
+	edIssuer := NewKeySet(nil)
+	if err := edIssuer.SetSigningKey("", edKey); err != nil {
+		t.Fatalf("SetSigningKey: %v", err)
+	}
+	edKid, err := KeyID(edKey.Public())
+	if err != nil {
+		t.Fatalf("KeyID: %v", err)
+	}
+
+	verifier := NewKeySet(secret)
+	if err := verifier.AddVerificationKey("rsa-1", rsaKey.Public()); err != nil {
+		t.Fatalf("AddVerificationKey: %v", err)
+	}
+	if err := verifier.AddVerificationKey("", edKey.Public()); err != nil {
+		t.Fatalf("AddVerificationKey: %v", err)
+	}
+	noSecret := NewKeySet(nil)
+	if err := noSecret.AddVerificationKey("rsa-1", rsaKey.Public()); err != nil {
+		t.Fatalf("AddVerificationKey: %v", err)
+	}
+
+	sign := func(ks *KeySet) string {
+		token, err := ks.Sign(testClaims())
+		if err != nil {
+			t.Fatalf("Sign: %v", err)
+		}
+		return token
+	}
+	// hs256 signs with the shared secret, optionally naming kid
+	hs256 := func(kid string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims())
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		s, err := token.SignedString(secret)
+		if err != nil {
+			t.Fatalf("SignedString: %v", err)
+		}
+		return s
+	}
+	unknownKid := func() string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, testClaims())
+		token.Header["kid"] = "rsa-2"
+		s, err := token.SignedString(newRSAKey(t))
+		if err != nil {
+			t.Fatalf("SignedString: %v", err)
+		}
+		return s
+	}
+	edWithoutKid := func() string {
+		s, err := jwt.NewWithClaims(jwt.SigningMethodEdDSA, testClaims()).SignedString(edKey)
+		if err != nil {
+			t.Fatalf("SignedString: %v", err)
+		}
+		return s
+	}
+
+	tests := []struct {
+		name    string
+		ks      *KeySet
+		token   string
+		wantErr bool
+		errIs   error
+	}{
+		{"rsa signing key", verifier, sign(issuer), false, nil},
+		{"ed25519 derived kid", verifier, sign(edIssuer), false, nil},
+		{"hs256 without kid", verifier, hs256(""), false, nil},
+		{"hs256 without secret", noSecret, hs256(""), true, ErrNoKeyID},
+		{"asymmetric without kid", verifier, edWithoutKid(), true, ErrNoKeyID},
+		{"unknown kid", verifier, unknownKid(), true, nil},
+		{"alg does not match kid", verifier, hs256("rsa-1"), true, nil},
+		{"hs256 naming ed25519 kid", verifier, hs256(edKid), true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := tt.ks.Parse(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.errIs != nil && !errors.Is(err, tt.errIs) {
+				t.Errorf("Parse err = %v, want %v", err, tt.errIs)
+			}
+			if !tt.wantErr && claims["userId"] != "u1" {
+				t.Errorf("userId = %v, want u1", claims["userId"])
+			}
+		})
+	}
+}
+
+func TestKeySetJWKSRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		key  func(t *testing.T) (id string, ks *KeySet)
+	}{
+		{"rsa", func(t *testing.T) (string, *KeySet) {
+			ks := NewKeySet(nil)
+			if err := ks.SetSigningKey("rsa-1", newRSAKey(t)); err != nil {
+				t.Fatalf("SetSigningKey: %v", err)
+			}
+			return "rsa-1", ks
+		}},
+		{"ed25519", func(t *testing.T) (string, *KeySet) {
+			ks := NewKeySet(nil)
+			if err := ks.SetSigningKey("ed-1", newEd25519Key(t)); err != nil {
+				t.Fatalf("SetSigningKey: %v", err)
+			}
+			return "ed-1", ks
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kid, issuer := tt.key(t)
+
+			set := issuer.JWKS()
+			if len(set.Keys) != 1 || set.Keys[0].KeyID != kid {
+				t.Fatalf("JWKS = %+v, want one key %q", set, kid)
+			}
+
+			var fetches atomic.Int32
+			handler := JWKSHandler(issuer)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fetches.Add(1)
+				handler.ServeHTTP(w, r)
+			}))
+			defer srv.Close()
+
+			verifier := NewKeySet(nil)
+			verifier.UseJWKS(srv.URL)
+
+			token, err := issuer.Sign(testClaims())
+			if err != nil {
+				t.Fatalf("Sign: %v", err)
+			}
+			for i := 0; i < 3; i++ {
+				if _, err := verifier.Parse(token); err != nil {
+					t.Fatalf("Parse: %v", err)
+				}
+			}
+			if n := fetches.Load(); n != 1 {
+				t.Errorf("JWKS fetched %d times, want 1", n)
+			}
+
+			// An unknown kid right after a fetch must not hit the issuer again
+			other := NewKeySet(nil)
+			if err := other.SetSigningKey("other", newEd25519Key(t)); err != nil {
+				t.Fatalf("SetSigningKey: %v", err)
+			}
+			foreign, err := other.Sign(testClaims())
+			if err != nil {
+				t.Fatalf("Sign: %v", err)
+			}
+			if _, err := verifier.Parse(foreign); err == nil {
+				t.Error("Parse of unknown kid: want error")
+			}
+			if n := fetches.Load(); n != 1 {
+				t.Errorf("JWKS fetched %d times after unknown kid, want 1", n)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package httplib
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/kunal768/cmpe202/http-lib/clients"
 	"github.com/sirupsen/logrus"
@@ -20,45 +22,98 @@ import (
 	3. http-encoder decoder
 */
 
-func AuthMiddleWare(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		tokenString := r.Header.Get("Authorization")
-		if tokenString == "" {
+// accountStatePool, when set, lets AuthMiddleWare turn away suspended and banned users
+var accountStatePool *pgxpool.Pool
+
+// EnableAccountStateCheck makes AuthMiddleWare look up the account behind every valid
+// token and reject unknown, suspended and banned users. The state is kept on the
+// request, so RoleInjectionMiddleWare and VerifiedEmailMiddleWare don't look it up again.
+func EnableAccountStateCheck(dbPool *pgxpool.Pool) {
+	accountStatePool = dbPool
+}
+
+// SessionRevocations reports sessions that were signed out before their access tokens
+// expire
+type SessionRevocations interface {
+	IsRevoked(ctx context.Context, sessionID string) (bool, error)
+}
+
+// sessionRevocations, when set, lets AuthMiddleWare turn away access tokens of revoked sessions
+var sessionRevocations SessionRevocations
+
+// EnableSessionRevocationCheck makes AuthMiddleWare reject access tokens whose session
+// (the sid claim) has been revoked. Lookups that fail let the request through, since
+// the token expires within AccessTokenTTL anyway.
+func EnableSessionRevocationCheck(revocations SessionRevocations) {
+	sessionRevocations = revocations
+}
+
+// accountStateKey holds the clients.AccountState loaded for the request, so the
+// middlewares in a chain share a single lookup
+const accountStateKey = ContextKey("accountState")
+
+// requireAccount loads the account behind the authenticated request, once per request,
+// and turns away unknown, suspended and banned users. A failed lookup also turns the
+// request away. It returns the request carrying the state, or false once it has
+// written the response.
+func requireAccount(w http.ResponseWriter, r *http.Request, dbPool *pgxpool.Pool, userID string) (clients.AccountState, *http.Request, bool) {
+	state, ok := r.Context().Value(accountStateKey).(clients.AccountState)
+	if !ok {
+		var err error
+		state, err = clients.FetchAccountState(r.Context(), dbPool, userID)
+		if errors.Is(err, pgx.ErrNoRows) {
 			WriteJSON(w, http.StatusUnauthorized, map[string]string{
-				"error":   "Authorization header required",
+				"error":   "User not found",
 				"message": "Please provide a valid access token",
 			})
-			return
+			return state, r, false
 		}
-		tokenString = strings.Replace(tokenString, "Bearer ", "", 1)
-
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method")
-			}
-			return []byte(os.Getenv("JWT_TOKEN_SECRET")), nil
-		})
-
 		if err != nil {
-			WriteJSON(w, http.StatusUnauthorized, map[string]string{
-				"error":   "Invalid token",
-				"message": "Please provide a valid access token",
+			logrus.WithError(err).WithField("userId", userID).Error("account state lookup failed")
+			WriteJSON(w, http.StatusServiceUnavailable, map[string]string{
+				"error":   "Account check failed",
+				"message": "Please try again later",
 			})
-			return
+			return state, r, false
 		}
+		r = r.WithContext(context.WithValue(r.Context(), accountStateKey, state))
+	}
+
+	if state.Restricted {
+		writeAccountRestricted(w, state)
+		return state, r, false
+	}
+	return state, r, true
+}
+
+// writeAccountRestricted rejects a request from a suspended or banned user
+func writeAccountRestricted(w http.ResponseWriter, state clients.AccountState) {
+	title := "Account banned"
+	if state.Status == string(SUSPENDED) {
+		title = "Account suspended"
+	}
+	WriteJSON(w, http.StatusForbidden, map[string]string{
+		"error":   title,
+		"message": state.Message(),
+	})
+}
 
-		if !token.Valid {
+func AuthMiddleWare(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenString := r.Header.Get("Authorization")
+		if tokenString == "" {
 			WriteJSON(w, http.StatusUnauthorized, map[string]string{
-				"error":   "Invalid token",
+				"error":   "Authorization header required",
 				"message": "Please provide a valid access token",
 			})
 			return
 		}
+		tokenString = strings.Replace(tokenString, "Bearer ", "", 1)
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
+		claims, err := keySet().Parse(tokenString)
+		if err != nil {
 			WriteJSON(w, http.StatusUnauthorized, map[string]string{
-				"error":   "Invalid token claims",
+				"error":   "Invalid token",
 				"message": "Please provide a valid access token",
 			})
 			return
@@ -83,26 +138,75 @@ func AuthMiddleWare(next http.Handler) http.Handler {
 			return
 		}
 
+		// Tokens issued before sessions existed have no sid and simply run out
+		sessionID, _ := claims["sid"].(string)
+		if sessionID != "" && sessionRevocations != nil {
+			revoked, err := sessionRevocations.IsRevoked(r.Context(), sessionID)
+			if err != nil {
+				logrus.WithError(err).WithField("sessionId", sessionID).Warn("session revocation check failed; continuing")
+			} else if revoked {
+				WriteJSON(w, http.StatusUnauthorized, map[string]string{
+					"error":   "Session revoked",
+					"message": "This session has been signed out. Please log in again",
+				})
+				return
+			}
+		}
+
+		if accountStatePool != nil {
+			if _, r, ok = requireAccount(w, r, accountStatePool, userID); !ok {
+				return
+			}
+		}
+
 		ctx := context.WithValue(r.Context(), ContextKey("userId"), userID)
+		if sessionID != "" {
+			ctx = context.WithValue(ctx, ContextKey("sessionId"), sessionID)
+		}
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// RoleInjectionMiddleWare puts the authenticated user's role in the request context.
+// Suspended and banned users are turned away here too, reusing the state AuthMiddleWare
+// loaded when EnableAccountStateCheck is on.
 func RoleInjectionMiddleWare(dbPool *pgxpool.Pool) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			ctx := r.Context()
-			userId, ok := ctx.Value(ContextKey("userId")).(string)
-			if ok && userId != "" {
-				role, err := clients.FetchUserRole(ctx, dbPool, userId)
-				if err == nil {
-					ctx = context.WithValue(ctx, ContextKey("userRole"), role)
-				} else {
-					// Proceed without role if not found; do not error out
-					logrus.WithError(err).WithField("userId", userId).Debug("role fetch failed; continuing without role")
-				}
+			userId, _ := r.Context().Value(ContextKey("userId")).(string)
+			if userId == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			state, r, ok := requireAccount(w, r, dbPool, userId)
+			if !ok {
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ContextKey("userRole"), state.Role)))
+		})
+	}
+}
+
+// VerifiedEmailMiddleWare turns away users who haven't verified their campus email yet,
+// along with suspended and banned users. It goes after AuthMiddleWare on routes that
+// publish content or reach other users (creating listings, chat); browsing stays open
+// to unverified users.
+func VerifiedEmailMiddleWare(dbPool *pgxpool.Pool) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userId, _ := r.Context().Value(ContextKey("userId")).(string)
+			state, r, ok := requireAccount(w, r, dbPool, userId)
+			if !ok {
+				return
+			}
+			if !state.EmailVerified {
+				WriteJSON(w, http.StatusForbidden, map[string]string{
+					"error":   "Email not verified",
+					"message": "Verify your campus email address to list items and chat",
+				})
+				return
 			}
-			next.ServeHTTP(w, r.WithContext(ctx))
+			next.ServeHTTP(w, r)
 		})
 	}
 }
@@ -215,21 +319,47 @@ func WriteJSON(w http.ResponseWriter, status int, data interface{}) {
 	}).Info("response sent")
 }
 
-// GenerateJWT generates a JWT access token for the user
-func GenerateJWT(userID string) (string, error) {
+const (
+	AccessTokenTTL  = time.Hour
+	RefreshTokenTTL = 7 * 24 * time.Hour
+)
+
+// GenerateJWT generates a JWT access token for the user's login session. emailVerified
+// (the ev claim) lets services that verify tokens locally gate on a verified email
+// without asking the orchestrator.
+func GenerateJWT(userID string, sessionID string, emailVerified bool) (string, error) {
 	// Create the Claims
 	claims := jwt.MapClaims{
 		"userId": userID,
-		"exp":    time.Now().Add(time.Hour * 1).Unix(), // Access token expires in 1 hour
+		"sid":    sessionID,
+		"ev":     emailVerified,
+		"exp":    time.Now().Add(AccessTokenTTL).Unix(), // Access token expires in 1 hour
 		"iat":    time.Now().Unix(),
 		"type":   "access",
 	}
 
+	// Sign with the active key set
+	return keySet().Sign(claims)
+}
+
+// GenerateRefreshToken generates a refresh token for the user's login session. tokenID
+// (the jti claim) identifies this token among the ones the session has rotated through.
+func GenerateRefreshToken(userID string, sessionID string, tokenID string) (string, error) {
+	// Create the Claims
+	claims := jwt.MapClaims{
+		"userId": userID,
+		"sid":    sessionID,
+		"jti":    tokenID,
+		"exp":    time.Now().Add(RefreshTokenTTL).Unix(), // Refresh token expires in 7 days
+		"iat":    time.Now().Unix(),
+		"type":   "refresh",
+	}
+
 	// Create token
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	// Sign token with secret
-	tokenString, err := token.SignedString([]byte(os.Getenv("JWT_TOKEN_SECRET")))
+	tokenString, err := token.SignedString([]byte(os.Getenv("JWT_REFRESH_SECRET")))
 	if err != nil {
 		return "", err
 	}
@@ -237,30 +367,47 @@ func GenerateJWT(userID string) (string, error) {
 	return tokenString, nil
 }
 
-// GenerateRefreshToken generates a refresh token for the user
-func GenerateRefreshToken(userID string) (string, error) {
-	// Create the Claims
+// GenerateEmailVerificationToken signs a token proving the holder received mail at email.
+// It expires after ttl and only verifies that address, so changing the email voids it.
+func GenerateEmailVerificationToken(userID, email string, ttl time.Duration) (string, error) {
 	claims := jwt.MapClaims{
 		"userId": userID,
-		"exp":    time.Now().Add(time.Hour * 24 * 7).Unix(), // Refresh token expires in 7 days
+		"email":  email,
+		"exp":    time.Now().Add(ttl).Unix(),
 		"iat":    time.Now().Unix(),
-		"type":   "refresh",
+		"type":   "email_verification",
 	}
 
-	// Create token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return keySet().Sign(claims)
+}
 
-	// Sign token with secret
-	tokenString, err := token.SignedString([]byte(os.Getenv("JWT_REFRESH_SECRET")))
+// ValidateEmailVerificationToken validates an email verification token and returns the
+// user ID and email address it was issued for
+func ValidateEmailVerificationToken(tokenString string) (string, string, error) {
+	claims, err := keySet().Parse(tokenString)
 	if err != nil {
-		return "", err
+		return "", "", err
+	}
+	if tokenType, _ := claims["type"].(string); tokenType != "email_verification" {
+		return "", "", fmt.Errorf("invalid token type")
+	}
+	userID, _ := claims["userId"].(string)
+	email, _ := claims["email"].(string)
+	if userID == "" || email == "" {
+		return "", "", fmt.Errorf("invalid claims")
 	}
+	return userID, email, nil
+}
 
-	return tokenString, nil
+// RefreshClaims identifies the user, session and token a refresh token was issued for
+type RefreshClaims struct {
+	UserID    string
+	SessionID string
+	TokenID   string
 }
 
-// ValidateRefreshToken validates a refresh token and returns the user ID
-func ValidateRefreshToken(tokenString string) (string, error) {
+// ValidateRefreshToken validates a refresh token and returns its claims
+func ValidateRefreshToken(tokenString string) (RefreshClaims, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method")
@@ -269,28 +416,34 @@ func ValidateRefreshToken(tokenString string) (string, error) {
 	})
 
 	if err != nil {
-		return "", err
+		return RefreshClaims{}, err
 	}
 
 	if !token.Valid {
-		return "", fmt.Errorf("invalid token")
+		return RefreshClaims{}, fmt.Errorf("invalid token")
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		return "", fmt.Errorf("invalid claims")
+		return RefreshClaims{}, fmt.Errorf("invalid claims")
 	}
 
 	// Check token type
 	tokenType, ok := claims["type"].(string)
 	if !ok || tokenType != "refresh" {
-		return "", fmt.Errorf("invalid token type")
+		return RefreshClaims{}, fmt.Errorf("invalid token type")
 	}
 
 	userID, ok := claims["userId"].(string)
 	if !ok {
-		return "", fmt.Errorf("user ID not found in token")
+		return RefreshClaims{}, fmt.Errorf("user ID not found in token")
+	}
+
+	sessionID, _ := claims["sid"].(string)
+	tokenID, _ := claims["jti"].(string)
+	if sessionID == "" || tokenID == "" {
+		return RefreshClaims{}, fmt.Errorf("session not found in token")
 	}
 
-	return userID, nil
+	return RefreshClaims{UserID: userID, SessionID: sessionID, TokenID: tokenID}, nil
 }
@@ -8,3 +8,11 @@ const (
 	ADMIN UserRole = "0" // admin
 	USER  UserRole = "1" // buyer, seller both are same roles
 )
+
+type AccountStatus string
+
+const (
+	ACTIVE    AccountStatus = "ACTIVE"
+	SUSPENDED AccountStatus = "SUSPENDED" // locked out until suspended_until, or until lifted
+	BANNED    AccountStatus = "BANNED"
+)
@@ -0,0 +1,176 @@
+package screening
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Rule is a regular expression screener
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+	Action  Action
+	Reason  string
+}
+
+func (r Rule) Screen(_ context.Context, text string) ([]Finding, error) {
+	loc := r.Pattern.FindStringIndex(text)
+	if loc == nil {
+		return nil, nil
+	}
+	return []Finding{{Rule: r.Name, Action: r.Action, Reason: r.Reason, Match: strings.TrimSpace(text[loc[0]:loc[1]])}}, nil
+}
+
+// TermsRule matches any of terms as whole words, ignoring case
+func TermsRule(name string, terms []string, action Action, reason string) (Rule, error) {
+	quoted := make([]string, 0, len(terms))
+	for _, t := range terms {
+		if t = strings.TrimSpace(t); t != "" {
+			quoted = append(quoted, regexp.QuoteMeta(t))
+		}
+	}
+	if len(quoted) == 0 {
+		return Rule{}, fmt.Errorf("rule %q has no terms", name)
+	}
+	re, err := regexp.Compile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)
+	if err != nil {
+		return Rule{}, fmt.Errorf("rule %q: %w", name, err)
+	}
+	return Rule{Name: name, Pattern: re, Action: action, Reason: reason}, nil
+}
+
+// North American phone numbers, with or without a +1 prefix and common separators.
+// A number glued to a preceding digit group (an ISBN, a part number) doesn't count.
+var phonePattern = regexp.MustCompile(`(?:^|[^\w-])(?:\+?1[\s.-]?)?(?:\(\d{3}\)|\d{3})[\s.-]?\d{3}[\s.-]?\d{4}\b`)
+
+// Payment-app handles: paypal.me links, $cashtags, and @handles next to an app name.
+// Prices like "$40" don't match because a cashtag has to start with a letter.
+var paymentHandlePattern = regexp.MustCompile(`(?i)\bpaypal\.me/\w+` +
+	`|(?:^|[^\w$])\$[a-z][\w-]{2,19}\b` +
+	`|\b(?:venmo|cash\s?app|zelle|paypal)\W{0,3}@[\w.-]{2,}` +
+	`|@[\w.-]{2,}\s+(?:on|via)\s+(?:venmo|cash\s?app|zelle|paypal)\b`)
+
+// PhoneNumberRule matches phone numbers, which scammers use to move buyers off the platform
+func PhoneNumberRule(action Action) Rule {
+	return Rule{Name: "phone_number", Pattern: phonePattern, Action: action, Reason: "contains a phone number"}
+}
+
+// PaymentHandleRule matches payment-app handles used to take payment off the platform
+func PaymentHandleRule(action Action) Rule {
+	return Rule{Name: "payment_handle", Pattern: paymentHandlePattern, Action: action, Reason: "contains a payment app handle"}
+}
+
+// RuleConfig is one entry of a rules file. A rule has either Terms or a Pattern.
+type RuleConfig struct {
+	Name    string   `json:"name"`
+	Terms   []string `json:"terms,omitempty"`
+	Pattern string   `json:"pattern,omitempty"`
+	Action  string   `json:"action"`
+	Reason  string   `json:"reason,omitempty"`
+}
+
+// LoadRules reads a JSON array of RuleConfig from path
+func LoadRules(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules file: %w", err)
+	}
+	var configs []RuleConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("failed to parse rules file: %w", err)
+	}
+
+	rules := make([]Rule, 0, len(configs))
+	for _, c := range configs {
+		action, ok := ParseAction(c.Action)
+		if !ok {
+			return nil, fmt.Errorf("rule %q: invalid action %q", c.Name, c.Action)
+		}
+		reason := c.Reason
+		if reason == "" {
+			reason = fmt.Sprintf("matches the %s rule", c.Name)
+		}
+
+		switch {
+		case len(c.Terms) > 0:
+			r, err := TermsRule(c.Name, c.Terms, action, reason)
+			if err != nil {
+				return nil, err
+			}
+			rules = append(rules, r)
+		case c.Pattern != "":
+			re, err := regexp.Compile(c.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("rule %q: %w", c.Name, err)
+			}
+			rules = append(rules, Rule{Name: c.Name, Pattern: re, Action: action, Reason: reason})
+		default:
+			return nil, fmt.Errorf("rule %q needs terms or a pattern", c.Name)
+		}
+	}
+	return rules, nil
+}
+
+// FromEnv builds the local rule pipeline:
+//   - SCREEN_RULES_FILE: optional JSON rules file (see RuleConfig)
+//   - SCREEN_BANNED_TERMS: comma-separated terms that are always blocked
+//   - SCREEN_PHONE_ACTION: action for phone numbers, default warn
+//   - SCREEN_PAYMENT_ACTION: action for payment-app handles, default flag
+//
+// Either action can be set to "off".
+func FromEnv() (Pipeline, error) {
+	var p Pipeline
+
+	if path := os.Getenv("SCREEN_RULES_FILE"); path != "" {
+		rules, err := LoadRules(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rules {
+			p = append(p, r)
+		}
+	}
+	if terms := os.Getenv("SCREEN_BANNED_TERMS"); strings.TrimSpace(terms) != "" {
+		r, err := TermsRule("banned_terms", strings.Split(terms, ","), ActionBlock, "contains a banned term")
+		if err != nil {
+			return nil, err
+		}
+		p = append(p, r)
+	}
+
+	phone, err := EnvAction("SCREEN_PHONE_ACTION", ActionWarn)
+	if err != nil {
+		return nil, err
+	}
+	if phone != "" {
+		p = append(p, PhoneNumberRule(phone))
+	}
+	payment, err := EnvAction("SCREEN_PAYMENT_ACTION", ActionFlag)
+	if err != nil {
+		return nil, err
+	}
+	if payment != "" {
+		p = append(p, PaymentHandleRule(payment))
+	}
+	return p, nil
+}
+
+// EnvAction reads an action from key, returning def when it is unset and "" when it is "off"
+func EnvAction(key string, def Action) (Action, error) {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return def, nil
+	}
+	if strings.EqualFold(v, "off") {
+		return "", nil
+	}
+	action, ok := ParseAction(v)
+	if !ok {
+		return "", fmt.Errorf("%s: invalid action %q", key, v)
+	}
+	return action, nil
+}
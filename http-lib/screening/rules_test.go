@@ -0,0 +1,101 @@
+package screening
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPhoneNumberRule(t *testing.T) {
+	rule := PhoneNumberRule(ActionWarn)
+
+	tests := []struct {
+		name  string
+		text  string
+		match bool
+	}{
+		{"plain", "call me at 4085551234", true},
+		{"dashes", "text 408-555-1234 after 5", true},
+		{"dots", "408.555.1234", true},
+		{"parens", "(408) 555-1234", true},
+		{"country code", "+1 408 555 1234", true},
+		{"start of text", "408-555-1234 is my cell", true},
+		{"isbn-13", "ISBN 9780134685991, barely used", false},
+		{"hyphenated isbn", "ISBN 978-0-13-468599-1", false},
+		{"part number", "part 12-408-555-1234", false},
+		{"price", "asking $40, firm", false},
+		{"short number", "room 555-1234", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings, err := rule.Screen(context.Background(), tt.text)
+			if err != nil {
+				t.Fatalf("Screen: %v", err)
+			}
+			if got := len(findings) > 0; got != tt.match {
+				t.Errorf("Screen(%q) matched = %v, want %v (%+v)", tt.text, got, tt.match, findings)
+			}
+		})
+	}
+}
+
+func TestPaymentHandleRule(t *testing.T) {
+	rule := PaymentHandleRule(ActionFlag)
+
+	tests := []struct {
+		name  string
+		text  string
+		match bool
+	}{
+		{"paypal.me link", "pay at paypal.me/jdoe", true},
+		{"cashtag", "send it to $jdoe99", true},
+		{"cashtag at start", "$sparty for the deposit", true},
+		{"venmo handle", "venmo @jane-doe", true},
+		{"cash app handle", "Cash App: @jdoe", true},
+		{"handle on app", "@jdoe on zelle please", true},
+		{"price", "asking $40", false},
+		{"price with cents", "only $12.50 obo", false},
+		{"price range", "$40-$60 depending on condition", false},
+		{"bare handle", "follow @jdoe for more", false},
+		{"app without handle", "I accept venmo", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings, err := rule.Screen(context.Background(), tt.text)
+			if err != nil {
+				t.Fatalf("Screen: %v", err)
+			}
+			if got := len(findings) > 0; got != tt.match {
+				t.Errorf("Screen(%q) matched = %v, want %v (%+v)", tt.text, got, tt.match, findings)
+			}
+		})
+	}
+}
+
+func TestTermsRule(t *testing.T) {
+	rule, err := TermsRule("banned", []string{"counterfeit", " replica ", ""}, ActionBlock, "banned")
+	if err != nil {
+		t.Fatalf("TermsRule: %v", err)
+	}
+
+	tests := []struct {
+		text  string
+		match bool
+	}{
+		{"Counterfeit watch", true},
+		{"a REPLICA bag", true},
+		{"replicated results", false},
+		{"genuine leather", false},
+	}
+	for _, tt := range tests {
+		findings, _ := rule.Screen(context.Background(), tt.text)
+		if got := len(findings) > 0; got != tt.match {
+			t.Errorf("Screen(%q) matched = %v, want %v", tt.text, got, tt.match)
+		}
+	}
+
+	if _, err := TermsRule("empty", []string{" ", ""}, ActionBlock, "x"); err == nil {
+		t.Error("TermsRule with no terms: want error")
+	}
+}
@@ -0,0 +1,96 @@
+// Package screening checks user-written text (listings, chat messages) against
+// content rules before it is stored. Each rule decides what happens on a match:
+// block the write, warn the author/reader, or flag the content for moderators.
+package screening
+
+import (
+	"context"
+	"log"
+	"strings"
+)
+
+// Action is what a rule asks the caller to do with matching content
+type Action string
+
+const (
+	ActionBlock Action = "block" // reject the write
+	ActionWarn  Action = "warn"  // accept it and surface a warning
+	ActionFlag  Action = "flag"  // accept it and queue it for moderation
+)
+
+// ParseAction maps a configured action name to an Action
+func ParseAction(s string) (Action, bool) {
+	switch a := Action(strings.ToLower(strings.TrimSpace(s))); a {
+	case ActionBlock, ActionWarn, ActionFlag:
+		return a, true
+	}
+	return "", false
+}
+
+// Finding is a single rule match
+type Finding struct {
+	Rule   string `json:"rule"`
+	Action Action `json:"action"`
+	Reason string `json:"reason"`
+	Match  string `json:"match,omitempty"`
+}
+
+// Screener inspects text and reports the rules it breaks
+type Screener interface {
+	Screen(ctx context.Context, text string) ([]Finding, error)
+}
+
+// Result is everything a Pipeline found in a piece of text
+type Result struct {
+	Findings []Finding `json:"findings"`
+}
+
+// With returns the findings whose rule asked for action a
+func (r Result) With(a Action) []Finding {
+	var out []Finding
+	for _, f := range r.Findings {
+		if f.Action == a {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// Blocked reports whether any rule blocks the text
+func (r Result) Blocked() bool { return len(r.With(ActionBlock)) > 0 }
+
+// Flagged reports whether any rule wants the text reviewed by a moderator
+func (r Result) Flagged() bool { return len(r.With(ActionFlag)) > 0 }
+
+// Reasons joins the reasons of the given findings for use in messages and flag details
+func Reasons(findings []Finding) string {
+	reasons := make([]string, 0, len(findings))
+	for _, f := range findings {
+		reasons = append(reasons, f.Reason)
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// Pipeline runs screeners in order. A screener that errors is logged and skipped so
+// an unavailable classifier never blocks writes, and screening stops at the first
+// block since nothing later can change the outcome.
+type Pipeline []Screener
+
+func (p Pipeline) Screen(ctx context.Context, text string) Result {
+	var res Result
+	if strings.TrimSpace(text) == "" {
+		return res
+	}
+	for _, s := range p {
+		findings, err := s.Screen(ctx, text)
+		if err != nil {
+			log.Printf("content screener failed: %v", err)
+			continue
+		}
+		res.Findings = append(res.Findings, findings...)
+		if res.Blocked() {
+			break
+		}
+	}
+	return res
+}
@@ -6,6 +6,9 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -13,6 +16,7 @@ import (
 	"github.com/kunal768/cmpe202/listing-service/internal/blob"
 	"github.com/kunal768/cmpe202/listing-service/internal/gemini"
 	"github.com/kunal768/cmpe202/listing-service/internal/listing"
+	"github.com/kunal768/cmpe202/listing-service/internal/notify"
 	"github.com/kunal768/cmpe202/listing-service/internal/platform"
 )
 
@@ -26,29 +30,87 @@ func main() {
 	// Repo Database Interface Layer being passed to the handler layer
 	store := &listing.Store{P: pool}
 
+	// Seller notifications (expiry, suspensions) are published to the orchestrator's
+	// notifications queue, which owns the inbox
+	rabbitURL := os.Getenv("RABBITMQ_URL")
+	notificationsQueue := os.Getenv("RABBITMQ_NOTIFICATIONS_QUEUE")
+	if rabbitURL != "" && notificationsQueue != "" {
+		publisher, err := notify.NewRabbitMQPublisher(rabbitURL, notificationsQueue)
+		if err != nil {
+			log.Fatalf("Failed to initialize notification publisher: %v", err)
+		}
+		defer func() {
+			if err := publisher.Close(); err != nil {
+				log.Printf("Error closing notification publisher: %v", err)
+			}
+		}()
+		store.Notifier = publisher
+	} else {
+		log.Println("RABBITMQ_URL or RABBITMQ_NOTIFICATIONS_QUEUE not set; seller notifications are disabled")
+	}
+
 	// --- Gemini AI Client ---
 	aiClient := gemini.NewClient()
-	blobClient, err := blob.GetServiceClientTokenCredential(os.Getenv("AZURE_ACCOUNT_URL"))
-	blobService := blob.NewBlobService(
-		blobClient,
-		blob.AzureBlobCredentials{
-			AccountName:   blob.CREDENTIAL(os.Getenv("AZURE_ACCOUNTNAME")),
-			AccountKey:    blob.CREDENTIAL(os.Getenv("AZURE_ACCOUNTKEY")),
-			ContainerName: blob.CREDENTIAL(os.Getenv("AZURE_CONTAINERNAME")),
-		},
-	)
+
+	// Uploads go to Azure, or to local disk when BLOB_LOCAL_DIR is set (development)
+	var blobService blob.BlobService
+	var localBlobs *blob.LocalBlobService
+	if dir := os.Getenv("BLOB_LOCAL_DIR"); dir != "" {
+		baseURL := getenv("BLOB_LOCAL_BASE_URL", "http://localhost:"+getenv("LISTING_PORT", "8080")+"/blobs")
+		local, err := blob.NewLocalBlobService(dir, baseURL)
+		if err != nil {
+			log.Fatalf("Failed to set up local blob store: %v", err)
+		}
+		log.Printf("Storing blobs in %s, served from %s", dir, baseURL)
+		blobService, localBlobs = local, local
+	} else {
+		blobClient, err := blob.GetServiceClientTokenCredential(os.Getenv("AZURE_ACCOUNT_URL"))
+		if err != nil {
+			fmt.Println("error : ", err.Error())
+			panic(err)
+		}
+		blobService = blob.NewBlobService(
+			blobClient,
+			blob.AzureBlobCredentials{
+				AccountName:   blob.CREDENTIAL(os.Getenv("AZURE_ACCOUNTNAME")),
+				AccountKey:    blob.CREDENTIAL(os.Getenv("AZURE_ACCOUNTKEY")),
+				ContainerName: blob.CREDENTIAL(os.Getenv("AZURE_CONTAINERNAME")),
+			},
+		)
+	}
+
+	screener, err := listing.ScreenerFromEnv(aiClient)
 	if err != nil {
-		fmt.Println("error : ", err.Error())
-		panic(err)
+		log.Fatalf("Invalid content screening config: %v", err)
 	}
 
-	handlers := &listing.Handlers{S: store, AI: aiClient, BlobSvc: blobService}
+	expiryConfig := listing.ExpiryConfigFromEnv()
+	handlers := &listing.Handlers{
+		S:              store,
+		AI:             aiClient,
+		BlobSvc:        blobService,
+		Expiry:         expiryConfig,
+		ClaimTimeout:   listing.ClaimTimeoutFromEnv(),
+		AutoModeration: listing.AutoModerationConfigFromEnv(),
+		Screener:       screener,
+	}
+
+	// Background worker that warns sellers about and archives stale listings.
+	// Safe to run on every replica: sweeps are serialized by a Postgres advisory lock.
+	workerCtx, stopWorker := context.WithCancel(ctx)
+	defer stopWorker()
+	expiryWorker := &listing.ExpiryWorker{S: store, Config: expiryConfig}
+	go expiryWorker.Run(workerCtx)
 
 	r := chi.NewRouter()
 	r.Use(middleware.Logger) // <-- built-in logger
 
 	orchestratorRequestID := os.Getenv("ORCH_REQUEST_ID")
 	r.Mount("/listings", listing.Routes(handlers, orchestratorRequestID))
+	if localBlobs != nil {
+		// Clients upload and download directly, like they do with Azure
+		r.Mount("/blobs", localBlobs.Routes())
+	}
 
 	log.Println("listening on", getenv("LISTING_PORT", "8080"))
 
@@ -58,10 +120,24 @@ func main() {
 		Handler: r,
 	}
 
-	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		panic(err)
-	}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			panic(err)
+		}
+	}()
 
+	// Stop the expiry worker and drain requests on shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	<-sigChan
+	log.Println("Shutdown signal received, stopping...")
+	stopWorker()
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer shutdownCancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Error shutting down server: %v", err)
+	}
 }
 
 func getenv(k, d string) string {
@@ -12,6 +12,7 @@ require (
 	github.com/jackc/pgx/v5 v5.7.6
 	github.com/joho/godotenv v1.5.1
 	github.com/kunal768/cmpe202/http-lib v0.0.0
+	github.com/rabbitmq/amqp091-go v1.10.0
 )
 
 require (
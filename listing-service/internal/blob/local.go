@@ -0,0 +1,155 @@
+package blob
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// maxLocalBlobSize caps a single upload to the local store
+const maxLocalBlobSize = 20 << 20
+
+// LocalBlobService keeps blobs on disk for development, where there is no Azure account.
+// It hands out signed upload URLs the same way GenerateUploadSAS does, so clients PUT to
+// the URL they get back without knowing which store is behind it.
+type LocalBlobService struct {
+	dir     string
+	baseURL string // where Routes is mounted, as clients reach it
+	key     []byte // signs upload URLs; random, so they don't survive a restart
+}
+
+// NewLocalBlobService stores blobs under dir and serves them from baseURL
+func NewLocalBlobService(dir, baseURL string) (*LocalBlobService, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create blob directory: %w", err)
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	return &LocalBlobService{dir: dir, baseURL: strings.TrimRight(baseURL, "/"), key: key}, nil
+}
+
+func (l *LocalBlobService) GenerateUploadSAS(ctx context.Context, blobName string) (UploadSASResponse, error) {
+	if _, err := l.path(blobName); err != nil {
+		return UploadSASResponse{}, err
+	}
+	expiry := time.Now().UTC().Add(12 * time.Hour).Unix()
+	blobURL := l.baseURL + "/" + blobName
+
+	q := url.Values{}
+	q.Set("se", strconv.FormatInt(expiry, 10))
+	q.Set("sig", l.sign(blobName, expiry))
+
+	return UploadSASResponse{
+		SASURL:             blobURL + "?" + q.Encode(),
+		PermanentPublicURL: blobURL,
+		BlobName:           blobName,
+	}, nil
+}
+
+// Routes serves uploads (PUT with a signed URL) and downloads (GET) of local blobs
+func (l *LocalBlobService) Routes() *chi.Mux {
+	r := chi.NewRouter()
+	r.Put("/*", l.putHandler)
+	r.Get("/*", l.getHandler)
+	return r
+}
+
+func (l *LocalBlobService) putHandler(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "*")
+	p, err := l.path(name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	expiry, err := strconv.ParseInt(r.URL.Query().Get("se"), 10, 64)
+	if err != nil || time.Now().Unix() > expiry ||
+		!hmac.Equal([]byte(r.URL.Query().Get("sig")), []byte(l.sign(name, expiry))) {
+		http.Error(w, "invalid or expired upload URL", http.StatusForbidden)
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		http.Error(w, "failed to store blob", http.StatusInternalServerError)
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		http.Error(w, "failed to store blob", http.StatusInternalServerError)
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, http.MaxBytesReader(w, r.Body, maxLocalBlobSize))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "blob too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "failed to store blob", http.StatusInternalServerError)
+		return
+	}
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		http.Error(w, "failed to store blob", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (l *LocalBlobService) getHandler(w http.ResponseWriter, r *http.Request) {
+	p, err := l.path(chi.URLParam(r, "*"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	// Blobs are user uploads: never let the browser treat one as a page
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'")
+	http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+}
+
+// path maps a blob name to its file, refusing names that would leave the store
+func (l *LocalBlobService) path(name string) (string, error) {
+	if name == "" || strings.HasPrefix(name, "/") || path.Clean(name) != name || strings.HasPrefix(name, "../") || name == ".." {
+		return "", fmt.Errorf("invalid blob name %q", name)
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(name)), nil
+}
+
+func (l *LocalBlobService) sign(name string, expiry int64) string {
+	mac := hmac.New(sha256.New, l.key)
+	fmt.Fprintf(mac, "%s\n%d", name, expiry)
+	return hex.EncodeToString(mac.Sum(nil))
+}
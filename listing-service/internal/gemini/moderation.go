@@ -0,0 +1,79 @@
+package gemini
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/kunal768/cmpe202/listing-service/internal/models"
+)
+
+// ClassifyContent asks Gemini whether text written by a user breaks the marketplace rules
+func (c *Client) ClassifyContent(ctx context.Context, text string) (*models.ContentClassification, error) {
+	if c.apiKey == "" {
+		return nil, fmt.Errorf("GOOGLE_API_KEY environment variable not set")
+	}
+
+	apiReq := models.GeminiRequest{
+		Contents: []models.Content{{
+			Parts: []models.Part{{Text: fmt.Sprintf("%s\n\nText:\n%q", getModerationPrompt(), text)}},
+		}},
+		GenerationConfig: models.GenerationConfig{ResponseMimeType: "application/json"},
+	}
+	reqBody, err := json.Marshal(apiReq)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling gemini request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", geminiAPIEndpoint, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("error creating gemini request: %w", err)
+	}
+	req.Header.Set("x-goog-api-key", c.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request to gemini: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("gemini API returned non-200 status: %s", resp.Status)
+	}
+
+	var apiResp models.GeminiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, fmt.Errorf("error decoding gemini response: %w", err)
+	}
+	if len(apiResp.Candidates) == 0 || len(apiResp.Candidates[0].Content.Parts) == 0 {
+		return nil, fmt.Errorf("no response content from gemini")
+	}
+
+	content := strings.TrimSpace(apiResp.Candidates[0].Content.Parts[0].Text)
+	content = strings.TrimPrefix(content, "```json")
+	content = strings.TrimPrefix(content, "```")
+	content = strings.TrimSuffix(content, "```")
+
+	var verdict models.ContentClassification
+	if err := json.Unmarshal([]byte(strings.TrimSpace(content)), &verdict); err != nil {
+		return nil, fmt.Errorf("error unmarshalling content classification: %w", err)
+	}
+	return &verdict, nil
+}
+
+func getModerationPrompt() string {
+	return `You review text that users post on a campus marketplace where students buy and sell items.
+Decide whether the text breaks the marketplace rules. It does if it:
+- tries to scam buyers, e.g. asks for payment up front, gift cards, wire transfers, or moving the deal off the platform
+- offers prohibited items such as weapons, drugs, alcohol, counterfeit goods, or academic work for hire
+- harasses, threatens, or uses hate speech against someone
+
+Ordinary listings and messages about prices, meeting up, or item condition are fine.
+
+Respond ONLY with a JSON object of the form:
+{"violation": true|false, "category": "scam"|"prohibited_item"|"harassment"|"none", "reason": "one short sentence"}`
+}
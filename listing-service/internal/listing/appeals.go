@@ -0,0 +1,230 @@
+package listing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/kunal768/cmpe202/listing-service/internal/models"
+)
+
+const appealColumns = `a.id, a.flag_id, a.listing_id, a.seller_user_id, a.justification, a.status, a.reviewer_user_id,
+	a.decision_notes, a.created_at, a.updated_at, a.decided_at`
+
+func scanAppeal(row pgx.Row) (models.ListingAppeal, error) {
+	var a models.ListingAppeal
+	err := row.Scan(&a.ID, &a.FlagID, &a.ListingID, &a.SellerUserID, &a.Justification, &a.Status, &a.ReviewerUserID,
+		&a.DecisionNotes, &a.CreatedAt, &a.UpdatedAt, &a.DecidedAt)
+	return a, err
+}
+
+// GetModerationOutcomes returns the closed flags on a seller's listings, newest first,
+// with the admin's notes and any appeal
+func (s *Store) GetModerationOutcomes(ctx context.Context, sellerID string) ([]models.ModerationOutcome, error) {
+	rows, err := s.P.Query(ctx, `
+		SELECT fl.id, fl.listing_id, l.title, l.status, fl.reason, fl.status, fl.resolution_notes, fl.resolved_at, to_jsonb(a)
+		FROM flagged_listings fl
+		JOIN listings l ON l.id = fl.listing_id
+		LEFT JOIN listing_appeals a ON a.flag_id = fl.id
+		WHERE l.user_id=$1 AND fl.status IN ('RESOLVED', 'DISMISSED')
+		ORDER BY fl.resolved_at DESC NULLS LAST, fl.id DESC
+	`, sellerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch moderation outcomes: %w", err)
+	}
+	defer rows.Close()
+
+	out := []models.ModerationOutcome{}
+	for rows.Next() {
+		var o models.ModerationOutcome
+		if err := rows.Scan(&o.FlagID, &o.ListingID, &o.ListingTitle, &o.ListingStatus, &o.Reason, &o.Status, &o.ResolutionNotes, &o.ResolvedAt,
+			&o.Appeal); err != nil {
+			return nil, fmt.Errorf("failed to scan moderation outcome: %w", err)
+		}
+		o.Appealable = o.Status == models.FlagStatusResolved && o.Appeal == nil
+		out = append(out, o)
+	}
+	return out, rows.Err()
+}
+
+// CreateAppeal files the seller's appeal against a RESOLVED flag on their listing
+func (s *Store) CreateAppeal(ctx context.Context, flagID int64, sellerID string, p models.CreateAppealParams) (models.ListingAppeal, error) {
+	tx, err := s.P.Begin(ctx)
+	if err != nil {
+		return models.ListingAppeal{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var listingID int64
+	var owner string
+	var status models.FlagStatus
+	var appealed bool
+	err = tx.QueryRow(ctx, `
+		SELECT fl.listing_id, l.user_id::text, fl.status, fl.appeal_status IS NOT NULL
+		FROM flagged_listings fl
+		JOIN listings l ON l.id = fl.listing_id
+		WHERE fl.id=$1
+		FOR UPDATE OF fl
+	`, flagID).Scan(&listingID, &owner, &status, &appealed)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return models.ListingAppeal{}, fmt.Errorf("flag not found")
+		}
+		return models.ListingAppeal{}, fmt.Errorf("failed to fetch flag: %w", err)
+	}
+	if owner != sellerID {
+		return models.ListingAppeal{}, fmt.Errorf("listing does not belong to user")
+	}
+	if status != models.FlagStatusResolved {
+		return models.ListingAppeal{}, fmt.Errorf("only resolved decisions can be appealed")
+	}
+	if appealed {
+		return models.ListingAppeal{}, fmt.Errorf("decision has already been appealed")
+	}
+
+	a, err := scanAppeal(tx.QueryRow(ctx, `
+		INSERT INTO listing_appeals AS a (flag_id, listing_id, seller_user_id, justification)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+appealColumns, flagID, listingID, sellerID, p.Justification))
+	if err != nil {
+		return models.ListingAppeal{}, fmt.Errorf("failed to create appeal: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE flagged_listings SET appeal_status='PENDING', updated_at=now() WHERE id=$1`, flagID); err != nil {
+		return models.ListingAppeal{}, fmt.Errorf("failed to mark flag appealed: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.ListingAppeal{}, fmt.Errorf("failed to commit appeal: %w", err)
+	}
+	return a, nil
+}
+
+// ListAppeals returns the appeals queue, oldest first, optionally filtered by status
+func (s *Store) ListAppeals(ctx context.Context, status *models.AppealStatus) ([]models.ListingAppeal, error) {
+	rows, err := s.P.Query(ctx, `
+		SELECT `+appealColumns+`
+		FROM listing_appeals a
+		WHERE ($1::appeal_status IS NULL OR a.status = $1)
+		ORDER BY a.created_at, a.id
+	`, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch appeals: %w", err)
+	}
+	defer rows.Close()
+
+	out := []models.ListingAppeal{}
+	for rows.Next() {
+		a, err := scanAppeal(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan appeal: %w", err)
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// GetAppeal returns an appeal with the flag and listing it concerns
+func (s *Store) GetAppeal(ctx context.Context, appealID int64) (models.ListingAppeal, error) {
+	a, err := scanAppeal(s.P.QueryRow(ctx, `SELECT `+appealColumns+` FROM listing_appeals a WHERE a.id=$1`, appealID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return a, fmt.Errorf("appeal not found")
+		}
+		return a, fmt.Errorf("failed to fetch appeal: %w", err)
+	}
+
+	var fl models.FlaggedListing
+	err = s.P.QueryRow(ctx, `
+		SELECT fl.id, fl.listing_id, fl.reporter_user_id, fl.reason, fl.details, fl.status, fl.reviewer_user_id,
+		       fl.resolution_notes, fl.created_at, fl.updated_at, fl.resolved_at,
+		       (SELECT to_jsonb(lr) FROM listing_revisions lr WHERE lr.id = fl.listing_revision_id), fl.appeal_status
+		FROM flagged_listings fl
+		WHERE fl.id=$1
+	`, a.FlagID).Scan(&fl.FlagID, &fl.ListingID, &fl.ReporterUserID, &fl.Reason, &fl.Details, &fl.Status, &fl.ReviewerUserID,
+		&fl.ResolutionNotes, &fl.FlagCreatedAt, &fl.FlagUpdatedAt, &fl.FlagResolvedAt, &fl.ListingRevision, &fl.AppealStatus)
+	if err != nil {
+		return a, fmt.Errorf("failed to fetch appealed flag: %w", err)
+	}
+	fl.Listing, err = s.Get(ctx, a.ListingID)
+	if err != nil {
+		return a, fmt.Errorf("failed to fetch appealed listing: %w", err)
+	}
+	a.Flag = &fl
+	return a, nil
+}
+
+// DecideAppeal closes a PENDING appeal and records the outcome on the appealed flag.
+// The admin who made the original decision can't decide its appeal. Overturning puts
+// an ARCHIVED or REPORTED listing back to AVAILABLE for a fresh ttl, unless another
+// flag on it is still open or upheld (RESOLVED without an overturned appeal).
+func (s *Store) DecideAppeal(ctx context.Context, appealID int64, adminID string, p models.DecideAppealParams, ttl time.Duration) (models.ListingAppeal, error) {
+	tx, err := s.P.Begin(ctx)
+	if err != nil {
+		return models.ListingAppeal{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var status models.AppealStatus
+	var flagID, listingID int64
+	var originalReviewer *string
+	err = tx.QueryRow(ctx, `
+		SELECT a.status, a.flag_id, a.listing_id, fl.reviewer_user_id::text
+		FROM listing_appeals a
+		JOIN flagged_listings fl ON fl.id = a.flag_id
+		WHERE a.id=$1
+		FOR UPDATE OF a
+	`, appealID).Scan(&status, &flagID, &listingID, &originalReviewer)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return models.ListingAppeal{}, fmt.Errorf("appeal not found")
+		}
+		return models.ListingAppeal{}, fmt.Errorf("failed to fetch appeal: %w", err)
+	}
+	if status != models.AppealPending {
+		return models.ListingAppeal{}, fmt.Errorf("appeal is already decided")
+	}
+	if originalReviewer != nil && *originalReviewer == adminID {
+		return models.ListingAppeal{}, fmt.Errorf("appeal must be decided by another admin")
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE listing_appeals
+		SET status=$2, reviewer_user_id=$3, decision_notes=$4, decided_at=now(), updated_at=now()
+		WHERE id=$1
+	`, appealID, p.Status, adminID, p.DecisionNotes); err != nil {
+		return models.ListingAppeal{}, fmt.Errorf("failed to decide appeal: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE flagged_listings SET appeal_status=$2, appeal_decided_at=now(), updated_at=now() WHERE id=$1
+	`, flagID, p.Status); err != nil {
+		return models.ListingAppeal{}, fmt.Errorf("failed to record appeal outcome: %w", err)
+	}
+
+	if p.Status == models.AppealOverturned {
+		if err := setRevisionActor(ctx, tx, adminID); err != nil {
+			return models.ListingAppeal{}, err
+		}
+		// The listing gets a full ttl from now, as if renewed. The appealed flag is already
+		// marked OVERTURNED above, so it doesn't hold the listing back.
+		if _, err := tx.Exec(ctx, `
+			UPDATE listings l
+			SET status='AVAILABLE', expires_at=now() + $2 * INTERVAL '1 second', expiry_warned_at=NULL, expired_at=NULL, updated_at=now()
+			WHERE l.id=$1 AND l.status IN ('ARCHIVED', 'REPORTED')
+			  AND NOT EXISTS (
+				SELECT 1 FROM flagged_listings f
+				WHERE f.listing_id = l.id
+				  AND (f.status IN ('OPEN', 'UNDER_REVIEW')
+				       OR (f.status = 'RESOLVED' AND f.appeal_status IS DISTINCT FROM 'OVERTURNED'))
+			  )
+		`, listingID, int64(ttl.Seconds())); err != nil {
+			return models.ListingAppeal{}, fmt.Errorf("failed to reinstate listing: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.ListingAppeal{}, fmt.Errorf("failed to commit appeal decision: %w", err)
+	}
+	return s.GetAppeal(ctx, appealID)
+}
@@ -0,0 +1,171 @@
+package listing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	httplib "github.com/kunal768/cmpe202/http-lib"
+	"github.com/kunal768/cmpe202/listing-service/internal/models"
+	"github.com/kunal768/cmpe202/listing-service/internal/notify"
+)
+
+// AutoModerationConfig holds the rules that move a flagged listing to REPORTED
+// without waiting for an admin. A listing is suspended when either rule matches.
+type AutoModerationConfig struct {
+	ReporterThreshold int                 // distinct reporters with open flags within Window
+	Window            time.Duration       // how far back flags count towards ReporterThreshold
+	TrustedReasons    []models.FlagReason // reasons that suspend immediately when a trusted reporter uses them
+	TrustedMinUpheld  int                 // upheld (RESOLVED) flags a reporter needs to be trusted
+}
+
+// AutoModerationConfigFromEnv reads AUTOMOD_REPORTER_THRESHOLD, AUTOMOD_WINDOW_HOURS,
+// AUTOMOD_TRUSTED_REASONS and AUTOMOD_TRUSTED_MIN_UPHELD, defaulting to 3 reporters
+// within 24 hours, SCAM, and 3 upheld flags. AUTOMOD_TRUSTED_REASONS=none turns the
+// trusted reporter rule off.
+func AutoModerationConfigFromEnv() AutoModerationConfig {
+	cfg := AutoModerationConfig{
+		ReporterThreshold: envInt("AUTOMOD_REPORTER_THRESHOLD", 3),
+		Window:            time.Duration(envInt("AUTOMOD_WINDOW_HOURS", 24)) * time.Hour,
+		TrustedReasons:    []models.FlagReason{models.FlagReasonScam},
+		TrustedMinUpheld:  envInt("AUTOMOD_TRUSTED_MIN_UPHELD", 3),
+	}
+	if v, ok := os.LookupEnv("AUTOMOD_TRUSTED_REASONS"); ok {
+		cfg.TrustedReasons = nil
+		for _, reason := range strings.Split(v, ",") {
+			reason = strings.ToUpper(strings.TrimSpace(reason))
+			if reason != "" && reason != "NONE" {
+				cfg.TrustedReasons = append(cfg.TrustedReasons, models.FlagReason(reason))
+			}
+		}
+	}
+	return cfg
+}
+
+// ApplyAutoModeration checks the listing behind a new flag against cfg and moves it
+// from AVAILABLE to REPORTED when a rule matches, telling the seller through the
+// orchestrator's notifications inbox. It reports whether the listing was suspended.
+func (s *Store) ApplyAutoModeration(ctx context.Context, fl models.FlaggedListing, cfg AutoModerationConfig) (bool, error) {
+	tx, err := s.P.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the listing so concurrent flags don't both suspend it
+	var status models.Status
+	if err := tx.QueryRow(ctx, `SELECT status FROM listings WHERE id=$1 FOR UPDATE`, fl.ListingID).Scan(&status); err != nil {
+		if err == pgx.ErrNoRows {
+			return false, fmt.Errorf("listing not found")
+		}
+		return false, fmt.Errorf("failed to lock listing: %w", err)
+	}
+	if status != models.StAvailable {
+		return false, nil
+	}
+
+	reason, err := matchAutoModerationRule(ctx, tx, fl, cfg)
+	if err != nil {
+		return false, err
+	}
+	if reason == "" {
+		return false, nil
+	}
+
+	// Suspensions are system changes, so the revision is recorded without an actor
+	var sellerID, title string
+	err = tx.QueryRow(ctx, `
+		UPDATE listings SET status='REPORTED', updated_at=now() WHERE id=$1
+		RETURNING user_id::text, title
+	`, fl.ListingID).Scan(&sellerID, &title)
+	if err != nil {
+		return false, fmt.Errorf("failed to suspend listing: %w", err)
+	}
+	notification, err := sellerNotification(sellerID, notificationListingStatus, "Your listing was hidden pending review", title+": "+reason,
+		map[string]any{"listing_id": fl.ListingID, "status": models.StReported, "flag_id": fl.FlagID},
+		fmt.Sprintf("listing_suspended:listing:%d:flag:%d", fl.ListingID, fl.FlagID))
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit suspension: %w", err)
+	}
+	s.notifySellers(ctx, []notify.Request{notification})
+	return true, nil
+}
+
+// matchAutoModerationRule returns a seller-facing description of the rule the
+// listing matches, or "" when none do
+func matchAutoModerationRule(ctx context.Context, tx pgx.Tx, fl models.FlaggedListing, cfg AutoModerationConfig) (string, error) {
+	var reporters int
+	err := tx.QueryRow(ctx, `
+		SELECT COUNT(DISTINCT reporter_user_id)
+		FROM flagged_listings
+		WHERE listing_id=$1 AND status IN ('OPEN', 'UNDER_REVIEW')
+		  AND created_at >= now() - $2 * INTERVAL '1 second'
+	`, fl.ListingID, int64(cfg.Window.Seconds())).Scan(&reporters)
+	if err != nil {
+		return "", fmt.Errorf("failed to count reporters: %w", err)
+	}
+	if reporters >= cfg.ReporterThreshold {
+		return fmt.Sprintf("reported by %d users", reporters), nil
+	}
+
+	if fl.ReporterUserID == nil || !slices.Contains(cfg.TrustedReasons, fl.Reason) {
+		return "", nil
+	}
+	// Admins are always trusted; other users earn it with a record of upheld flags
+	var trusted bool
+	err = tx.QueryRow(ctx, `
+		SELECT u.role = $2
+		    OR (COUNT(f.id) FILTER (WHERE f.status = 'RESOLVED') >= $3
+		        AND COUNT(f.id) FILTER (WHERE f.status = 'RESOLVED') > COUNT(f.id) FILTER (WHERE f.status = 'DISMISSED'))
+		FROM users u
+		LEFT JOIN flagged_listings f ON f.reporter_user_id = u.user_id
+		WHERE u.user_id=$1
+		GROUP BY u.role
+	`, *fl.ReporterUserID, string(httplib.ADMIN), cfg.TrustedMinUpheld).Scan(&trusted)
+	if err != nil && err != pgx.ErrNoRows {
+		return "", fmt.Errorf("failed to check reporter trust: %w", err)
+	}
+	if trusted {
+		return fmt.Sprintf("reported as %s by a trusted reporter", fl.Reason), nil
+	}
+	return "", nil
+}
+
+// restoreIfCleared puts a REPORTED listing back to AVAILABLE once every flag on it
+// has been DISMISSED. It returns the notification telling the seller the listing is
+// visible again, to be published once tx commits, or nil when nothing was restored.
+func restoreIfCleared(ctx context.Context, tx pgx.Tx, listingID int64) (*notify.Request, error) {
+	var sellerID, title string
+	var restoredAt time.Time
+	err := tx.QueryRow(ctx, `
+		UPDATE listings l
+		SET status='AVAILABLE', updated_at=now()
+		WHERE l.id=$1 AND l.status='REPORTED'
+		  AND EXISTS (SELECT 1 FROM flagged_listings f WHERE f.listing_id = l.id)
+		  AND NOT EXISTS (SELECT 1 FROM flagged_listings f WHERE f.listing_id = l.id AND f.status <> 'DISMISSED')
+		RETURNING user_id::text, title, updated_at
+	`, listingID).Scan(&sellerID, &title, &restoredAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore listing: %w", err)
+	}
+
+	req, err := sellerNotification(sellerID, notificationListingStatus, "Your listing is visible again", title,
+		map[string]any{"listing_id": listingID, "status": models.StAvailable},
+		fmt.Sprintf("listing_restored:listing:%d:%d", listingID, restoredAt.Unix()))
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
@@ -0,0 +1,286 @@
+package listing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/kunal768/cmpe202/listing-service/internal/models"
+	"github.com/kunal768/cmpe202/listing-service/internal/notify"
+)
+
+type bulkCandidate struct {
+	flagID    int64
+	listingID int64
+	status    models.FlagStatus
+	claimedBy *string
+}
+
+// BulkUpdateFlags applies one moderation action to many flags in a single transaction.
+// Flags that are already closed or sit in a case another admin has claimed are skipped;
+// everything else is updated together and gets a flag_audit_events row. A filter that
+// matches more than MaxBulkFlags flags is rejected rather than applied in part.
+func (s *Store) BulkUpdateFlags(ctx context.Context, adminID string, p models.BulkFlagParams) (models.BulkFlagResult, error) {
+	res := models.BulkFlagResult{BulkActionID: uuid.New(), Action: p.Action, Results: []models.BulkFlagItemResult{}}
+	if len(p.FlagIDs) == 0 && p.Filter == nil {
+		return res, fmt.Errorf("flag_ids or filter is required")
+	}
+
+	tx, err := s.P.Begin(ctx)
+	if err != nil {
+		return res, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	candidates, err := selectBulkCandidates(ctx, tx, p)
+	if err != nil {
+		return res, err
+	}
+
+	// Results follow the requested ids, or the matched flags when filtering
+	order := p.FlagIDs
+	if len(order) == 0 {
+		for _, c := range candidates {
+			order = append(order, c.flagID)
+		}
+	}
+	byID := make(map[int64]bulkCandidate, len(candidates))
+	for _, c := range candidates {
+		byID[c.flagID] = c
+	}
+
+	var eligible []int64
+	seen := make(map[int64]bool, len(order))
+	for _, id := range order {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		item := models.BulkFlagItemResult{FlagID: id}
+		c, ok := byID[id]
+		switch {
+		case !ok:
+			item.Result, item.Message = models.BulkItemNotFound, "flag not found"
+		case c.status == models.FlagStatusResolved || c.status == models.FlagStatusDismissed:
+			item.ListingID = c.listingID
+			item.Result, item.Message = models.BulkItemSkipped, "flag is already closed"
+		case c.claimedBy != nil && *c.claimedBy != adminID:
+			item.ListingID = c.listingID
+			item.Result, item.Message = models.BulkItemSkipped, "flag is claimed by another admin"
+		default:
+			item.ListingID = c.listingID
+			item.Result = models.BulkItemUpdated
+			eligible = append(eligible, id)
+		}
+		res.Results = append(res.Results, item)
+	}
+	items := make(map[int64]*models.BulkFlagItemResult, len(res.Results))
+	for i := range res.Results {
+		items[res.Results[i].FlagID] = &res.Results[i]
+	}
+
+	var restored []notify.Request
+	if len(eligible) > 0 {
+		if restored, err = applyBulkAction(ctx, tx, adminID, p, res.BulkActionID, eligible, byID, items); err != nil {
+			return res, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return res, fmt.Errorf("failed to commit bulk moderation: %w", err)
+	}
+	s.notifySellers(ctx, restored)
+
+	// The listing tells callers whose listings the decisions were about
+	listings := make(map[int64]models.Listing)
+	for i := range res.Results {
+		item := &res.Results[i]
+		switch item.Result {
+		case models.BulkItemUpdated:
+			res.Updated++
+		default:
+			res.Skipped++
+		}
+		if item.Flag == nil {
+			continue
+		}
+		l, ok := listings[item.ListingID]
+		if !ok {
+			if l, err = s.Get(ctx, item.ListingID); err == nil {
+				listings[item.ListingID] = l
+			}
+		}
+		item.Flag.Listing = l
+	}
+	return res, nil
+}
+
+// selectBulkCandidates locks the flags a request selects, along with whoever holds a
+// live claim on their case
+func selectBulkCandidates(ctx context.Context, tx pgx.Tx, p models.BulkFlagParams) ([]bulkCandidate, error) {
+	const base = `
+		SELECT fl.id, fl.listing_id, fl.status, c.assigned_admin_id::text
+		FROM flagged_listings fl
+		JOIN listings l ON l.id = fl.listing_id
+		LEFT JOIN moderation_cases c ON c.id = fl.case_id AND c.status = 'UNDER_REVIEW' AND c.claim_expires_at > now()
+	`
+	var rows pgx.Rows
+	var err error
+	if len(p.FlagIDs) > 0 {
+		rows, err = tx.Query(ctx, base+`WHERE fl.id = ANY($1) ORDER BY fl.id FOR UPDATE OF fl`, p.FlagIDs)
+	} else {
+		f := p.Filter
+		rows, err = tx.Query(ctx, base+`
+			WHERE ($1::flag_reason IS NULL OR fl.reason = $1)
+			  AND (CASE WHEN $2::flag_status IS NULL THEN fl.status IN ('OPEN', 'UNDER_REVIEW') ELSE fl.status = $2 END)
+			  AND ($3::timestamptz IS NULL OR fl.created_at >= $3)
+			  AND ($4::timestamptz IS NULL OR fl.created_at < $4)
+			  AND ($5::uuid IS NULL OR l.user_id = $5)
+			ORDER BY fl.created_at, fl.id
+			LIMIT $6
+			FOR UPDATE OF fl
+		`, f.Reason, f.Status, f.CreatedAfter, f.CreatedBefore, f.ListingOwnerID, models.MaxBulkFlags+1)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to select flags: %w", err)
+	}
+	defer rows.Close()
+
+	var out []bulkCandidate
+	for rows.Next() {
+		var c bulkCandidate
+		if err := rows.Scan(&c.flagID, &c.listingID, &c.status, &c.claimedBy); err != nil {
+			return nil, fmt.Errorf("failed to scan flag: %w", err)
+		}
+		out = append(out, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to select flags: %w", err)
+	}
+	if len(out) > models.MaxBulkFlags {
+		return nil, fmt.Errorf("filter matches more than %d flags", models.MaxBulkFlags)
+	}
+	return out, nil
+}
+
+// applyBulkAction closes the eligible flags and carries out the side effects of the
+// action: archiving listings, restoring cleared ones, closing emptied cases and
+// writing the audit trail. It returns the notifications for restored listings, to be
+// published once the transaction commits.
+func applyBulkAction(ctx context.Context, tx pgx.Tx, adminID string, p models.BulkFlagParams, bulkID uuid.UUID,
+	eligible []int64, byID map[int64]bulkCandidate, items map[int64]*models.BulkFlagItemResult) ([]notify.Request, error) {
+	status := models.FlagStatusResolved
+	if p.Action == models.BulkDismiss {
+		status = models.FlagStatusDismissed
+	}
+
+	rows, err := tx.Query(ctx, `
+		UPDATE flagged_listings
+		SET status=$2, resolution_notes=$3, reviewer_user_id=$4, resolved_at=now(), updated_at=now()
+		WHERE id = ANY($1)
+		RETURNING id, listing_id, reporter_user_id, reason, details, status, reviewer_user_id, resolution_notes,
+		          created_at, updated_at, resolved_at, case_id
+	`, eligible, status, p.ResolutionNotes, adminID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update flags: %w", err)
+	}
+	var caseIDs, listingIDs []int64
+	seenListing := make(map[int64]bool)
+	for rows.Next() {
+		var fl models.FlaggedListing
+		var caseID *int64
+		if err := rows.Scan(&fl.FlagID, &fl.ListingID, &fl.ReporterUserID, &fl.Reason, &fl.Details, &fl.Status, &fl.ReviewerUserID,
+			&fl.ResolutionNotes, &fl.FlagCreatedAt, &fl.FlagUpdatedAt, &fl.FlagResolvedAt, &caseID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan updated flag: %w", err)
+		}
+		items[fl.FlagID].Flag = &fl
+		if caseID != nil {
+			caseIDs = append(caseIDs, *caseID)
+		}
+		if !seenListing[fl.ListingID] {
+			seenListing[fl.ListingID] = true
+			listingIDs = append(listingIDs, fl.ListingID)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to update flags: %w", err)
+	}
+
+	if err := setRevisionActor(ctx, tx, adminID); err != nil {
+		return nil, err
+	}
+	archived := make(map[int64]bool)
+	var restored []notify.Request
+	switch p.Action {
+	case models.BulkResolveAndArchive:
+		rows, err := tx.Query(ctx, `
+			UPDATE listings SET status='ARCHIVED', updated_at=now()
+			WHERE id = ANY($1) AND status NOT IN ('SOLD', 'ARCHIVED')
+			RETURNING id
+		`, listingIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to archive listings: %w", err)
+		}
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan archived listing: %w", err)
+			}
+			archived[id] = true
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to archive listings: %w", err)
+		}
+	case models.BulkDismiss:
+		// A suspended listing comes back once every flag against it is dismissed
+		for _, id := range listingIDs {
+			req, err := restoreIfCleared(ctx, tx, id)
+			if err != nil {
+				return nil, err
+			}
+			if req != nil {
+				restored = append(restored, *req)
+			}
+		}
+	}
+
+	// Closing the last open flag of a case closes the case too
+	if len(caseIDs) > 0 {
+		if _, err := tx.Exec(ctx, `
+			UPDATE moderation_cases c
+			SET status=$2, assigned_admin_id=$3, claim_expires_at=NULL, resolved_at=now(), updated_at=now()
+			WHERE c.id = ANY($1) AND c.status IN ('OPEN', 'UNDER_REVIEW')
+			  AND NOT EXISTS (SELECT 1 FROM flagged_listings f WHERE f.case_id = c.id AND f.status IN ('OPEN', 'UNDER_REVIEW'))
+		`, caseIDs, status, adminID); err != nil {
+			return nil, fmt.Errorf("failed to close cases: %w", err)
+		}
+	}
+
+	flagIDs := make([]int64, 0, len(eligible))
+	auditListings := make([]int64, 0, len(eligible))
+	previous := make([]string, 0, len(eligible))
+	wasArchived := make([]bool, 0, len(eligible))
+	for _, id := range eligible {
+		c := byID[id]
+		items[id].ListingArchived = archived[c.listingID]
+		flagIDs = append(flagIDs, id)
+		auditListings = append(auditListings, c.listingID)
+		previous = append(previous, string(c.status))
+		wasArchived = append(wasArchived, archived[c.listingID])
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO flag_audit_events
+			(bulk_action_id, flag_id, listing_id, action, previous_status, new_status, listing_archived, notes, admin_user_id)
+		SELECT $1, u.flag_id, u.listing_id, $2, u.previous_status::flag_status, $3, u.listing_archived, $4, $5
+		FROM unnest($6::bigint[], $7::bigint[], $8::text[], $9::boolean[]) AS u(flag_id, listing_id, previous_status, listing_archived)
+	`, bulkID, p.Action, status, p.ResolutionNotes, adminID, flagIDs, auditListings, previous, wasArchived); err != nil {
+		return nil, fmt.Errorf("failed to write audit trail: %w", err)
+	}
+	return restored, nil
+}
@@ -0,0 +1,62 @@
+package listing
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrInvalidCursor is returned for a cursor that wasn't issued by encodeCursor for the
+// requested sort
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// listCursor is the keyset position of the last listing on a page.
+// Only the key matching Sort is populated: CreatedAt for created_at_desc, Price for the price sorts.
+type listCursor struct {
+	Sort      string     `json:"s"`
+	CreatedAt *time.Time `json:"c,omitempty"`
+	Price     *int64     `json:"p,omitempty"`
+	ID        int64      `json:"i"`
+}
+
+// normalizeSort maps any unknown sort to the default used by List
+func normalizeSort(sort string) string {
+	switch sort {
+	case "price_asc", "price_desc":
+		return sort
+	default:
+		return "created_at_desc"
+	}
+}
+
+// encodeCursor returns an opaque token clients hand back to fetch the next page
+func encodeCursor(c listCursor) (string, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// decodeCursor parses a token produced by encodeCursor and checks it was issued for sort
+func decodeCursor(token string, sort string) (listCursor, error) {
+	var c listCursor
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return c, ErrInvalidCursor
+	}
+	if c.Sort != sort {
+		return c, ErrInvalidCursor
+	}
+	if sort == "created_at_desc" && c.CreatedAt == nil {
+		return c, ErrInvalidCursor
+	}
+	if sort != "created_at_desc" && c.Price == nil {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
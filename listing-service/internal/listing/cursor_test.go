@@ -0,0 +1,94 @@
+package listing
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	price := int64(1999)
+
+	tests := []struct {
+		name string
+		in   listCursor
+	}{
+		{"created_at_desc", listCursor{Sort: "created_at_desc", CreatedAt: &created, ID: 42}},
+		{"price_asc", listCursor{Sort: "price_asc", Price: &price, ID: 7}},
+		{"price_desc", listCursor{Sort: "price_desc", Price: &price, ID: 9}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := encodeCursor(tt.in)
+			if err != nil {
+				t.Fatalf("encodeCursor: %v", err)
+			}
+			got, err := decodeCursor(token, tt.in.Sort)
+			if err != nil {
+				t.Fatalf("decodeCursor: %v", err)
+			}
+			if got.Sort != tt.in.Sort || got.ID != tt.in.ID {
+				t.Errorf("got %+v, want %+v", got, tt.in)
+			}
+			if tt.in.CreatedAt != nil && (got.CreatedAt == nil || !got.CreatedAt.Equal(*tt.in.CreatedAt)) {
+				t.Errorf("CreatedAt = %v, want %v", got.CreatedAt, tt.in.CreatedAt)
+			}
+			if tt.in.Price != nil && (got.Price == nil || *got.Price != *tt.in.Price) {
+				t.Errorf("Price = %v, want %v", got.Price, tt.in.Price)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorRejects(t *testing.T) {
+	created := time.Now()
+	price := int64(500)
+
+	mustEncode := func(c listCursor) string {
+		token, err := encodeCursor(c)
+		if err != nil {
+			t.Fatalf("encodeCursor: %v", err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		sort  string
+	}{
+		{"not base64", "!!!", "created_at_desc"},
+		{"not json", base64.RawURLEncoding.EncodeToString([]byte("nope")), "created_at_desc"},
+		{"sort mismatch", mustEncode(listCursor{Sort: "price_asc", Price: &price, ID: 1}), "price_desc"},
+		{"missing created_at", mustEncode(listCursor{Sort: "created_at_desc", ID: 1}), "created_at_desc"},
+		{"missing price", mustEncode(listCursor{Sort: "price_asc", CreatedAt: &created, ID: 1}), "price_asc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeCursor(tt.token, tt.sort); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("err = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
+
+func TestNormalizeSort(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"price_asc", "price_asc"},
+		{"price_desc", "price_desc"},
+		{"created_at_desc", "created_at_desc"},
+		{"", "created_at_desc"},
+		{"title", "created_at_desc"},
+	}
+	for _, tt := range tests {
+		if got := normalizeSort(tt.in); got != tt.want {
+			t.Errorf("normalizeSort(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
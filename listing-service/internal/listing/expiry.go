@@ -0,0 +1,187 @@
+package listing
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/kunal768/cmpe202/listing-service/internal/models"
+	"github.com/kunal768/cmpe202/listing-service/internal/notify"
+)
+
+// expiryLockKey is the Postgres advisory lock held while sweeping, so only one
+// replica runs a sweep at a time
+const expiryLockKey int64 = 202_0001
+
+// ExpiryConfig controls how long listings stay AVAILABLE before being archived
+type ExpiryConfig struct {
+	TTL        time.Duration // how long a listing lives after creation or renewal
+	WarnBefore time.Duration // how far ahead of expiry the seller is warned
+	Interval   time.Duration // how often the worker sweeps
+}
+
+// ExpiryConfigFromEnv reads LISTING_TTL_DAYS, LISTING_EXPIRY_WARNING_DAYS and
+// LISTING_EXPIRY_INTERVAL_MINUTES, defaulting to 30 days, 3 days and 60 minutes
+func ExpiryConfigFromEnv() ExpiryConfig {
+	return ExpiryConfig{
+		TTL:        time.Duration(envInt("LISTING_TTL_DAYS", 30)) * 24 * time.Hour,
+		WarnBefore: time.Duration(envInt("LISTING_EXPIRY_WARNING_DAYS", 3)) * 24 * time.Hour,
+		Interval:   time.Duration(envInt("LISTING_EXPIRY_INTERVAL_MINUTES", 60)) * time.Minute,
+	}
+}
+
+func envInt(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil || i <= 0 {
+		log.Printf("invalid %s=%q, using default %d", key, v, def)
+		return def
+	}
+	return i
+}
+
+// ExpiryWorker periodically warns sellers about and archives expired listings
+type ExpiryWorker struct {
+	S      *Store
+	Config ExpiryConfig
+}
+
+// Run sweeps once immediately and then every Config.Interval until ctx is cancelled
+func (w *ExpiryWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.Config.Interval)
+	defer ticker.Stop()
+
+	for {
+		warned, archived, err := w.S.SweepExpiredListings(ctx, w.Config)
+		if err != nil {
+			log.Printf("listing expiry sweep failed: %v", err)
+		} else if warned > 0 || archived > 0 {
+			log.Printf("listing expiry sweep: warned %d, archived %d", warned, archived)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SweepExpiredListings assigns an expiry to AVAILABLE listings that have none, warns
+// sellers of listings expiring within cfg.WarnBefore and archives expired listings.
+// Sellers are told through the orchestrator's notifications inbox; the notifications
+// are published before the sweep commits, so a failed publish leaves the listings for
+// the next sweep. The sweep runs under an advisory lock, so concurrent sweeps on other
+// replicas return immediately with zero counts.
+func (s *Store) SweepExpiredListings(ctx context.Context, cfg ExpiryConfig) (warned int64, archived int64, err error) {
+	tx, err := s.P.Begin(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var locked bool
+	if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, expiryLockKey).Scan(&locked); err != nil {
+		return 0, 0, fmt.Errorf("failed to acquire expiry lock: %w", err)
+	}
+	if !locked {
+		return 0, 0, nil
+	}
+
+	ttl := int64(cfg.TTL.Seconds())
+	warnBefore := int64(cfg.WarnBefore.Seconds())
+
+	// Listings created before expiry existed (or since the last sweep) get one now.
+	// Old listings are given at least the warning window so sellers hear before they go.
+	if _, err := tx.Exec(ctx, `
+		UPDATE listings
+		SET expires_at = GREATEST(created_at + $1 * INTERVAL '1 second', now() + $2 * INTERVAL '1 second')
+		WHERE status = 'AVAILABLE' AND expires_at IS NULL
+	`, ttl, warnBefore); err != nil {
+		return 0, 0, fmt.Errorf("failed to assign listing expiry: %w", err)
+	}
+
+	expiring, err := collectExpiry(tx.Query(ctx, `
+		UPDATE listings
+		SET expiry_warned_at = now()
+		WHERE status = 'AVAILABLE'
+		  AND expiry_warned_at IS NULL
+		  AND expires_at > now()
+		  AND expires_at <= now() + $1 * INTERVAL '1 second'
+		RETURNING id, user_id::text, title, expires_at
+	`, warnBefore))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to warn expiring listings: %w", err)
+	}
+
+	expired, err := collectExpiry(tx.Query(ctx, `
+		UPDATE listings
+		SET status = 'ARCHIVED', expired_at = now(), updated_at = now()
+		WHERE status = 'AVAILABLE' AND expires_at <= now()
+		RETURNING id, user_id::text, title, expires_at
+	`))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to archive expired listings: %w", err)
+	}
+
+	var reqs []notify.Request
+	for _, l := range expiring {
+		req, err := sellerNotification(l.UserID, notificationListingExpiring, "Your listing expires soon", l.Title,
+			map[string]any{"listing_id": l.ID, "expires_at": l.ExpiresAt},
+			fmt.Sprintf("listing_expiring:listing:%d:%d", l.ID, l.ExpiresAt.Unix()))
+		if err != nil {
+			return 0, 0, err
+		}
+		reqs = append(reqs, req)
+	}
+	for _, l := range expired {
+		req, err := sellerNotification(l.UserID, notificationListingStatus, "Your listing expired and was archived", l.Title,
+			map[string]any{"listing_id": l.ID, "status": models.StArchived, "expired": true},
+			fmt.Sprintf("listing_expired:listing:%d:%d", l.ID, l.ExpiresAt.Unix()))
+		if err != nil {
+			return 0, 0, err
+		}
+		reqs = append(reqs, req)
+	}
+	if err := s.publishNotifications(ctx, reqs); err != nil {
+		return 0, 0, fmt.Errorf("failed to notify sellers: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, 0, fmt.Errorf("failed to commit expiry sweep: %w", err)
+	}
+	return int64(len(expiring)), int64(len(expired)), nil
+}
+
+// expiringListing is a listing the sweep warned about or archived
+type expiringListing struct {
+	ID        int64
+	UserID    string
+	Title     string
+	ExpiresAt time.Time
+}
+
+func collectExpiry(rows pgx.Rows, err error) ([]expiringListing, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var listings []expiringListing
+	for rows.Next() {
+		var l expiringListing
+		if err := rows.Scan(&l.ID, &l.UserID, &l.Title, &l.ExpiresAt); err != nil {
+			return nil, err
+		}
+		listings = append(listings, l)
+	}
+	return listings, rows.Err()
+}
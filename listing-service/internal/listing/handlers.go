@@ -2,15 +2,20 @@ package listing
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	httplib "github.com/kunal768/cmpe202/http-lib"
+	"github.com/kunal768/cmpe202/http-lib/screening"
 	"github.com/kunal768/cmpe202/listing-service/internal/blob"
 	"github.com/kunal768/cmpe202/listing-service/internal/common"
 	"github.com/kunal768/cmpe202/listing-service/internal/gemini"
@@ -19,9 +24,13 @@ import (
 )
 
 type Handlers struct {
-	AI      *gemini.Client
-	S       *Store
-	BlobSvc blob.BlobService
+	AI             *gemini.Client
+	S              *Store
+	BlobSvc        blob.BlobService
+	Expiry         ExpiryConfig
+	ClaimTimeout   time.Duration // how long a claimed moderation case stays locked
+	AutoModeration AutoModerationConfig
+	Screener       screening.Pipeline // screens listing text on create and update
 }
 
 func (h *Handlers) CreateHandler(w http.ResponseWriter, r *http.Request) {
@@ -39,12 +48,17 @@ func (h *Handlers) CreateHandler(w http.ResponseWriter, r *http.Request) {
 		platform.Error(w, http.StatusBadRequest, "title, price, category required")
 		return
 	}
+	screened, ok := h.screenListing(w, r, &p.Title, p.Description)
+	if !ok {
+		return
+	}
 
 	l, err := h.S.Create(r.Context(), userID, p)
 	if err != nil {
 		platform.Error(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	h.applyScreening(r, &l, screened)
 	platform.JSON(w, http.StatusCreated, l)
 }
 
@@ -64,6 +78,14 @@ func (h *Handlers) ListHandler(w http.ResponseWriter, r *http.Request) {
 		Limit:  common.ParseInt(q.Get("limit"), 20),
 		Offset: common.ParseInt(q.Get("offset"), 0),
 		Sort:   q.Get("sort"),
+		Query:  q.Get("q"),
+		Cursor: q.Get("cursor"),
+	}
+	if q.Get("skip_count") == "true" {
+		f.SkipCount = true
+	}
+	if r.Header.Get("X-Role-ID") == string(httplib.ADMIN) {
+		f.Admin = true
 	}
 	if s := q.Get("keywords"); s != "" {
 		f.Keywords = strings.Fields(s)
@@ -85,12 +107,24 @@ func (h *Handlers) ListHandler(w http.ResponseWriter, r *http.Request) {
 		f.MaxPrice = &v
 	}
 
-	items, totalCount, err := h.S.List(r.Context(), &f)
+	items, totalCount, nextCursor, err := h.S.List(r.Context(), &f)
 	if err != nil {
+		if errors.Is(err, ErrInvalidCursor) {
+			platform.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		platform.Error(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	platform.JSON(w, http.StatusOK, map[string]any{"items": items, "count": totalCount})
+	// count is left out when skip_count was requested, so it never reads as zero matches
+	resp := map[string]any{"items": items}
+	if !f.SkipCount {
+		resp["count"] = totalCount
+	}
+	if nextCursor != "" {
+		resp["next_cursor"] = nextCursor
+	}
+	platform.JSON(w, http.StatusOK, resp)
 }
 
 func (h *Handlers) UpdateHandler(w http.ResponseWriter, r *http.Request) {
@@ -106,14 +140,23 @@ func (h *Handlers) UpdateHandler(w http.ResponseWriter, r *http.Request) {
 		platform.Error(w, http.StatusBadRequest, "invalid json")
 		return
 	}
+	screened, ok := h.screenListing(w, r, p.Title, p.Description)
+	if !ok {
+		return
+	}
 
 	log.Println("SQL update try from updatehandler")
 	l, err := h.S.Update(r.Context(), id, userID, userRole, p)
 	if err != nil {
+		if err.Error() == "status SOLD can only be set by a confirmed transaction" {
+			platform.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		platform.Error(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 	log.Println("SQL update passed from updatehandler")
+	h.applyScreening(r, &l, screened)
 	platform.JSON(w, http.StatusOK, l)
 }
 
@@ -184,7 +227,9 @@ func (h *Handlers) ChatSearchHandler(w http.ResponseWriter, r *http.Request) {
 		*searchParams.MaxPrice = *searchParams.MaxPrice * 100
 	}
 
-	listings, _, err := h.S.List(r.Context(), searchParams)
+	// Chat search only returns the items, so skip the COUNT(*)
+	searchParams.SkipCount = true
+	listings, _, _, err := h.S.List(r.Context(), searchParams)
 	if err != nil {
 		log.Printf("ERROR finding listings in database: %v", err)
 		http.Error(w, "Failed to retrieve listings", http.StatusInternalServerError)
@@ -379,6 +424,41 @@ func (h *Handlers) UploadUserMedia(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// CreateChatAttachmentHandler hands out an upload URL for an image to be sent in chat. The
+// blob name carries the uploader's ID; chat-consumer only accepts attachments from the
+// sender's own prefix.
+func (h *Handlers) CreateChatAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := common.ValidateUserAndRoleAuth(w, r)
+	if err != nil {
+		return
+	}
+
+	var req models.CreateChatAttachmentParams
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		platform.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	ext, ok := models.ChatAttachmentTypes[strings.ToLower(req.ContentType)]
+	if !ok {
+		platform.Error(w, http.StatusBadRequest, fmt.Sprintf("unsupported content type %q", req.ContentType))
+		return
+	}
+	if req.Size <= 0 || req.Size > models.MaxChatAttachmentSize {
+		platform.Error(w, http.StatusBadRequest, fmt.Sprintf("size must be between 1 and %d bytes", models.MaxChatAttachmentSize))
+		return
+	}
+
+	blobName := fmt.Sprintf("chat/%s/%s%s", userID, uuid.NewString(), ext)
+	upload, err := h.BlobSvc.GenerateUploadSAS(r.Context(), blobName)
+	if err != nil {
+		log.Printf("Error generating chat attachment upload URL for %s: %v", userID, err)
+		platform.Error(w, http.StatusInternalServerError, "error generating upload URL")
+		return
+	}
+
+	platform.JSON(w, http.StatusOK, upload)
+}
+
 // GetFlaggedListingsHandler handles getting all flagged listings (admin only)
 func (h *Handlers) GetFlaggedListingsHandler(w http.ResponseWriter, r *http.Request) {
 	// Validate user is authenticated and get role
@@ -483,6 +563,14 @@ func (h *Handlers) FlagListingHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The flag stands even if the auto-moderation rules can't be checked
+	suspended, err := h.S.ApplyAutoModeration(r.Context(), flaggedListing, h.AutoModeration)
+	if err != nil {
+		log.Printf("Error applying auto-moderation to listing %d: %v", listingID, err)
+	} else if suspended {
+		flaggedListing.Listing.Status = models.StReported
+	}
+
 	platform.JSON(w, http.StatusCreated, flaggedListing)
 }
 
@@ -526,11 +614,15 @@ func (h *Handlers) UpdateFlagListingHandler(w http.ResponseWriter, r *http.Reque
 	log.Println("Got update request model")
 
 	// Create the flag
-	flaggedListing, err := h.S.UpdateFlagListing(r.Context(), flagID, userID, req)
+	flaggedListing, err := h.S.UpdateFlagListing(r.Context(), flagID, userID, req, h.ClaimTimeout)
 	if err != nil {
 		log.Printf("Error flagging listing: %v", err)
-		if err.Error() == "listing not found" {
-			platform.Error(w, http.StatusNotFound, "listing not found")
+		if err.Error() == "listing not found" || err.Error() == "flag not found" {
+			platform.Error(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if err.Error() == "flag is claimed by another admin" || err.Error() == "case is already closed" {
+			platform.Error(w, http.StatusConflict, err.Error())
 			return
 		}
 		platform.Error(w, http.StatusInternalServerError, "failed to flag listing")
@@ -886,3 +978,927 @@ func (h *Handlers) GetSavedListingsHandler(w http.ResponseWriter, r *http.Reques
 
 	platform.JSON(w, http.StatusOK, savedListings)
 }
+
+// CreateOfferHandler handles a buyer making an offer on a listing
+func (h *Handlers) CreateOfferHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := common.ValidateUserAndRoleAuth(w, r)
+	if err != nil {
+		return
+	}
+
+	listingID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		platform.Error(w, http.StatusBadRequest, "invalid listing ID")
+		return
+	}
+
+	var req models.CreateOfferParams
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		platform.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Amount <= 0 {
+		platform.Error(w, http.StatusBadRequest, "amount must be greater than 0")
+		return
+	}
+
+	offer, err := h.S.CreateOffer(r.Context(), listingID, userID, req)
+	if err != nil {
+		log.Printf("Error creating offer: %v", err)
+		switch err.Error() {
+		case "listing not found":
+			platform.Error(w, http.StatusNotFound, err.Error())
+		case "cannot make an offer on your own listing":
+			platform.Error(w, http.StatusForbidden, err.Error())
+		case "listing is not available", "offer already pending":
+			platform.Error(w, http.StatusConflict, err.Error())
+		default:
+			platform.Error(w, http.StatusInternalServerError, "failed to create offer")
+		}
+		return
+	}
+
+	platform.JSON(w, http.StatusCreated, offer)
+}
+
+// GetListingOffersHandler returns the offers on a listing visible to the caller
+func (h *Handlers) GetListingOffersHandler(w http.ResponseWriter, r *http.Request) {
+	userID, userRole, err := common.ValidateUserAndRoleAuthWithRole(w, r)
+	if err != nil {
+		return
+	}
+
+	listingID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		platform.Error(w, http.StatusBadRequest, "invalid listing ID")
+		return
+	}
+
+	offers, err := h.S.GetListingOffers(r.Context(), listingID, userID, userRole)
+	if err != nil {
+		if err.Error() == "listing not found" {
+			platform.Error(w, http.StatusNotFound, err.Error())
+			return
+		}
+		platform.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	platform.JSON(w, http.StatusOK, map[string]any{"offers": offers, "count": len(offers)})
+}
+
+// GetUserOffersHandler returns offers the caller has made or received
+func (h *Handlers) GetUserOffersHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := common.ValidateUserAndRoleAuth(w, r)
+	if err != nil {
+		return
+	}
+
+	var status *models.OfferStatus
+	if s := r.URL.Query().Get("status"); s != "" {
+		st := models.OfferStatus(strings.ToUpper(s))
+		status = &st
+	}
+
+	offers, err := h.S.GetUserOffers(r.Context(), userID, status)
+	if err != nil {
+		platform.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	platform.JSON(w, http.StatusOK, map[string]any{"offers": offers, "count": len(offers)})
+}
+
+// RespondOfferHandler handles accepting, rejecting or countering an offer
+func (h *Handlers) RespondOfferHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := common.ValidateUserAndRoleAuth(w, r)
+	if err != nil {
+		return
+	}
+
+	offerID, err := strconv.ParseInt(chi.URLParam(r, "offer_id"), 10, 64)
+	if err != nil {
+		platform.Error(w, http.StatusBadRequest, "invalid offer ID")
+		return
+	}
+
+	var req models.RespondOfferParams
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		platform.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	switch req.Action {
+	case models.OfferActionAccept, models.OfferActionReject, models.OfferActionCounter:
+	default:
+		platform.Error(w, http.StatusBadRequest, "action must be one of accept, reject, counter")
+		return
+	}
+
+	resp, err := h.S.RespondToOffer(r.Context(), offerID, userID, req)
+	if err != nil {
+		log.Printf("Error responding to offer %d: %v", offerID, err)
+		switch err.Error() {
+		case "offer not found":
+			platform.Error(w, http.StatusNotFound, err.Error())
+		case "cannot respond to your own offer":
+			platform.Error(w, http.StatusForbidden, err.Error())
+		case "counter amount is required", "invalid action":
+			platform.Error(w, http.StatusBadRequest, err.Error())
+		case "offer is no longer pending", "offer has expired", "listing is not available":
+			platform.Error(w, http.StatusConflict, err.Error())
+		default:
+			platform.Error(w, http.StatusInternalServerError, "failed to respond to offer")
+		}
+		return
+	}
+
+	platform.JSON(w, http.StatusOK, resp)
+}
+
+// transactionError maps transaction store errors to HTTP statuses
+func transactionError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case "listing not found", "transaction not found", "buyer not found":
+		platform.Error(w, http.StatusNotFound, err.Error())
+	case "listing does not belong to user":
+		platform.Error(w, http.StatusForbidden, err.Error())
+	case "cannot sell a listing to yourself":
+		platform.Error(w, http.StatusBadRequest, err.Error())
+	case "listing is not available", "listing already has a sale in progress", "transaction is not pending confirmation",
+		"listing is reserved for the buyer whose offer was accepted":
+		platform.Error(w, http.StatusConflict, err.Error())
+	default:
+		platform.Error(w, http.StatusInternalServerError, "failed to process transaction")
+	}
+}
+
+// MarkSoldHandler handles the seller marking a listing sold to a buyer
+func (h *Handlers) MarkSoldHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := common.ValidateUserAndRoleAuth(w, r)
+	if err != nil {
+		return
+	}
+
+	listingID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		platform.Error(w, http.StatusBadRequest, "invalid listing ID")
+		return
+	}
+
+	var req models.MarkSoldParams
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		platform.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.BuyerUserID == uuid.Nil {
+		platform.Error(w, http.StatusBadRequest, "buyer_user_id is required")
+		return
+	}
+	if req.FinalPrice != nil && *req.FinalPrice < 0 {
+		platform.Error(w, http.StatusBadRequest, "final_price must be non-negative")
+		return
+	}
+
+	t, err := h.S.MarkSold(r.Context(), listingID, userID, req)
+	if err != nil {
+		log.Printf("Error marking listing %d sold: %v", listingID, err)
+		transactionError(w, err)
+		return
+	}
+
+	platform.JSON(w, http.StatusCreated, t)
+}
+
+// ConfirmTransactionHandler handles the buyer confirming a purchase
+func (h *Handlers) ConfirmTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := common.ValidateUserAndRoleAuth(w, r)
+	if err != nil {
+		return
+	}
+
+	transactionID, err := strconv.ParseInt(chi.URLParam(r, "transaction_id"), 10, 64)
+	if err != nil {
+		platform.Error(w, http.StatusBadRequest, "invalid transaction ID")
+		return
+	}
+
+	t, err := h.S.ConfirmTransaction(r.Context(), transactionID, userID)
+	if err != nil {
+		log.Printf("Error confirming transaction %d: %v", transactionID, err)
+		transactionError(w, err)
+		return
+	}
+
+	platform.JSON(w, http.StatusOK, t)
+}
+
+// CancelTransactionHandler handles either party cancelling a sale before confirmation
+func (h *Handlers) CancelTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := common.ValidateUserAndRoleAuth(w, r)
+	if err != nil {
+		return
+	}
+
+	transactionID, err := strconv.ParseInt(chi.URLParam(r, "transaction_id"), 10, 64)
+	if err != nil {
+		platform.Error(w, http.StatusBadRequest, "invalid transaction ID")
+		return
+	}
+
+	t, err := h.S.CancelTransaction(r.Context(), transactionID, userID)
+	if err != nil {
+		log.Printf("Error cancelling transaction %d: %v", transactionID, err)
+		transactionError(w, err)
+		return
+	}
+
+	platform.JSON(w, http.StatusOK, t)
+}
+
+// GetUserTransactionsHandler returns the caller's purchases and sales
+func (h *Handlers) GetUserTransactionsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := common.ValidateUserAndRoleAuth(w, r)
+	if err != nil {
+		return
+	}
+
+	var status *models.TransactionStatus
+	if s := r.URL.Query().Get("status"); s != "" {
+		st := models.TransactionStatus(strings.ToUpper(s))
+		status = &st
+	}
+
+	transactions, err := h.S.GetUserTransactions(r.Context(), userID, status)
+	if err != nil {
+		platform.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	platform.JSON(w, http.StatusOK, map[string]any{"transactions": transactions, "count": len(transactions)})
+}
+
+// CreateReviewHandler handles a buyer or seller rating the other party of a completed sale
+func (h *Handlers) CreateReviewHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := common.ValidateUserAndRoleAuth(w, r)
+	if err != nil {
+		return
+	}
+
+	listingID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		platform.Error(w, http.StatusBadRequest, "invalid listing ID")
+		return
+	}
+
+	var req models.CreateReviewParams
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		platform.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Rating < 1 || req.Rating > 5 {
+		platform.Error(w, http.StatusBadRequest, "rating must be between 1 and 5")
+		return
+	}
+
+	review, err := h.S.CreateReview(r.Context(), listingID, userID, req)
+	if err != nil {
+		log.Printf("Error creating review for listing %d: %v", listingID, err)
+		switch err.Error() {
+		case "listing has no completed sale":
+			platform.Error(w, http.StatusNotFound, err.Error())
+		case "user is not a party to this sale":
+			platform.Error(w, http.StatusForbidden, err.Error())
+		case "review already submitted":
+			platform.Error(w, http.StatusConflict, err.Error())
+		default:
+			platform.Error(w, http.StatusInternalServerError, "failed to create review")
+		}
+		return
+	}
+
+	platform.JSON(w, http.StatusCreated, review)
+}
+
+// GetListingReviewsHandler returns the reviews left on a listing's sale
+func (h *Handlers) GetListingReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	listingID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		platform.Error(w, http.StatusBadRequest, "invalid listing ID")
+		return
+	}
+
+	reviews, err := h.S.GetListingReviews(r.Context(), listingID)
+	if err != nil {
+		platform.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	platform.JSON(w, http.StatusOK, map[string]any{"reviews": reviews, "count": len(reviews)})
+}
+
+// GetUserReviewsHandler returns the reviews a user has received and their rating
+func (h *Handlers) GetUserReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	targetUserID := chi.URLParam(r, "user_id")
+	if _, err := uuid.Parse(targetUserID); err != nil {
+		platform.Error(w, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	reviews, summary, err := h.S.GetUserReviews(r.Context(), targetUserID)
+	if err != nil {
+		platform.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	platform.JSON(w, http.StatusOK, map[string]any{"reviews": reviews, "rating": summary})
+}
+
+// CreateSavedSearchHandler handles saving a named search to be alerted about
+func (h *Handlers) CreateSavedSearchHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := common.ValidateUserAndRoleAuth(w, r)
+	if err != nil {
+		return
+	}
+
+	var req models.SavedSearchParams
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		platform.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > 100 {
+		platform.Error(w, http.StatusBadRequest, "name is required and must be at most 100 characters")
+		return
+	}
+	if req.Query != nil && strings.TrimSpace(*req.Query) == "" {
+		req.Query = nil
+	}
+	if req.Query == nil && len(req.Keywords) == 0 && req.Category == nil && req.MinPrice == nil && req.MaxPrice == nil {
+		platform.Error(w, http.StatusBadRequest, "at least one filter is required")
+		return
+	}
+	if req.Category != nil && !slices.Contains(models.AllCategories, *req.Category) {
+		platform.Error(w, http.StatusBadRequest, "invalid category")
+		return
+	}
+	if (req.MinPrice != nil && *req.MinPrice < 0) || (req.MaxPrice != nil && *req.MaxPrice < 0) {
+		platform.Error(w, http.StatusBadRequest, "prices must be non-negative")
+		return
+	}
+	if req.MinPrice != nil && req.MaxPrice != nil && *req.MinPrice > *req.MaxPrice {
+		platform.Error(w, http.StatusBadRequest, "min_price must not exceed max_price")
+		return
+	}
+
+	ss, err := h.S.CreateSavedSearch(r.Context(), userID, req)
+	if err != nil {
+		log.Printf("Error saving search for user %s: %v", userID, err)
+		switch err.Error() {
+		case "saved search limit reached", "saved search name already exists":
+			platform.Error(w, http.StatusConflict, err.Error())
+		default:
+			platform.Error(w, http.StatusInternalServerError, "failed to save search")
+		}
+		return
+	}
+
+	platform.JSON(w, http.StatusCreated, ss)
+}
+
+// GetSavedSearchesHandler returns the caller's saved searches
+func (h *Handlers) GetSavedSearchesHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := common.ValidateUserAndRoleAuth(w, r)
+	if err != nil {
+		return
+	}
+
+	searches, err := h.S.GetSavedSearches(r.Context(), userID)
+	if err != nil {
+		platform.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	platform.JSON(w, http.StatusOK, map[string]any{"searches": searches, "count": len(searches)})
+}
+
+// DeleteSavedSearchHandler removes one of the caller's saved searches
+func (h *Handlers) DeleteSavedSearchHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := common.ValidateUserAndRoleAuth(w, r)
+	if err != nil {
+		return
+	}
+
+	searchID, err := strconv.ParseInt(chi.URLParam(r, "search_id"), 10, 64)
+	if err != nil {
+		platform.Error(w, http.StatusBadRequest, "invalid saved search ID")
+		return
+	}
+
+	if err := h.S.DeleteSavedSearch(r.Context(), searchID, userID); err != nil {
+		if err.Error() == "saved search not found" {
+			platform.Error(w, http.StatusNotFound, err.Error())
+			return
+		}
+		platform.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	platform.JSON(w, http.StatusOK, map[string]string{"message": "saved search deleted"})
+}
+
+// UpdateSavedListingAlertsHandler turns price-drop notifications for a saved listing on or off
+func (h *Handlers) UpdateSavedListingAlertsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := common.ValidateUserAndRoleAuth(w, r)
+	if err != nil {
+		return
+	}
+
+	listingID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		platform.Error(w, http.StatusBadRequest, "invalid listing ID")
+		return
+	}
+
+	var req models.SavedListingAlertsParams
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.PriceAlerts == nil {
+		platform.Error(w, http.StatusBadRequest, "price_alerts is required")
+		return
+	}
+
+	if err := h.S.SetSavedListingPriceAlerts(r.Context(), userID, listingID, *req.PriceAlerts); err != nil {
+		if err.Error() == "saved listing not found" {
+			platform.Error(w, http.StatusNotFound, err.Error())
+			return
+		}
+		log.Printf("Error updating saved listing alerts: %v", err)
+		platform.Error(w, http.StatusInternalServerError, "failed to update saved listing")
+		return
+	}
+
+	platform.JSON(w, http.StatusOK, map[string]any{"listing_id": listingID, "price_alerts": *req.PriceAlerts})
+}
+
+// RenewListingHandler handles the seller extending a listing's expiry
+func (h *Handlers) RenewListingHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := common.ValidateUserAndRoleAuth(w, r)
+	if err != nil {
+		return
+	}
+
+	listingID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		platform.Error(w, http.StatusBadRequest, "invalid listing ID")
+		return
+	}
+
+	l, err := h.S.Renew(r.Context(), listingID, userID, h.Expiry.TTL)
+	if err != nil {
+		log.Printf("Error renewing listing %d: %v", listingID, err)
+		switch err.Error() {
+		case "listing not found":
+			platform.Error(w, http.StatusNotFound, err.Error())
+		case "listing does not belong to user":
+			platform.Error(w, http.StatusForbidden, err.Error())
+		case "listing cannot be renewed":
+			platform.Error(w, http.StatusConflict, err.Error())
+		default:
+			platform.Error(w, http.StatusInternalServerError, "failed to renew listing")
+		}
+		return
+	}
+
+	platform.JSON(w, http.StatusOK, l)
+}
+
+// GetListingHistoryHandler returns a listing's revision history (admin only)
+func (h *Handlers) GetListingHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	_, userRole, err := common.ValidateUserAndRoleAuthWithRole(w, r)
+	if err != nil {
+		return
+	}
+	if userRole != string(httplib.ADMIN) {
+		platform.Error(w, http.StatusForbidden, "admin access required")
+		return
+	}
+
+	listingID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		platform.Error(w, http.StatusBadRequest, "invalid listing ID")
+		return
+	}
+
+	revisions, err := h.S.GetListingRevisions(r.Context(), listingID)
+	if err != nil {
+		log.Printf("Error fetching history for listing %d: %v", listingID, err)
+		platform.Error(w, http.StatusInternalServerError, "failed to fetch listing history")
+		return
+	}
+	if len(revisions) == 0 {
+		platform.Error(w, http.StatusNotFound, "listing not found")
+		return
+	}
+
+	platform.JSON(w, http.StatusOK, revisions)
+}
+
+// requireAdmin validates the caller is an admin and returns their user ID
+func requireAdmin(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID, userRole, err := common.ValidateUserAndRoleAuthWithRole(w, r)
+	if err != nil {
+		return "", false
+	}
+	if userRole != string(httplib.ADMIN) {
+		platform.Error(w, http.StatusForbidden, "admin access required")
+		return "", false
+	}
+	return userID, true
+}
+
+// moderationError maps moderation store errors to HTTP statuses
+func moderationError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case "case not found", "assignee not found", "no cases to review":
+		platform.Error(w, http.StatusNotFound, err.Error())
+	case "assignee is not an admin":
+		platform.Error(w, http.StatusBadRequest, err.Error())
+	case "case is already closed", "case is not claimed by you":
+		platform.Error(w, http.StatusConflict, err.Error())
+	default:
+		platform.Error(w, http.StatusInternalServerError, "failed to process moderation case")
+	}
+}
+
+func caseIDParam(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	caseID, err := strconv.ParseInt(chi.URLParam(r, "case_id"), 10, 64)
+	if err != nil {
+		platform.Error(w, http.StatusBadRequest, "invalid case ID")
+		return 0, false
+	}
+	return caseID, true
+}
+
+// ClaimNextCaseHandler hands the calling admin the next case in the moderation queue
+func (h *Handlers) ClaimNextCaseHandler(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := requireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	c, err := h.S.ClaimNextCase(r.Context(), adminID, h.ClaimTimeout)
+	if err != nil {
+		log.Printf("Error claiming next case: %v", err)
+		moderationError(w, err)
+		return
+	}
+
+	platform.JSON(w, http.StatusOK, c)
+}
+
+// GetCasesHandler lists moderation cases, filtered by ?status= and ?assigned_to=
+func (h *Handlers) GetCasesHandler(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireAdmin(w, r); !ok {
+		return
+	}
+
+	var status *models.FlagStatus
+	if v := r.URL.Query().Get("status"); v != "" {
+		st := models.FlagStatus(v)
+		if !slices.Contains(models.AllFlagStatuses, st) {
+			platform.Error(w, http.StatusBadRequest, "invalid status")
+			return
+		}
+		status = &st
+	}
+	var assignedTo *string
+	if v := r.URL.Query().Get("assigned_to"); v != "" {
+		if _, err := uuid.Parse(v); err != nil {
+			platform.Error(w, http.StatusBadRequest, "invalid assigned_to")
+			return
+		}
+		assignedTo = &v
+	}
+
+	cases, err := h.S.ListCases(r.Context(), status, assignedTo)
+	if err != nil {
+		log.Printf("Error fetching cases: %v", err)
+		platform.Error(w, http.StatusInternalServerError, "failed to fetch cases")
+		return
+	}
+
+	platform.JSON(w, http.StatusOK, cases)
+}
+
+// GetCaseHandler returns a case with its listing and flags
+func (h *Handlers) GetCaseHandler(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireAdmin(w, r); !ok {
+		return
+	}
+	caseID, ok := caseIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	c, err := h.S.GetCase(r.Context(), caseID)
+	if err != nil {
+		log.Printf("Error fetching case %d: %v", caseID, err)
+		moderationError(w, err)
+		return
+	}
+
+	platform.JSON(w, http.StatusOK, c)
+}
+
+// AssignCaseHandler assigns a case to a specific admin
+func (h *Handlers) AssignCaseHandler(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireAdmin(w, r); !ok {
+		return
+	}
+	caseID, ok := caseIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.AssignCaseParams
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		platform.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.AdminUserID == uuid.Nil {
+		platform.Error(w, http.StatusBadRequest, "admin_user_id is required")
+		return
+	}
+
+	c, err := h.S.AssignCase(r.Context(), caseID, req.AdminUserID.String(), h.ClaimTimeout)
+	if err != nil {
+		log.Printf("Error assigning case %d: %v", caseID, err)
+		moderationError(w, err)
+		return
+	}
+
+	platform.JSON(w, http.StatusOK, c)
+}
+
+// ReleaseCaseHandler returns the calling admin's claimed case to the queue
+func (h *Handlers) ReleaseCaseHandler(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := requireAdmin(w, r)
+	if !ok {
+		return
+	}
+	caseID, ok := caseIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	c, err := h.S.ReleaseCase(r.Context(), caseID, adminID)
+	if err != nil {
+		log.Printf("Error releasing case %d: %v", caseID, err)
+		moderationError(w, err)
+		return
+	}
+
+	platform.JSON(w, http.StatusOK, c)
+}
+
+// ResolveCaseHandler closes the calling admin's claimed case and all of its flags
+func (h *Handlers) ResolveCaseHandler(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := requireAdmin(w, r)
+	if !ok {
+		return
+	}
+	caseID, ok := caseIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.ResolveCaseParams
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		platform.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Status != models.FlagStatusResolved && req.Status != models.FlagStatusDismissed {
+		platform.Error(w, http.StatusBadRequest, "status must be RESOLVED or DISMISSED")
+		return
+	}
+
+	c, err := h.S.ResolveCase(r.Context(), caseID, adminID, req)
+	if err != nil {
+		log.Printf("Error resolving case %d: %v", caseID, err)
+		moderationError(w, err)
+		return
+	}
+
+	platform.JSON(w, http.StatusOK, c)
+}
+
+// GetModerationStatsHandler returns per-admin moderation workload
+func (h *Handlers) GetModerationStatsHandler(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireAdmin(w, r); !ok {
+		return
+	}
+
+	stats, err := h.S.GetModeratorWorkload(r.Context())
+	if err != nil {
+		log.Printf("Error fetching moderation stats: %v", err)
+		platform.Error(w, http.StatusInternalServerError, "failed to fetch moderation stats")
+		return
+	}
+
+	platform.JSON(w, http.StatusOK, stats)
+}
+
+// BulkUpdateFlagsHandler applies a dismiss/resolve/resolve-and-archive action to a set
+// of flags, chosen by id or by filter, and reports what happened to each one
+func (h *Handlers) BulkUpdateFlagsHandler(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := requireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.BulkFlagParams
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		platform.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	switch req.Action {
+	case models.BulkDismiss, models.BulkResolve, models.BulkResolveAndArchive:
+	default:
+		platform.Error(w, http.StatusBadRequest, "action must be DISMISS, RESOLVE or RESOLVE_AND_ARCHIVE")
+		return
+	}
+	if len(req.FlagIDs) == 0 && req.Filter == nil {
+		platform.Error(w, http.StatusBadRequest, "flag_ids or filter is required")
+		return
+	}
+	if len(req.FlagIDs) > 0 && req.Filter != nil {
+		platform.Error(w, http.StatusBadRequest, "use either flag_ids or filter, not both")
+		return
+	}
+	if len(req.FlagIDs) > models.MaxBulkFlags {
+		platform.Error(w, http.StatusBadRequest, fmt.Sprintf("at most %d flags can be updated at once", models.MaxBulkFlags))
+		return
+	}
+
+	result, err := h.S.BulkUpdateFlags(r.Context(), adminID, req)
+	if err != nil {
+		log.Printf("Error applying bulk moderation: %v", err)
+		if strings.HasPrefix(err.Error(), "filter matches more than") {
+			platform.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		platform.Error(w, http.StatusInternalServerError, "failed to apply bulk moderation")
+		return
+	}
+
+	platform.JSON(w, http.StatusOK, result)
+}
+
+// appealError maps appeal store errors to HTTP statuses
+func appealError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case "flag not found", "appeal not found":
+		platform.Error(w, http.StatusNotFound, err.Error())
+	case "listing does not belong to user", "appeal must be decided by another admin":
+		platform.Error(w, http.StatusForbidden, err.Error())
+	case "only resolved decisions can be appealed", "decision has already been appealed", "appeal is already decided":
+		platform.Error(w, http.StatusConflict, err.Error())
+	default:
+		platform.Error(w, http.StatusInternalServerError, "failed to process appeal")
+	}
+}
+
+func appealIDParam(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	appealID, err := strconv.ParseInt(chi.URLParam(r, "appeal_id"), 10, 64)
+	if err != nil {
+		platform.Error(w, http.StatusBadRequest, "invalid appeal ID")
+		return 0, false
+	}
+	return appealID, true
+}
+
+// GetModerationOutcomesHandler returns the moderation decisions on the caller's listings
+func (h *Handlers) GetModerationOutcomesHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := common.ValidateUserAndRoleAuth(w, r)
+	if err != nil {
+		return
+	}
+
+	outcomes, err := h.S.GetModerationOutcomes(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error fetching moderation outcomes: %v", err)
+		platform.Error(w, http.StatusInternalServerError, "failed to fetch moderation outcomes")
+		return
+	}
+
+	platform.JSON(w, http.StatusOK, outcomes)
+}
+
+// CreateAppealHandler lets a seller appeal a RESOLVED flag on their listing
+func (h *Handlers) CreateAppealHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := common.ValidateUserAndRoleAuth(w, r)
+	if err != nil {
+		return
+	}
+
+	flagID, err := strconv.ParseInt(chi.URLParam(r, "flag_id"), 10, 64)
+	if err != nil {
+		platform.Error(w, http.StatusBadRequest, "invalid flag ID")
+		return
+	}
+
+	var req models.CreateAppealParams
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		platform.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.Justification = strings.TrimSpace(req.Justification)
+	if req.Justification == "" {
+		platform.Error(w, http.StatusBadRequest, "justification is required")
+		return
+	}
+
+	appeal, err := h.S.CreateAppeal(r.Context(), flagID, userID, req)
+	if err != nil {
+		log.Printf("Error appealing flag %d: %v", flagID, err)
+		appealError(w, err)
+		return
+	}
+
+	platform.JSON(w, http.StatusCreated, appeal)
+}
+
+// GetAppealsHandler lists the appeals queue (admin only)
+func (h *Handlers) GetAppealsHandler(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireAdmin(w, r); !ok {
+		return
+	}
+
+	var status *models.AppealStatus
+	if v := r.URL.Query().Get("status"); v != "" {
+		st := models.AppealStatus(v)
+		if st != models.AppealPending && st != models.AppealUpheld && st != models.AppealOverturned {
+			platform.Error(w, http.StatusBadRequest, "invalid status")
+			return
+		}
+		status = &st
+	}
+
+	appeals, err := h.S.ListAppeals(r.Context(), status)
+	if err != nil {
+		log.Printf("Error fetching appeals: %v", err)
+		platform.Error(w, http.StatusInternalServerError, "failed to fetch appeals")
+		return
+	}
+
+	platform.JSON(w, http.StatusOK, appeals)
+}
+
+// GetAppealHandler returns an appeal with the flag and listing it concerns (admin only)
+func (h *Handlers) GetAppealHandler(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireAdmin(w, r); !ok {
+		return
+	}
+	appealID, ok := appealIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	appeal, err := h.S.GetAppeal(r.Context(), appealID)
+	if err != nil {
+		log.Printf("Error fetching appeal %d: %v", appealID, err)
+		appealError(w, err)
+		return
+	}
+
+	platform.JSON(w, http.StatusOK, appeal)
+}
+
+// DecideAppealHandler upholds or overturns an appeal (admin only)
+func (h *Handlers) DecideAppealHandler(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := requireAdmin(w, r)
+	if !ok {
+		return
+	}
+	appealID, ok := appealIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.DecideAppealParams
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		platform.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Status != models.AppealUpheld && req.Status != models.AppealOverturned {
+		platform.Error(w, http.StatusBadRequest, "status must be UPHELD or OVERTURNED")
+		return
+	}
+
+	appeal, err := h.S.DecideAppeal(r.Context(), appealID, adminID, req, h.Expiry.TTL)
+	if err != nil {
+		log.Printf("Error deciding appeal %d: %v", appealID, err)
+		appealError(w, err)
+		return
+	}
+
+	platform.JSON(w, http.StatusOK, appeal)
+}
@@ -0,0 +1,108 @@
+package listing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// emptyRows is a result set with no rows
+type emptyRows struct{}
+
+func (emptyRows) Close()                                       {}
+func (emptyRows) Err() error                                   { return nil }
+func (emptyRows) CommandTag() pgconn.CommandTag                { return pgconn.CommandTag{} }
+func (emptyRows) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (emptyRows) Next() bool                                   { return false }
+func (emptyRows) Scan(...any) error                            { return errors.New("no rows") }
+func (emptyRows) Values() ([]any, error)                       { return nil, nil }
+func (emptyRows) RawValues() [][]byte                          { return nil }
+func (emptyRows) Conn() *pgx.Conn                              { return nil }
+
+// countRow answers a COUNT(*) query with n
+type countRow struct{ n int }
+
+func (r countRow) Scan(dest ...any) error {
+	*dest[0].(*int) = r.n
+	return nil
+}
+
+// listPool serves List: no listings, and count for the COUNT(*) query
+type listPool struct {
+	count   int
+	queries []string
+}
+
+func (p *listPool) Query(_ context.Context, sql string, _ ...any) (pgx.Rows, error) {
+	p.queries = append(p.queries, sql)
+	return emptyRows{}, nil
+}
+
+func (p *listPool) QueryRow(_ context.Context, sql string, _ ...any) pgx.Row {
+	p.queries = append(p.queries, sql)
+	return countRow{p.count}
+}
+
+func (p *listPool) Exec(context.Context, string, ...any) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, errors.New("unexpected Exec")
+}
+
+func (p *listPool) Begin(context.Context) (pgx.Tx, error) {
+	return nil, errors.New("unexpected Begin")
+}
+
+func TestListHandlerCount(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		wantCount any // nil when the count must be absent
+		wantSQL   int
+	}{
+		{"count", "", float64(0), 2},
+		{"count with search", "?q=bike", float64(3), 2},
+		{"skip_count", "?skip_count=true", nil, 1},
+		{"skip_count with search", "?q=bike&skip_count=true", nil, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count := 0
+			if tt.wantCount != nil {
+				count = int(tt.wantCount.(float64))
+			}
+			pool := &listPool{count: count}
+			h := &Handlers{S: &Store{P: pool}}
+
+			rec := httptest.NewRecorder()
+			h.ListHandler(rec, httptest.NewRequest(http.MethodGet, "/listings/"+tt.query, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+			}
+
+			var body map[string]any
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			got, present := body["count"]
+			if tt.wantCount == nil && present {
+				t.Errorf("count = %v, want it left out", got)
+			}
+			if tt.wantCount != nil && got != tt.wantCount {
+				t.Errorf("count = %v, want %v", got, tt.wantCount)
+			}
+			if len(pool.queries) != tt.wantSQL {
+				t.Errorf("ran %d queries, want %d: %v", len(pool.queries), tt.wantSQL, pool.queries)
+			}
+			if strings.Contains(tt.query, "q=") && !strings.Contains(pool.queries[len(pool.queries)-1], "websearch_to_tsquery") {
+				t.Errorf("search term not used: %s", pool.queries[len(pool.queries)-1])
+			}
+		})
+	}
+}
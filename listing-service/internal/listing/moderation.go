@@ -0,0 +1,354 @@
+package listing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	httplib "github.com/kunal768/cmpe202/http-lib"
+	"github.com/kunal768/cmpe202/listing-service/internal/models"
+	"github.com/kunal768/cmpe202/listing-service/internal/notify"
+)
+
+// ClaimTimeoutFromEnv reads MODERATION_CLAIM_TIMEOUT_MINUTES, defaulting to models.DefaultClaimTimeout
+func ClaimTimeoutFromEnv() time.Duration {
+	return time.Duration(envInt("MODERATION_CLAIM_TIMEOUT_MINUTES", int(models.DefaultClaimTimeout/time.Minute))) * time.Minute
+}
+
+const caseColumns = `c.id, c.listing_id, c.status, c.assigned_admin_id, c.claimed_at, c.claim_expires_at, c.resolution_notes,
+	c.created_at, c.updated_at, c.resolved_at, (SELECT COUNT(*) FROM flagged_listings f WHERE f.case_id = c.id)`
+
+func scanCase(row pgx.Row) (models.ModerationCase, error) {
+	var c models.ModerationCase
+	err := row.Scan(&c.ID, &c.ListingID, &c.Status, &c.AssignedAdminID, &c.ClaimedAt, &c.ClaimExpiresAt, &c.ResolutionNotes,
+		&c.CreatedAt, &c.UpdatedAt, &c.ResolvedAt, &c.FlagCount)
+	return c, err
+}
+
+// ReleaseStaleClaims puts UNDER_REVIEW cases whose claim expired back in the queue
+func (s *Store) ReleaseStaleClaims(ctx context.Context) error {
+	_, err := s.P.Exec(ctx, `
+		WITH released AS (
+			UPDATE moderation_cases
+			SET status='OPEN', assigned_admin_id=NULL, claimed_at=NULL, claim_expires_at=NULL, updated_at=now()
+			WHERE status='UNDER_REVIEW' AND claim_expires_at <= now()
+			RETURNING id
+		)
+		UPDATE flagged_listings
+		SET status='OPEN', reviewer_user_id=NULL, updated_at=now()
+		WHERE case_id IN (SELECT id FROM released) AND status='UNDER_REVIEW'
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to release stale claims: %w", err)
+	}
+	return nil
+}
+
+// claimCase locks caseID to adminID for timeout and moves its open flags to UNDER_REVIEW
+func claimCase(ctx context.Context, tx pgx.Tx, caseID int64, adminID string, timeout time.Duration) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE moderation_cases
+		SET status='UNDER_REVIEW', assigned_admin_id=$2, claimed_at=now(),
+		    claim_expires_at=now() + $3 * INTERVAL '1 second', updated_at=now()
+		WHERE id=$1
+	`, caseID, adminID, int64(timeout.Seconds()))
+	if err != nil {
+		return fmt.Errorf("failed to claim case: %w", err)
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE flagged_listings
+		SET status='UNDER_REVIEW', reviewer_user_id=$2, updated_at=now()
+		WHERE case_id=$1 AND status IN ('OPEN', 'UNDER_REVIEW')
+	`, caseID, adminID)
+	if err != nil {
+		return fmt.Errorf("failed to claim case flags: %w", err)
+	}
+	return nil
+}
+
+// lockCase loads a case FOR UPDATE inside tx
+func lockCase(ctx context.Context, tx pgx.Tx, caseID int64) (models.ModerationCase, error) {
+	c, err := scanCase(tx.QueryRow(ctx, `SELECT `+caseColumns+` FROM moderation_cases c WHERE c.id=$1 FOR UPDATE`, caseID))
+	if err == pgx.ErrNoRows {
+		return c, fmt.Errorf("case not found")
+	}
+	if err != nil {
+		return c, fmt.Errorf("failed to fetch case: %w", err)
+	}
+	return c, nil
+}
+
+// claimedBy reports whether adminID holds a live claim on c. An expired claim no longer
+// counts, even before ReleaseStaleClaims returns it to the queue.
+func claimedBy(c models.ModerationCase, adminID string) bool {
+	return c.Status == models.FlagStatusUnderReview &&
+		c.AssignedAdminID != nil && c.AssignedAdminID.String() == adminID &&
+		c.ClaimExpiresAt != nil && c.ClaimExpiresAt.After(time.Now())
+}
+
+// ClaimNextCase hands adminID the next unassigned case, most-flagged first. An admin works
+// one case at a time: if they already hold a claim, that case is returned instead.
+// SKIP LOCKED lets concurrent claims from different admins pick different cases.
+func (s *Store) ClaimNextCase(ctx context.Context, adminID string, timeout time.Duration) (models.ModerationCase, error) {
+	if err := s.ReleaseStaleClaims(ctx); err != nil {
+		return models.ModerationCase{}, err
+	}
+
+	tx, err := s.P.Begin(ctx)
+	if err != nil {
+		return models.ModerationCase{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var caseID int64
+	err = tx.QueryRow(ctx, `SELECT id FROM moderation_cases WHERE status='UNDER_REVIEW' AND assigned_admin_id=$1 AND claim_expires_at > now() LIMIT 1 FOR UPDATE`, adminID).Scan(&caseID)
+	if err != nil && err != pgx.ErrNoRows {
+		return models.ModerationCase{}, fmt.Errorf("failed to check existing claim: %w", err)
+	}
+
+	if err == pgx.ErrNoRows {
+		err = tx.QueryRow(ctx, `
+			SELECT c.id FROM moderation_cases c
+			WHERE c.status='OPEN'
+			ORDER BY (SELECT COUNT(*) FROM flagged_listings f WHERE f.case_id = c.id) DESC, c.created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		`).Scan(&caseID)
+		if err == pgx.ErrNoRows {
+			return models.ModerationCase{}, fmt.Errorf("no cases to review")
+		}
+		if err != nil {
+			return models.ModerationCase{}, fmt.Errorf("failed to find next case: %w", err)
+		}
+		if err := claimCase(ctx, tx, caseID, adminID, timeout); err != nil {
+			return models.ModerationCase{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.ModerationCase{}, fmt.Errorf("failed to commit claim: %w", err)
+	}
+	return s.GetCase(ctx, caseID)
+}
+
+// AssignCase claims a live case on behalf of targetAdminID, taking it over from whoever held it
+func (s *Store) AssignCase(ctx context.Context, caseID int64, targetAdminID string, timeout time.Duration) (models.ModerationCase, error) {
+	var role string
+	err := s.P.QueryRow(ctx, `SELECT role FROM users WHERE user_id=$1`, targetAdminID).Scan(&role)
+	if err == pgx.ErrNoRows {
+		return models.ModerationCase{}, fmt.Errorf("assignee not found")
+	}
+	if err != nil {
+		return models.ModerationCase{}, fmt.Errorf("failed to fetch assignee: %w", err)
+	}
+	if role != string(httplib.ADMIN) {
+		return models.ModerationCase{}, fmt.Errorf("assignee is not an admin")
+	}
+
+	tx, err := s.P.Begin(ctx)
+	if err != nil {
+		return models.ModerationCase{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	c, err := lockCase(ctx, tx, caseID)
+	if err != nil {
+		return models.ModerationCase{}, err
+	}
+	if c.Status != models.FlagStatusOpen && c.Status != models.FlagStatusUnderReview {
+		return models.ModerationCase{}, fmt.Errorf("case is already closed")
+	}
+	if err := claimCase(ctx, tx, caseID, targetAdminID, timeout); err != nil {
+		return models.ModerationCase{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.ModerationCase{}, fmt.Errorf("failed to commit assignment: %w", err)
+	}
+	return s.GetCase(ctx, caseID)
+}
+
+// ReleaseCase gives up adminID's claim on a case and returns it to the queue
+func (s *Store) ReleaseCase(ctx context.Context, caseID int64, adminID string) (models.ModerationCase, error) {
+	tx, err := s.P.Begin(ctx)
+	if err != nil {
+		return models.ModerationCase{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	c, err := lockCase(ctx, tx, caseID)
+	if err != nil {
+		return models.ModerationCase{}, err
+	}
+	if !claimedBy(c, adminID) {
+		return models.ModerationCase{}, fmt.Errorf("case is not claimed by you")
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE moderation_cases
+		SET status='OPEN', assigned_admin_id=NULL, claimed_at=NULL, claim_expires_at=NULL, updated_at=now()
+		WHERE id=$1
+	`, caseID); err != nil {
+		return models.ModerationCase{}, fmt.Errorf("failed to release case: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE flagged_listings SET status='OPEN', reviewer_user_id=NULL, updated_at=now()
+		WHERE case_id=$1 AND status='UNDER_REVIEW'
+	`, caseID); err != nil {
+		return models.ModerationCase{}, fmt.Errorf("failed to release case flags: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.ModerationCase{}, fmt.Errorf("failed to commit release: %w", err)
+	}
+	return s.GetCase(ctx, caseID)
+}
+
+// ResolveCase closes a case claimed by adminID, closing every flag in it the same way
+func (s *Store) ResolveCase(ctx context.Context, caseID int64, adminID string, p models.ResolveCaseParams) (models.ModerationCase, error) {
+	tx, err := s.P.Begin(ctx)
+	if err != nil {
+		return models.ModerationCase{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	c, err := lockCase(ctx, tx, caseID)
+	if err != nil {
+		return models.ModerationCase{}, err
+	}
+	if c.Status == models.FlagStatusResolved || c.Status == models.FlagStatusDismissed {
+		return models.ModerationCase{}, fmt.Errorf("case is already closed")
+	}
+	if !claimedBy(c, adminID) {
+		return models.ModerationCase{}, fmt.Errorf("case is not claimed by you")
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE moderation_cases
+		SET status=$2, resolution_notes=$3, claim_expires_at=NULL, resolved_at=now(), updated_at=now()
+		WHERE id=$1
+	`, caseID, p.Status, p.ResolutionNotes); err != nil {
+		return models.ModerationCase{}, fmt.Errorf("failed to resolve case: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE flagged_listings
+		SET status=$2, reviewer_user_id=$3, resolution_notes=$4, resolved_at=now(), updated_at=now()
+		WHERE case_id=$1 AND status IN ('OPEN', 'UNDER_REVIEW')
+	`, caseID, p.Status, adminID, p.ResolutionNotes); err != nil {
+		return models.ModerationCase{}, fmt.Errorf("failed to resolve case flags: %w", err)
+	}
+	var restored *notify.Request
+	if p.Status == models.FlagStatusDismissed {
+		if err := setRevisionActor(ctx, tx, adminID); err != nil {
+			return models.ModerationCase{}, err
+		}
+		if restored, err = restoreIfCleared(ctx, tx, c.ListingID); err != nil {
+			return models.ModerationCase{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.ModerationCase{}, fmt.Errorf("failed to commit resolution: %w", err)
+	}
+	if restored != nil {
+		s.notifySellers(ctx, []notify.Request{*restored})
+	}
+	return s.GetCase(ctx, caseID)
+}
+
+// GetCase returns a case with its listing and flags
+func (s *Store) GetCase(ctx context.Context, caseID int64) (models.ModerationCase, error) {
+	c, err := scanCase(s.P.QueryRow(ctx, `SELECT `+caseColumns+` FROM moderation_cases c WHERE c.id=$1`, caseID))
+	if err == pgx.ErrNoRows {
+		return c, fmt.Errorf("case not found")
+	}
+	if err != nil {
+		return c, fmt.Errorf("failed to fetch case: %w", err)
+	}
+
+	listing, err := s.Get(ctx, c.ListingID)
+	if err != nil {
+		return c, fmt.Errorf("failed to fetch case listing: %w", err)
+	}
+	c.Listing = &listing
+
+	rows, err := s.P.Query(ctx, `
+		SELECT fl.id, fl.listing_id, fl.reporter_user_id, fl.reason, fl.details, fl.status, fl.reviewer_user_id,
+		       fl.resolution_notes, fl.created_at, fl.updated_at, fl.resolved_at,
+		       (SELECT to_jsonb(lr) FROM listing_revisions lr WHERE lr.id = fl.listing_revision_id), fl.appeal_status
+		FROM flagged_listings fl
+		WHERE fl.case_id=$1
+		ORDER BY fl.created_at
+	`, caseID)
+	if err != nil {
+		return c, fmt.Errorf("failed to fetch case flags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var fl models.FlaggedListing
+		if err := rows.Scan(&fl.FlagID, &fl.ListingID, &fl.ReporterUserID, &fl.Reason, &fl.Details, &fl.Status, &fl.ReviewerUserID,
+			&fl.ResolutionNotes, &fl.FlagCreatedAt, &fl.FlagUpdatedAt, &fl.FlagResolvedAt, &fl.ListingRevision, &fl.AppealStatus); err != nil {
+			return c, fmt.Errorf("failed to scan case flag: %w", err)
+		}
+		fl.Listing = listing
+		c.Flags = append(c.Flags, fl)
+	}
+	return c, rows.Err()
+}
+
+// ListCases returns cases, oldest first, optionally filtered by status and assignee
+func (s *Store) ListCases(ctx context.Context, status *models.FlagStatus, assignedTo *string) ([]models.ModerationCase, error) {
+	if err := s.ReleaseStaleClaims(ctx); err != nil {
+		return nil, err
+	}
+
+	q := `SELECT ` + caseColumns + ` FROM moderation_cases c WHERE ($1::flag_status IS NULL OR c.status = $1) AND ($2::uuid IS NULL OR c.assigned_admin_id = $2) ORDER BY c.created_at`
+	rows, err := s.P.Query(ctx, q, status, assignedTo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch cases: %w", err)
+	}
+	defer rows.Close()
+
+	var out []models.ModerationCase
+	for rows.Next() {
+		c, err := scanCase(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan case: %w", err)
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// GetModeratorWorkload returns per-admin case counts
+func (s *Store) GetModeratorWorkload(ctx context.Context) ([]models.AdminWorkload, error) {
+	rows, err := s.P.Query(ctx, `
+		SELECT u.user_id, u.user_name,
+		       COUNT(c.id) FILTER (WHERE c.status = 'UNDER_REVIEW'),
+		       COUNT(c.id) FILTER (WHERE c.status = 'RESOLVED'),
+		       COUNT(c.id) FILTER (WHERE c.status = 'DISMISSED'),
+		       MAX(c.resolved_at)
+		FROM users u
+		LEFT JOIN moderation_cases c ON c.assigned_admin_id = u.user_id
+		WHERE u.role = $1
+		GROUP BY u.user_id, u.user_name
+		ORDER BY 3 DESC, u.user_name
+	`, string(httplib.ADMIN))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch moderator workload: %w", err)
+	}
+	defer rows.Close()
+
+	var out []models.AdminWorkload
+	for rows.Next() {
+		var w models.AdminWorkload
+		if err := rows.Scan(&w.AdminUserID, &w.UserName, &w.ActiveClaims, &w.ResolvedCases, &w.DismissedCases, &w.LastClosedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan moderator workload: %w", err)
+		}
+		out = append(out, w)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,54 @@
+package listing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/kunal768/cmpe202/listing-service/internal/notify"
+)
+
+// Notification types, matching the orchestrator's inbox types
+const (
+	notificationListingStatus   = "listing_status"
+	notificationListingExpiring = "listing_expiring"
+)
+
+// sellerNotification builds an inbox notification about one of the seller's listings
+func sellerNotification(userID, typ, title, body string, data map[string]any, dedupeKey string) (notify.Request, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return notify.Request{}, fmt.Errorf("failed to marshal notification data: %w", err)
+	}
+	return notify.Request{
+		UserID:    userID,
+		Type:      typ,
+		Title:     title,
+		Body:      &body,
+		Data:      raw,
+		DedupeKey: &dedupeKey,
+	}, nil
+}
+
+// publishNotifications hands notifications to the orchestrator, which owns the inbox.
+// It does nothing when the store has no notifier.
+func (s *Store) publishNotifications(ctx context.Context, reqs []notify.Request) error {
+	if s.Notifier == nil {
+		return nil
+	}
+	for _, req := range reqs {
+		if err := s.Notifier.Publish(ctx, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifySellers publishes notifications about changes that have already committed.
+// A failed publish is logged rather than undoing the change.
+func (s *Store) notifySellers(ctx context.Context, reqs []notify.Request) {
+	if err := s.publishNotifications(ctx, reqs); err != nil {
+		log.Printf("Warning: failed to notify sellers: %v", err)
+	}
+}
@@ -2,9 +2,11 @@ package listing
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -13,6 +15,7 @@ import (
 	httplib "github.com/kunal768/cmpe202/http-lib"
 	"github.com/kunal768/cmpe202/listing-service/internal/common"
 	"github.com/kunal768/cmpe202/listing-service/internal/models"
+	"github.com/kunal768/cmpe202/listing-service/internal/notify"
 )
 
 // Better: pass *pgxpool.Pool and open per-call connections
@@ -20,9 +23,41 @@ type PgxPool interface {
 	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
 	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
 }
 
-type Store struct{ P PgxPool }
+type Store struct {
+	P PgxPool
+
+	// Notifier hands seller notifications to the orchestrator; nil disables them
+	Notifier notify.Publisher
+}
+
+// setRevisionActor attributes the listing changes made in tx to userID in listing_revisions.
+// It must run inside a transaction: the setting is local to it.
+func setRevisionActor(ctx context.Context, tx pgx.Tx, userID string) error {
+	if _, err := tx.Exec(ctx, `SELECT set_config('app.actor_user_id', $1, true)`, userID); err != nil {
+		return fmt.Errorf("failed to set revision actor: %w", err)
+	}
+	return nil
+}
+
+// withActor runs fn in a transaction whose listing changes are attributed to userID
+func (s *Store) withActor(ctx context.Context, userID string, fn func(tx pgx.Tx) error) error {
+	tx, err := s.P.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := setRevisionActor(ctx, tx, userID); err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
 
 func (s *Store) Create(ctx context.Context, userID string, p models.CreateParams) (models.Listing, error) {
 	const q = `
@@ -35,23 +70,32 @@ func (s *Store) Create(ctx context.Context, userID string, p models.CreateParams
 	RETURNING id, title, description, price, category, user_id, status, created_at;
 	`
 	var l models.Listing
-	err := s.P.QueryRow(ctx, q, p.Title, p.Description, p.Price, p.Category, userID).
-		Scan(&l.ID, &l.Title, &l.Description, &l.Price, &l.Category, &l.UserID, &l.Status, &l.CreatedAt)
+	err := s.withActor(ctx, userID, func(tx pgx.Tx) error {
+		return tx.QueryRow(ctx, q, p.Title, p.Description, p.Price, p.Category, userID).
+			Scan(&l.ID, &l.Title, &l.Description, &l.Price, &l.Category, &l.UserID, &l.Status, &l.CreatedAt)
+	})
 	log.Println("listing repo create done: ", userID)
 	return l, err
 }
 
 func (s *Store) Get(ctx context.Context, id int64) (models.Listing, error) {
-	const q = `SELECT id,title,description,price,category,user_id,status,created_at FROM listings WHERE id=$1`
+	const q = `SELECT id,title,description,price,category,user_id,status,created_at,expires_at FROM listings WHERE id=$1`
 	var l models.Listing
 	err := s.P.QueryRow(ctx, q, id).
-		Scan(&l.ID, &l.Title, &l.Description, &l.Price, &l.Category, &l.UserID, &l.Status, &l.CreatedAt)
+		Scan(&l.ID, &l.Title, &l.Description, &l.Price, &l.Category, &l.UserID, &l.Status, &l.CreatedAt, &l.ExpiresAt)
+	if err != nil {
+		return l, err
+	}
 
-	return l, err
+	listings := []models.Listing{l}
+	if err := s.attachSellerInfo(ctx, listings); err != nil {
+		return l, err
+	}
+	return listings[0], nil
 }
 
 func (s *Store) GetUserLists(ctx context.Context, user_id string) ([]models.Listing, error) {
-	const q = `SELECT id,title,description,price,category,user_id,status,created_at FROM listings WHERE user_id=$1::uuid`
+	const q = `SELECT id,title,description,price,category,user_id,status,created_at,expires_at FROM listings WHERE user_id=$1::uuid`
 	var args []any
 	args = append(args, user_id)
 	rows, err := s.P.Query(ctx, q, args...)
@@ -63,12 +107,15 @@ func (s *Store) GetUserLists(ctx context.Context, user_id string) ([]models.List
 	var out []models.Listing
 	for rows.Next() {
 		var l models.Listing
-		if err := rows.Scan(&l.ID, &l.Title, &l.Description, &l.Price, &l.Category, &l.UserID, &l.Status, &l.CreatedAt); err != nil {
+		if err := rows.Scan(&l.ID, &l.Title, &l.Description, &l.Price, &l.Category, &l.UserID, &l.Status, &l.CreatedAt, &l.ExpiresAt); err != nil {
 			return nil, err
 		}
 		out = append(out, l)
 	}
-	return out, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, s.attachSellerInfo(ctx, out)
 }
 
 func (s *Store) GetListingsByUserID(ctx context.Context, targetUserID string) ([]models.Listing, error) {
@@ -89,11 +136,19 @@ func (s *Store) GetListingsByUserID(ctx context.Context, targetUserID string) ([
 		}
 		out = append(out, l)
 	}
-	return out, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, s.attachSellerInfo(ctx, out)
 }
 
-func (s *Store) List(ctx context.Context, f *models.ListFilters) ([]models.Listing, int, error) {
-	hasKeywords := len(f.Keywords) > 0
+// List returns one page of listings matching f, the total match count and, for plain feeds,
+// a cursor for the next page (empty on the last page). The count is 0 when f.SkipCount is set.
+func (s *Store) List(ctx context.Context, f *models.ListFilters) ([]models.Listing, int, string, error) {
+	// Full-text search takes precedence; the ILIKE keyword path is the fallback
+	searchQuery := strings.TrimSpace(f.Query)
+	hasQuery := searchQuery != ""
+	hasKeywords := !hasQuery && len(f.Keywords) > 0
 
 	// First, prepare all keyword parameters for reuse
 	var keywordArgs []any
@@ -108,6 +163,12 @@ func (s *Store) List(ctx context.Context, f *models.ListFilters) ([]models.Listi
 	var where []string
 	var args []any
 
+	// The search query is always $1 so the rank expression can reuse it
+	if hasQuery {
+		args = append(args, searchQuery)
+		where = append(where, "search_vector @@ websearch_to_tsquery('english', $1)")
+	}
+
 	// Add keyword parameters first (they're already prepared)
 	if hasKeywords {
 		args = append(args, keywordArgs...)
@@ -129,6 +190,12 @@ func (s *Store) List(ctx context.Context, f *models.ListFilters) ([]models.Listi
 		args = append(args, *f.Category)
 		currentParamNum++
 	}
+	// Listings suspended by moderation stay out of the feed unless an admin asks for
+	// a status explicitly; those of suspended or banned sellers never show up
+	if f.Status == nil || !f.Admin {
+		where = append(where, "status <> 'REPORTED'")
+	}
+	where = append(where, "user_id NOT IN (SELECT u.user_id FROM users u WHERE account_is_restricted(u.account_status, u.suspended_until))")
 	if f.Status != nil {
 		where = append(where, fmt.Sprintf("status = $%d", currentParamNum))
 		args = append(args, *f.Status)
@@ -152,11 +219,35 @@ func (s *Store) List(ctx context.Context, f *models.ListFilters) ([]models.Listi
 	}
 
 	// First, execute COUNT query to get total number of matching listings
-	countQuery := "SELECT COUNT(*) FROM listings" + whereClause
 	var totalCount int
-	err := s.P.QueryRow(ctx, countQuery, args...).Scan(&totalCount)
-	if err != nil {
-		return nil, 0, err
+	if !f.SkipCount {
+		countQuery := "SELECT COUNT(*) FROM listings" + whereClause
+		if err := s.P.QueryRow(ctx, countQuery, args...).Scan(&totalCount); err != nil {
+			return nil, 0, "", err
+		}
+	}
+
+	// Keyset pagination: relevance-ranked searches have no stable key, so they stay on OFFSET
+	sort := normalizeSort(f.Sort)
+	useKeyset := !hasQuery && !hasKeywords
+	if useKeyset && f.Cursor != "" {
+		c, err := decodeCursor(f.Cursor, sort)
+		if err != nil {
+			return nil, 0, "", err
+		}
+		// The cursor condition only narrows the page, so it is kept out of the COUNT query
+		switch sort {
+		case "price_asc":
+			where = append(where, fmt.Sprintf("(price, id) > ($%d, $%d)", currentParamNum, currentParamNum+1))
+			args = append(args, *c.Price, c.ID)
+		case "price_desc":
+			where = append(where, fmt.Sprintf("(price, id) < ($%d, $%d)", currentParamNum, currentParamNum+1))
+			args = append(args, *c.Price, c.ID)
+		default:
+			where = append(where, fmt.Sprintf("(created_at, id) < ($%d, $%d)", currentParamNum, currentParamNum+1))
+			args = append(args, *c.CreatedAt, c.ID)
+		}
+		whereClause = " WHERE " + strings.Join(where, " AND ")
 	}
 
 	// Build SELECT clause with keyword score if keywords are present
@@ -181,6 +272,11 @@ func (s *Store) List(ctx context.Context, f *models.ListFilters) ([]models.Listi
 		selectFields = append(selectFields, scoreExpr)
 	}
 
+	if hasQuery {
+		// ts_rank honours the A/B weights set on search_vector (title over description)
+		selectFields = append(selectFields, "ts_rank(search_vector, websearch_to_tsquery('english', $1))::float8 AS search_rank")
+	}
+
 	sb.WriteString("SELECT " + strings.Join(selectFields, ", ") + " FROM listings")
 	sb.WriteString(whereClause)
 
@@ -188,19 +284,22 @@ func (s *Store) List(ctx context.Context, f *models.ListFilters) ([]models.Listi
 	// If keywords are present, order by keyword_score DESC first, then by the requested sort
 	// If no keywords, use the requested sort directly
 	var orderBy []string
+	if hasQuery {
+		orderBy = append(orderBy, "search_rank DESC")
+	}
 	if hasKeywords {
 		// Order by relevance score first (highest first)
 		orderBy = append(orderBy, "keyword_score DESC")
 	}
 
-	// Then apply the requested sort
-	switch f.Sort {
+	// Then apply the requested sort, with id as a tiebreaker so the order (and the cursor) is total
+	switch sort {
 	case "price_asc":
-		orderBy = append(orderBy, "price ASC")
+		orderBy = append(orderBy, "price ASC", "id ASC")
 	case "price_desc":
-		orderBy = append(orderBy, "price DESC")
+		orderBy = append(orderBy, "price DESC", "id DESC")
 	default:
-		orderBy = append(orderBy, "created_at DESC")
+		orderBy = append(orderBy, "created_at DESC", "id DESC")
 	}
 
 	sb.WriteString(" ORDER BY " + strings.Join(orderBy, ", "))
@@ -208,39 +307,77 @@ func (s *Store) List(ctx context.Context, f *models.ListFilters) ([]models.Listi
 	if f.Limit <= 0 || f.Limit > 100 {
 		f.Limit = 20
 	}
-	sb.WriteString(fmt.Sprintf(" LIMIT %d OFFSET %d", f.Limit, f.Offset))
+	if useKeyset {
+		// Fetch one extra row to learn whether a next page exists
+		offset := f.Offset
+		if f.Cursor != "" {
+			offset = 0
+		}
+		sb.WriteString(fmt.Sprintf(" LIMIT %d OFFSET %d", f.Limit+1, offset))
+	} else {
+		sb.WriteString(fmt.Sprintf(" LIMIT %d OFFSET %d", f.Limit, f.Offset))
+	}
 
 	log.Println("List SQL Query: \n", common.FormatQuery(sb.String(), args))
 
 	rows, err := s.P.Query(ctx, sb.String(), args...)
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, "", err
 	}
 	defer rows.Close()
 
 	var out []models.Listing
 	for rows.Next() {
 		var l models.Listing
-		if hasKeywords {
+		if hasQuery {
+			// Scan includes search_rank, but we don't need to store it
+			var searchRank float64
+			if err := rows.Scan(&l.ID, &l.Title, &l.Description, &l.Price, &l.Category, &l.UserID, &l.Status, &l.CreatedAt, &searchRank); err != nil {
+				return nil, 0, "", err
+			}
+		} else if hasKeywords {
 			// Scan includes keyword_score, but we don't need to store it
 			var keywordScore int
 			if err := rows.Scan(&l.ID, &l.Title, &l.Description, &l.Price, &l.Category, &l.UserID, &l.Status, &l.CreatedAt, &keywordScore); err != nil {
-				return nil, 0, err
+				return nil, 0, "", err
 			}
 		} else {
 			if err := rows.Scan(&l.ID, &l.Title, &l.Description, &l.Price, &l.Category, &l.UserID, &l.Status, &l.CreatedAt); err != nil {
-				return nil, 0, err
+				return nil, 0, "", err
 			}
 		}
 		out = append(out, l)
 	}
 	if err := rows.Err(); err != nil {
-		return nil, 0, err
+		return nil, 0, "", err
+	}
+
+	var nextCursor string
+	if useKeyset && len(out) > f.Limit {
+		out = out[:f.Limit]
+		last := out[len(out)-1]
+		c := listCursor{Sort: sort, ID: last.ID}
+		if sort == "created_at_desc" {
+			c.CreatedAt = &last.CreatedAt
+		} else {
+			c.Price = &last.Price
+		}
+		if nextCursor, err = encodeCursor(c); err != nil {
+			return nil, 0, "", err
+		}
+	}
+	if err := s.attachSellerInfo(ctx, out); err != nil {
+		return nil, 0, "", err
 	}
-	return out, totalCount, nil
+	return out, totalCount, nextCursor, nil
 }
 
 func (s *Store) Update(ctx context.Context, id int64, userID string, userRole string, p models.UpdateParams) (models.Listing, error) {
+	// SOLD is only reachable by the buyer confirming a transaction (see ConfirmTransaction)
+	if p.Status != nil && *p.Status == models.StSold {
+		return models.Listing{}, fmt.Errorf("status SOLD can only be set by a confirmed transaction")
+	}
+
 	// Build dynamic SET clause with positional parameters
 	var sets []string
 	var args []any
@@ -285,24 +422,33 @@ func (s *Store) Update(ctx context.Context, id int64, userID string, userRole st
 		q = fmt.Sprintf(`
 			UPDATE listings
 			SET %s
+			FROM (SELECT price AS previous_price FROM listings WHERE id=$%d FOR UPDATE) prev
 			WHERE id=$%d
-			RETURNING id, title, description, price, category, user_id, status, created_at
-		`, strings.Join(sets, ","), whereIDIdx)
+			RETURNING id, title, description, price, category, user_id, status, created_at, prev.previous_price
+		`, strings.Join(sets, ","), whereIDIdx, whereIDIdx)
 		args = append(args, id)
 	} else {
 		whereUserIdx := i + 1
 		q = fmt.Sprintf(`
 			UPDATE listings
 			SET %s
+			FROM (SELECT price AS previous_price FROM listings WHERE id=$%d FOR UPDATE) prev
 			WHERE id=$%d AND user_id=$%d
-			RETURNING id, title, description, price, category, user_id, status, created_at
-		`, strings.Join(sets, ","), whereIDIdx, whereUserIdx)
+			RETURNING id, title, description, price, category, user_id, status, created_at, prev.previous_price
+		`, strings.Join(sets, ","), whereIDIdx, whereIDIdx, whereUserIdx)
 		args = append(args, id, userID)
 	}
 
+	// The pre-update price is read in the same statement so price-drop events see the real change
 	var l models.Listing
-	err := s.P.QueryRow(ctx, q, args...).
-		Scan(&l.ID, &l.Title, &l.Description, &l.Price, &l.Category, &l.UserID, &l.Status, &l.CreatedAt)
+	var previousPrice int64
+	err := s.withActor(ctx, userID, func(tx pgx.Tx) error {
+		return tx.QueryRow(ctx, q, args...).
+			Scan(&l.ID, &l.Title, &l.Description, &l.Price, &l.Category, &l.UserID, &l.Status, &l.CreatedAt, &previousPrice)
+	})
+	if err == nil && previousPrice != l.Price {
+		l.PreviousPrice = &previousPrice
+	}
 	return l, err
 }
 
@@ -319,8 +465,52 @@ func (s *Store) Archive(ctx context.Context, id int64, userid string, userRole s
 		args = append(args, userid)
 	}
 
-	_, err := s.P.Exec(ctx, query, args...)
-	return err
+	return s.withActor(ctx, userid, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, query, args...)
+		return err
+	})
+}
+
+// Renew pushes a listing's expiry out by ttl from now. Sellers can renew an AVAILABLE
+// listing at any time, and a listing the expiry worker archived, which makes it AVAILABLE
+// again. Listings archived by hand, or PENDING/SOLD, cannot be renewed.
+func (s *Store) Renew(ctx context.Context, id int64, userID string, ttl time.Duration) (models.Listing, error) {
+	const q = `
+		UPDATE listings
+		SET status = 'AVAILABLE',
+		    expires_at = now() + $3 * INTERVAL '1 second',
+		    expiry_warned_at = NULL,
+		    expired_at = NULL,
+		    updated_at = now()
+		WHERE id = $1 AND user_id = $2
+		  AND (status = 'AVAILABLE' OR (status = 'ARCHIVED' AND expired_at IS NOT NULL))
+		RETURNING id, title, description, price, category, user_id, status, created_at, expires_at
+	`
+	var l models.Listing
+	err := s.withActor(ctx, userID, func(tx pgx.Tx) error {
+		return tx.QueryRow(ctx, q, id, userID, int64(ttl.Seconds())).
+			Scan(&l.ID, &l.Title, &l.Description, &l.Price, &l.Category, &l.UserID, &l.Status, &l.CreatedAt, &l.ExpiresAt)
+	})
+	if err == nil {
+		return l, nil
+	}
+	if err != pgx.ErrNoRows {
+		return models.Listing{}, fmt.Errorf("failed to renew listing: %w", err)
+	}
+
+	// Nothing updated: work out why
+	var ownerID string
+	err = s.P.QueryRow(ctx, `SELECT user_id::text FROM listings WHERE id=$1`, id).Scan(&ownerID)
+	if err == pgx.ErrNoRows {
+		return models.Listing{}, fmt.Errorf("listing not found")
+	}
+	if err != nil {
+		return models.Listing{}, fmt.Errorf("failed to fetch listing: %w", err)
+	}
+	if ownerID != userID {
+		return models.Listing{}, fmt.Errorf("listing does not belong to user")
+	}
+	return models.Listing{}, fmt.Errorf("listing cannot be renewed")
 }
 
 func (s *Store) Delete(ctx context.Context, id int64, userid string, userRole string) error {
@@ -337,7 +527,10 @@ func (s *Store) Delete(ctx context.Context, id int64, userid string, userRole st
 	}
 
 	log.Println("Testing Delete Query: ", common.FormatQuery(query, args))
-	_, err := s.P.Exec(ctx, query, args...)
+	err := s.withActor(ctx, userid, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, query, args...)
+		return err
+	})
 	log.Println("Finished Delete Query: ", err)
 	return err
 }
@@ -417,7 +610,9 @@ func (s *Store) GetFlaggedListings(ctx context.Context, status *string) ([]model
 			l.category,
 			l.user_id,
 			l.status,
-			l.created_at
+			l.created_at,
+			(SELECT to_jsonb(lr) FROM listing_revisions lr WHERE lr.id = fl.listing_revision_id),
+			fl.appeal_status
 		FROM flagged_listings fl
 		JOIN listings l ON fl.listing_id = l.id
 	`
@@ -463,6 +658,8 @@ func (s *Store) GetFlaggedListings(ctx context.Context, status *string) ([]model
 			&listing.UserID,
 			&listing.Status,
 			&listing.CreatedAt,
+			&fl.ListingRevision,
+			&fl.AppealStatus,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan flagged listing: %w", err)
@@ -475,45 +672,99 @@ func (s *Store) GetFlaggedListings(ctx context.Context, status *string) ([]model
 	return out, rows.Err()
 }
 
-// UpdateFlagListing updates a flagged listing
-func (s *Store) UpdateFlagListing(ctx context.Context, flagID int64, userID string, p models.UpdateFlagParams) (models.FlaggedListing, error) {
-
-	// First verify the flag exists
-	var flag models.FlaggedListing
-	err := s.P.QueryRow(ctx, `SELECT id,listing_id,reporter_user_id,reason,details,status,reviewer_user_id,resolution_notes,created_at,updated_at,resolved_at FROM flagged_listings WHERE id=$1`, flagID).
-		Scan(&flag.FlagID, &flag.ListingID, &flag.ReporterUserID, &flag.Reason, &flag.Details, &flag.Status, &flag.ReviewerUserID, &flag.ResolutionNotes, &flag.FlagCreatedAt, &flag.FlagUpdatedAt, &flag.FlagResolvedAt)
-	if err != nil {
+// UpdateFlagListing updates a flagged listing. A flag in a moderation case can only be
+// changed under a live claim on that case: an unclaimed case (or one whose claim ran
+// out) is claimed for userID for timeout, and one claimed by another admin is refused.
+func (s *Store) UpdateFlagListing(ctx context.Context, flagID int64, userID string, p models.UpdateFlagParams, timeout time.Duration) (models.FlaggedListing, error) {
+	var updatedFlag models.FlaggedListing
+	var restored *notify.Request
+	err := s.withActor(ctx, userID, func(tx pgx.Tx) error {
+		// First verify the flag exists
+		var caseID *int64
+		err := tx.QueryRow(ctx, `SELECT case_id FROM flagged_listings WHERE id=$1`, flagID).Scan(&caseID)
 		if err == pgx.ErrNoRows {
-			return models.FlaggedListing{}, fmt.Errorf("flag not found")
+			return fmt.Errorf("flag not found")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to verify flag: %w", err)
 		}
-		return models.FlaggedListing{}, fmt.Errorf("failed to verify flag: %w", err)
-	}
 
-	// Update the flag in flagged_listings table
-	const updateFlagQuery = `
-		UPDATE flagged_listings
-		SET status=$1, resolution_notes=$2, updated_at=now()
-		WHERE id=$3
-		RETURNING id, listing_id, reporter_user_id, reason, details, status, reviewer_user_id, resolution_notes, created_at, updated_at, resolved_at
-	`
+		// Flags in a case another admin has claimed are theirs until the claim expires
+		if caseID != nil {
+			c, err := lockCase(ctx, tx, *caseID)
+			if err != nil {
+				return err
+			}
+			if c.Status == models.FlagStatusResolved || c.Status == models.FlagStatusDismissed {
+				return fmt.Errorf("case is already closed")
+			}
+			if !claimedBy(c, userID) {
+				if c.Status == models.FlagStatusUnderReview && c.ClaimExpiresAt != nil && c.ClaimExpiresAt.After(time.Now()) {
+					return fmt.Errorf("flag is claimed by another admin")
+				}
+				if err := claimCase(ctx, tx, *caseID, userID, timeout); err != nil {
+					return err
+				}
+			}
+		}
 
-	var updatedFlag models.FlaggedListing
-	err = s.P.QueryRow(ctx, updateFlagQuery, p.Status, p.ResolutionNotes, flagID).
-		Scan(
-			&updatedFlag.FlagID,
-			&updatedFlag.ListingID,
-			&updatedFlag.ReporterUserID,
-			&updatedFlag.Reason,
-			&updatedFlag.Details,
-			&updatedFlag.Status,
-			&updatedFlag.ReviewerUserID,
-			&updatedFlag.ResolutionNotes,
-			&updatedFlag.FlagCreatedAt,
-			&updatedFlag.FlagUpdatedAt,
-			&updatedFlag.FlagResolvedAt,
-		)
+		// Update the flag in flagged_listings table
+		const updateFlagQuery = `
+			UPDATE flagged_listings
+			SET status=$1, resolution_notes=$2, reviewer_user_id=$4, updated_at=now(),
+			    resolved_at = CASE WHEN $1::flag_status IN ('RESOLVED', 'DISMISSED') THEN now() ELSE NULL END
+			WHERE id=$3
+			RETURNING id, listing_id, reporter_user_id, reason, details, status, reviewer_user_id, resolution_notes, created_at, updated_at, resolved_at
+		`
+		err = tx.QueryRow(ctx, updateFlagQuery, p.Status, p.ResolutionNotes, flagID, userID).
+			Scan(
+				&updatedFlag.FlagID,
+				&updatedFlag.ListingID,
+				&updatedFlag.ReporterUserID,
+				&updatedFlag.Reason,
+				&updatedFlag.Details,
+				&updatedFlag.Status,
+				&updatedFlag.ReviewerUserID,
+				&updatedFlag.ResolutionNotes,
+				&updatedFlag.FlagCreatedAt,
+				&updatedFlag.FlagUpdatedAt,
+				&updatedFlag.FlagResolvedAt,
+			)
+		if err != nil {
+			return fmt.Errorf("failed to update flag: %w", err)
+		}
+
+		// Closing the last open flag of a case closes the case too
+		if caseID != nil && (p.Status == models.FlagStatusResolved || p.Status == models.FlagStatusDismissed) {
+			_, err := tx.Exec(ctx, `
+				UPDATE moderation_cases c
+				SET status=$2, assigned_admin_id=$3, claim_expires_at=NULL, resolved_at=now(), updated_at=now()
+				WHERE c.id=$1 AND c.status IN ('OPEN', 'UNDER_REVIEW')
+				  AND NOT EXISTS (SELECT 1 FROM flagged_listings f WHERE f.case_id = c.id AND f.status IN ('OPEN', 'UNDER_REVIEW'))
+			`, *caseID, p.Status, userID)
+			if err != nil {
+				return fmt.Errorf("failed to close case: %w", err)
+			}
+		}
+
+		// A suspended listing comes back once every flag against it is dismissed
+		if p.Status == models.FlagStatusDismissed {
+			if restored, err = restoreIfCleared(ctx, tx, updatedFlag.ListingID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		return models.FlaggedListing{}, fmt.Errorf("failed to update flag: %w", err)
+		return models.FlaggedListing{}, err
+	}
+	if restored != nil {
+		s.notifySellers(ctx, []notify.Request{*restored})
+	}
+
+	// The listing tells callers whose listing the decision was about
+	if listing, err := s.Get(ctx, updatedFlag.ListingID); err == nil {
+		updatedFlag.Listing = listing
 	}
 
 	return updatedFlag, nil
@@ -578,17 +829,6 @@ func (s *Store) FlagListing(ctx context.Context, listingID int64, reporterUserID
 		return models.FlaggedListing{}, fmt.Errorf("failed to create flag: %w", err)
 	}
 
-	// Update listing status to REPORTED when flagged
-	// Always update status to REPORTED regardless of current status
-	_, err = s.P.Exec(ctx, `UPDATE listings SET status='REPORTED' WHERE id=$1`, listingID)
-	if err != nil {
-		log.Printf("Warning: Failed to update listing status to REPORTED: %v", err)
-		// Don't fail the flag creation if status update fails
-	} else {
-		// Update the local listing status for the response
-		listing.Status = models.StReported
-	}
-
 	// Set the listing information
 	fl.Listing = listing
 
@@ -781,6 +1021,21 @@ func (s *Store) UnsaveListing(ctx context.Context, userID string, listingID int6
 	return nil
 }
 
+// SetSavedListingPriceAlerts turns price-drop notifications for a saved listing on or off
+func (s *Store) SetSavedListingPriceAlerts(ctx context.Context, userID string, listingID int64, enabled bool) error {
+	result, err := s.P.Exec(ctx, `
+		UPDATE saved_listings SET price_alerts=$3
+		WHERE user_id=$1::uuid AND listing_id=$2
+	`, userID, listingID, enabled)
+	if err != nil {
+		return fmt.Errorf("failed to update saved listing: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("saved listing not found")
+	}
+	return nil
+}
+
 // IsListingSaved checks if a listing is saved by a user
 func (s *Store) IsListingSaved(ctx context.Context, userID string, listingID int64) (bool, error) {
 	var exists bool
@@ -805,6 +1060,7 @@ func (s *Store) GetSavedListings(ctx context.Context, userID string) ([]models.S
 			sl.user_id,
 			sl.listing_id,
 			sl.created_at,
+			sl.price_alerts,
 			l.id,
 			l.title,
 			l.description,
@@ -835,6 +1091,7 @@ func (s *Store) GetSavedListings(ctx context.Context, userID string) ([]models.S
 			&sl.UserID,
 			&sl.ListingID,
 			&sl.CreatedAt,
+			&sl.PriceAlerts,
 			&listing.ID,
 			&listing.Title,
 			&listing.Description,
@@ -854,3 +1111,712 @@ func (s *Store) GetSavedListings(ctx context.Context, userID string) ([]models.S
 
 	return savedListings, rows.Err()
 }
+
+const offerColumns = `id, listing_id, buyer_user_id, seller_user_id, created_by_user_id, parent_offer_id, amount, message, status, expires_at, created_at, updated_at, responded_at`
+
+func scanOffer(row pgx.Row) (models.Offer, error) {
+	var o models.Offer
+	err := row.Scan(&o.ID, &o.ListingID, &o.BuyerUserID, &o.SellerUserID, &o.CreatedByUserID, &o.ParentOfferID, &o.Amount, &o.Message, &o.Status, &o.ExpiresAt, &o.CreatedAt, &o.UpdatedAt, &o.RespondedAt)
+	return o, err
+}
+
+func collectOffers(rows pgx.Rows) ([]models.Offer, error) {
+	defer rows.Close()
+	var out []models.Offer
+	for rows.Next() {
+		o, err := scanOffer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan offer: %w", err)
+		}
+		out = append(out, o)
+	}
+	return out, rows.Err()
+}
+
+// offerTTL turns an optional expires_in_hours into a duration, capped at models.MaxOfferTTL
+func offerTTL(hours *int) time.Duration {
+	if hours == nil || *hours <= 0 {
+		return models.DefaultOfferTTL
+	}
+	ttl := time.Duration(*hours) * time.Hour
+	if ttl > models.MaxOfferTTL {
+		return models.MaxOfferTTL
+	}
+	return ttl
+}
+
+// ExpireOffers marks PENDING offers past their expires_at as EXPIRED.
+// It is idempotent, so it is safe to run lazily before reads and from several replicas.
+func (s *Store) ExpireOffers(ctx context.Context) error {
+	_, err := s.P.Exec(ctx, `UPDATE offers SET status='EXPIRED', updated_at=now() WHERE status='PENDING' AND expires_at <= now()`)
+	if err != nil {
+		return fmt.Errorf("failed to expire offers: %w", err)
+	}
+	return nil
+}
+
+// CreateOffer records a buyer's offer on an AVAILABLE listing they don't own
+func (s *Store) CreateOffer(ctx context.Context, listingID int64, buyerUserID string, p models.CreateOfferParams) (models.Offer, error) {
+	var sellerID string
+	var status models.Status
+	err := s.P.QueryRow(ctx, `SELECT user_id::text, status FROM listings WHERE id=$1`, listingID).Scan(&sellerID, &status)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return models.Offer{}, fmt.Errorf("listing not found")
+		}
+		return models.Offer{}, fmt.Errorf("failed to verify listing: %w", err)
+	}
+	if sellerID == buyerUserID {
+		return models.Offer{}, fmt.Errorf("cannot make an offer on your own listing")
+	}
+	if status != models.StAvailable {
+		return models.Offer{}, fmt.Errorf("listing is not available")
+	}
+
+	if err := s.ExpireOffers(ctx); err != nil {
+		return models.Offer{}, err
+	}
+
+	// A buyer keeps at most one open negotiation per listing. uq_offers_pending_buyer
+	// enforces it for concurrent requests; this check just answers the common case early.
+	var pending bool
+	err = s.P.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM offers WHERE listing_id=$1 AND buyer_user_id=$2::uuid AND status='PENDING')`, listingID, buyerUserID).Scan(&pending)
+	if err != nil {
+		return models.Offer{}, fmt.Errorf("failed to check existing offers: %w", err)
+	}
+	if pending {
+		return models.Offer{}, fmt.Errorf("offer already pending")
+	}
+
+	q := `
+		INSERT INTO offers (listing_id, buyer_user_id, seller_user_id, created_by_user_id, amount, message, expires_at)
+		VALUES ($1, $2::uuid, $3::uuid, $2::uuid, $4, $5, $6)
+		RETURNING ` + offerColumns
+	o, err := scanOffer(s.P.QueryRow(ctx, q, listingID, buyerUserID, sellerID, p.Amount, p.Message, time.Now().Add(offerTTL(p.ExpiresInHours))))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique_violation on uq_offers_pending_buyer
+			return models.Offer{}, fmt.Errorf("offer already pending")
+		}
+		return models.Offer{}, fmt.Errorf("failed to create offer: %w", err)
+	}
+	return o, nil
+}
+
+// GetListingOffers returns the offers on a listing. The seller and admins see every offer,
+// anyone else only sees their own negotiation.
+func (s *Store) GetListingOffers(ctx context.Context, listingID int64, userID string, userRole string) ([]models.Offer, error) {
+	if err := s.ExpireOffers(ctx); err != nil {
+		return nil, err
+	}
+
+	var sellerID string
+	err := s.P.QueryRow(ctx, `SELECT user_id::text FROM listings WHERE id=$1`, listingID).Scan(&sellerID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("listing not found")
+		}
+		return nil, fmt.Errorf("failed to verify listing: %w", err)
+	}
+
+	q := `SELECT ` + offerColumns + ` FROM offers WHERE listing_id=$1`
+	args := []any{listingID}
+	if userRole != string(httplib.ADMIN) && sellerID != userID {
+		q += ` AND buyer_user_id=$2::uuid`
+		args = append(args, userID)
+	}
+	q += ` ORDER BY created_at DESC`
+
+	rows, err := s.P.Query(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query offers: %w", err)
+	}
+	return collectOffers(rows)
+}
+
+// GetUserOffers returns offers the user is a party to, as buyer or seller
+func (s *Store) GetUserOffers(ctx context.Context, userID string, status *models.OfferStatus) ([]models.Offer, error) {
+	if err := s.ExpireOffers(ctx); err != nil {
+		return nil, err
+	}
+
+	q := `SELECT ` + offerColumns + ` FROM offers WHERE (buyer_user_id=$1::uuid OR seller_user_id=$1::uuid)`
+	args := []any{userID}
+	if status != nil {
+		q += ` AND status=$2`
+		args = append(args, *status)
+	}
+	q += ` ORDER BY created_at DESC`
+
+	rows, err := s.P.Query(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query offers: %w", err)
+	}
+	return collectOffers(rows)
+}
+
+// RespondToOffer lets the party who did not create a PENDING offer accept, reject or counter it.
+// Accepting moves the listing to PENDING and declines every other open offer on it,
+// all in one transaction.
+func (s *Store) RespondToOffer(ctx context.Context, offerID int64, userID string, p models.RespondOfferParams) (models.OfferResponse, error) {
+	tx, err := s.P.Begin(ctx)
+	if err != nil {
+		return models.OfferResponse{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	if err := setRevisionActor(ctx, tx, userID); err != nil {
+		return models.OfferResponse{}, err
+	}
+
+	offer, err := scanOffer(tx.QueryRow(ctx, `SELECT `+offerColumns+` FROM offers WHERE id=$1 FOR UPDATE`, offerID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return models.OfferResponse{}, fmt.Errorf("offer not found")
+		}
+		return models.OfferResponse{}, fmt.Errorf("failed to load offer: %w", err)
+	}
+
+	if offer.BuyerUserID.String() != userID && offer.SellerUserID.String() != userID {
+		return models.OfferResponse{}, fmt.Errorf("offer not found")
+	}
+	if offer.CreatedByUserID.String() == userID {
+		return models.OfferResponse{}, fmt.Errorf("cannot respond to your own offer")
+	}
+	if offer.Status != models.OfferPending {
+		return models.OfferResponse{}, fmt.Errorf("offer is no longer pending")
+	}
+	if !offer.ExpiresAt.After(time.Now()) {
+		if _, err := tx.Exec(ctx, `UPDATE offers SET status='EXPIRED', updated_at=now() WHERE id=$1`, offerID); err != nil {
+			return models.OfferResponse{}, fmt.Errorf("failed to expire offer: %w", err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return models.OfferResponse{}, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return models.OfferResponse{}, fmt.Errorf("offer has expired")
+	}
+
+	var resp models.OfferResponse
+	const setStatus = `UPDATE offers SET status=$1, responded_at=now(), updated_at=now() WHERE id=$2 RETURNING `
+
+	switch p.Action {
+	case models.OfferActionAccept:
+		// Lock the listing so two sellers' accepts can't both succeed
+		var listingStatus models.Status
+		err := tx.QueryRow(ctx, `SELECT status FROM listings WHERE id=$1 FOR UPDATE`, offer.ListingID).Scan(&listingStatus)
+		if err != nil {
+			return models.OfferResponse{}, fmt.Errorf("failed to verify listing: %w", err)
+		}
+		if listingStatus != models.StAvailable {
+			return models.OfferResponse{}, fmt.Errorf("listing is not available")
+		}
+
+		if resp.Offer, err = scanOffer(tx.QueryRow(ctx, setStatus+offerColumns, models.OfferAccepted, offerID)); err != nil {
+			return models.OfferResponse{}, fmt.Errorf("failed to accept offer: %w", err)
+		}
+		if _, err := tx.Exec(ctx, `UPDATE listings SET status='PENDING', updated_at=now() WHERE id=$1`, offer.ListingID); err != nil {
+			return models.OfferResponse{}, fmt.Errorf("failed to update listing status: %w", err)
+		}
+
+		rows, err := tx.Query(ctx, `
+			UPDATE offers SET status='DECLINED', responded_at=now(), updated_at=now()
+			WHERE listing_id=$1 AND id<>$2 AND status='PENDING'
+			RETURNING `+offerColumns, offer.ListingID, offerID)
+		if err != nil {
+			return models.OfferResponse{}, fmt.Errorf("failed to decline competing offers: %w", err)
+		}
+		if resp.Declined, err = collectOffers(rows); err != nil {
+			return models.OfferResponse{}, err
+		}
+
+	case models.OfferActionReject:
+		if resp.Offer, err = scanOffer(tx.QueryRow(ctx, setStatus+offerColumns, models.OfferRejected, offerID)); err != nil {
+			return models.OfferResponse{}, fmt.Errorf("failed to reject offer: %w", err)
+		}
+
+	case models.OfferActionCounter:
+		if p.Amount == nil || *p.Amount <= 0 {
+			return models.OfferResponse{}, fmt.Errorf("counter amount is required")
+		}
+		// Keep negotiating only while the listing is still for sale
+		var listingStatus models.Status
+		err := tx.QueryRow(ctx, `SELECT status FROM listings WHERE id=$1 FOR UPDATE`, offer.ListingID).Scan(&listingStatus)
+		if err != nil {
+			return models.OfferResponse{}, fmt.Errorf("failed to verify listing: %w", err)
+		}
+		if listingStatus != models.StAvailable {
+			return models.OfferResponse{}, fmt.Errorf("listing is not available")
+		}
+		if resp.Offer, err = scanOffer(tx.QueryRow(ctx, setStatus+offerColumns, models.OfferCountered, offerID)); err != nil {
+			return models.OfferResponse{}, fmt.Errorf("failed to counter offer: %w", err)
+		}
+		q := `
+			INSERT INTO offers (listing_id, buyer_user_id, seller_user_id, created_by_user_id, parent_offer_id, amount, message, expires_at)
+			VALUES ($1, $2, $3, $4::uuid, $5, $6, $7, $8)
+			RETURNING ` + offerColumns
+		counter, err := scanOffer(tx.QueryRow(ctx, q, offer.ListingID, offer.BuyerUserID, offer.SellerUserID, userID, offerID, *p.Amount, p.Message, time.Now().Add(offerTTL(p.ExpiresInHours))))
+		if err != nil {
+			return models.OfferResponse{}, fmt.Errorf("failed to create counter offer: %w", err)
+		}
+		resp.Counter = &counter
+
+	default:
+		return models.OfferResponse{}, fmt.Errorf("invalid action")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.OfferResponse{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return resp, nil
+}
+
+const transactionColumns = `id, listing_id, seller_user_id, buyer_user_id, offer_id, final_price, status, created_at, updated_at, completed_at, cancelled_at`
+
+func scanTransaction(row pgx.Row) (models.Transaction, error) {
+	var t models.Transaction
+	err := row.Scan(&t.ID, &t.ListingID, &t.SellerUserID, &t.BuyerUserID, &t.OfferID, &t.FinalPrice, &t.Status, &t.CreatedAt, &t.UpdatedAt, &t.CompletedAt, &t.CancelledAt)
+	return t, err
+}
+
+// MarkSold starts a sale: the seller names the buyer and the listing moves to PENDING until
+// the buyer confirms with ConfirmTransaction.
+func (s *Store) MarkSold(ctx context.Context, listingID int64, sellerUserID string, p models.MarkSoldParams) (models.Transaction, error) {
+	if p.BuyerUserID.String() == sellerUserID {
+		return models.Transaction{}, fmt.Errorf("cannot sell a listing to yourself")
+	}
+
+	tx, err := s.P.Begin(ctx)
+	if err != nil {
+		return models.Transaction{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	if err := setRevisionActor(ctx, tx, sellerUserID); err != nil {
+		return models.Transaction{}, err
+	}
+
+	var ownerID string
+	var status models.Status
+	var price int64
+	err = tx.QueryRow(ctx, `SELECT user_id::text, status, price FROM listings WHERE id=$1 FOR UPDATE`, listingID).Scan(&ownerID, &status, &price)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return models.Transaction{}, fmt.Errorf("listing not found")
+		}
+		return models.Transaction{}, fmt.Errorf("failed to verify listing: %w", err)
+	}
+	if ownerID != sellerUserID {
+		return models.Transaction{}, fmt.Errorf("listing does not belong to user")
+	}
+	if status != models.StAvailable && status != models.StPending {
+		return models.Transaction{}, fmt.Errorf("listing is not available")
+	}
+
+	var live bool
+	err = tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM transactions WHERE listing_id=$1 AND status<>'CANCELLED')`, listingID).Scan(&live)
+	if err != nil {
+		return models.Transaction{}, fmt.Errorf("failed to check existing transactions: %w", err)
+	}
+	if live {
+		return models.Transaction{}, fmt.Errorf("listing already has a sale in progress")
+	}
+
+	// Link the accepted offer, if any, and take its amount as the default price. A listing
+	// held PENDING by an accepted offer can only be sold to that offer's buyer.
+	var offerID *int64
+	var offerAmount int64
+	var offerBuyerID string
+	err = tx.QueryRow(ctx, `
+		SELECT id, amount, buyer_user_id::text FROM offers
+		WHERE listing_id=$1 AND status='ACCEPTED'
+		ORDER BY responded_at DESC
+		LIMIT 1
+	`, listingID).Scan(&offerID, &offerAmount, &offerBuyerID)
+	if err != nil && err != pgx.ErrNoRows {
+		return models.Transaction{}, fmt.Errorf("failed to look up accepted offer: %w", err)
+	}
+	if offerID != nil && offerBuyerID != p.BuyerUserID.String() {
+		if status == models.StPending {
+			return models.Transaction{}, fmt.Errorf("listing is reserved for the buyer whose offer was accepted")
+		}
+		offerID = nil
+	}
+
+	finalPrice := price
+	if p.FinalPrice != nil {
+		finalPrice = *p.FinalPrice
+	} else if offerID != nil {
+		finalPrice = offerAmount
+	}
+
+	q := `
+		INSERT INTO transactions (listing_id, seller_user_id, buyer_user_id, offer_id, final_price)
+		VALUES ($1, $2::uuid, $3, $4, $5)
+		RETURNING ` + transactionColumns
+	t, err := scanTransaction(tx.QueryRow(ctx, q, listingID, sellerUserID, p.BuyerUserID, offerID, finalPrice))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" { // foreign_key_violation
+			return models.Transaction{}, fmt.Errorf("buyer not found")
+		}
+		return models.Transaction{}, fmt.Errorf("failed to create transaction: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE listings SET status='PENDING', updated_at=now() WHERE id=$1`, listingID); err != nil {
+		return models.Transaction{}, fmt.Errorf("failed to update listing status: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.Transaction{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return t, nil
+}
+
+// ConfirmTransaction is the buyer's side of the sale: it completes the transaction and marks the listing SOLD
+func (s *Store) ConfirmTransaction(ctx context.Context, transactionID int64, buyerUserID string) (models.Transaction, error) {
+	tx, err := s.P.Begin(ctx)
+	if err != nil {
+		return models.Transaction{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	if err := setRevisionActor(ctx, tx, buyerUserID); err != nil {
+		return models.Transaction{}, err
+	}
+
+	t, err := scanTransaction(tx.QueryRow(ctx, `SELECT `+transactionColumns+` FROM transactions WHERE id=$1 FOR UPDATE`, transactionID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return models.Transaction{}, fmt.Errorf("transaction not found")
+		}
+		return models.Transaction{}, fmt.Errorf("failed to load transaction: %w", err)
+	}
+	if t.BuyerUserID.String() != buyerUserID {
+		return models.Transaction{}, fmt.Errorf("transaction not found")
+	}
+	if t.Status != models.TxPendingConfirmation {
+		return models.Transaction{}, fmt.Errorf("transaction is not pending confirmation")
+	}
+
+	t, err = scanTransaction(tx.QueryRow(ctx, `
+		UPDATE transactions SET status='COMPLETED', completed_at=now(), updated_at=now()
+		WHERE id=$1
+		RETURNING `+transactionColumns, transactionID))
+	if err != nil {
+		return models.Transaction{}, fmt.Errorf("failed to confirm transaction: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE listings SET status='SOLD', updated_at=now() WHERE id=$1`, t.ListingID); err != nil {
+		return models.Transaction{}, fmt.Errorf("failed to update listing status: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.Transaction{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return t, nil
+}
+
+// CancelTransaction lets either party back out before the buyer confirms; the listing becomes AVAILABLE again
+func (s *Store) CancelTransaction(ctx context.Context, transactionID int64, userID string) (models.Transaction, error) {
+	tx, err := s.P.Begin(ctx)
+	if err != nil {
+		return models.Transaction{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	if err := setRevisionActor(ctx, tx, userID); err != nil {
+		return models.Transaction{}, err
+	}
+
+	t, err := scanTransaction(tx.QueryRow(ctx, `SELECT `+transactionColumns+` FROM transactions WHERE id=$1 FOR UPDATE`, transactionID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return models.Transaction{}, fmt.Errorf("transaction not found")
+		}
+		return models.Transaction{}, fmt.Errorf("failed to load transaction: %w", err)
+	}
+	if t.BuyerUserID.String() != userID && t.SellerUserID.String() != userID {
+		return models.Transaction{}, fmt.Errorf("transaction not found")
+	}
+	if t.Status != models.TxPendingConfirmation {
+		return models.Transaction{}, fmt.Errorf("transaction is not pending confirmation")
+	}
+
+	t, err = scanTransaction(tx.QueryRow(ctx, `
+		UPDATE transactions SET status='CANCELLED', cancelled_at=now(), updated_at=now()
+		WHERE id=$1
+		RETURNING `+transactionColumns, transactionID))
+	if err != nil {
+		return models.Transaction{}, fmt.Errorf("failed to cancel transaction: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE listings SET status='AVAILABLE', updated_at=now() WHERE id=$1 AND status='PENDING'`, t.ListingID); err != nil {
+		return models.Transaction{}, fmt.Errorf("failed to update listing status: %w", err)
+	}
+
+	// The accepted offer behind the sale is closed too, so the listing isn't left
+	// AVAILABLE with an offer that still claims it
+	if _, err := tx.Exec(ctx, `UPDATE offers SET status='DECLINED', updated_at=now() WHERE listing_id=$1 AND status='ACCEPTED'`, t.ListingID); err != nil {
+		return models.Transaction{}, fmt.Errorf("failed to close accepted offer: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.Transaction{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return t, nil
+}
+
+// GetUserTransactions returns the transactions the user is a party to, as buyer or seller
+func (s *Store) GetUserTransactions(ctx context.Context, userID string, status *models.TransactionStatus) ([]models.Transaction, error) {
+	q := `SELECT ` + transactionColumns + ` FROM transactions WHERE (buyer_user_id=$1::uuid OR seller_user_id=$1::uuid)`
+	args := []any{userID}
+	if status != nil {
+		q += ` AND status=$2`
+		args = append(args, *status)
+	}
+	q += ` ORDER BY created_at DESC`
+
+	rows, err := s.P.Query(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var out []models.Transaction
+	for rows.Next() {
+		t, err := scanTransaction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+const reviewColumns = `id, transaction_id, listing_id, reviewer_user_id, reviewee_user_id, reviewer_role, rating, comment, created_at`
+
+func collectReviews(rows pgx.Rows) ([]models.Review, error) {
+	defer rows.Close()
+	var out []models.Review
+	for rows.Next() {
+		var r models.Review
+		if err := rows.Scan(&r.ID, &r.TransactionID, &r.ListingID, &r.ReviewerUserID, &r.RevieweeUserID, &r.ReviewerRole, &r.Rating, &r.Comment, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// CreateReview records the caller's rating of the other party of the listing's completed sale
+func (s *Store) CreateReview(ctx context.Context, listingID int64, reviewerUserID string, p models.CreateReviewParams) (models.Review, error) {
+	var transactionID int64
+	var sellerID, buyerID string
+	err := s.P.QueryRow(ctx, `
+		SELECT id, seller_user_id::text, buyer_user_id::text FROM transactions
+		WHERE listing_id=$1 AND status='COMPLETED'
+	`, listingID).Scan(&transactionID, &sellerID, &buyerID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return models.Review{}, fmt.Errorf("listing has no completed sale")
+		}
+		return models.Review{}, fmt.Errorf("failed to look up sale: %w", err)
+	}
+
+	var revieweeID string
+	var role models.ReviewerRole
+	switch reviewerUserID {
+	case buyerID:
+		revieweeID, role = sellerID, models.ReviewerBuyer
+	case sellerID:
+		revieweeID, role = buyerID, models.ReviewerSeller
+	default:
+		return models.Review{}, fmt.Errorf("user is not a party to this sale")
+	}
+
+	q := `
+		INSERT INTO reviews (transaction_id, listing_id, reviewer_user_id, reviewee_user_id, reviewer_role, rating, comment)
+		VALUES ($1, $2, $3::uuid, $4::uuid, $5, $6, $7)
+		RETURNING ` + reviewColumns
+	var r models.Review
+	err = s.P.QueryRow(ctx, q, transactionID, listingID, reviewerUserID, revieweeID, role, p.Rating, p.Comment).
+		Scan(&r.ID, &r.TransactionID, &r.ListingID, &r.ReviewerUserID, &r.RevieweeUserID, &r.ReviewerRole, &r.Rating, &r.Comment, &r.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return models.Review{}, fmt.Errorf("review already submitted")
+		}
+		return models.Review{}, fmt.Errorf("failed to create review: %w", err)
+	}
+	return r, nil
+}
+
+// GetListingReviews returns the reviews left on a listing's sale
+func (s *Store) GetListingReviews(ctx context.Context, listingID int64) ([]models.Review, error) {
+	q := `SELECT ` + reviewColumns + ` FROM reviews WHERE listing_id=$1 ORDER BY created_at DESC`
+	rows, err := s.P.Query(ctx, q, listingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch reviews: %w", err)
+	}
+	return collectReviews(rows)
+}
+
+// GetUserReviews returns the reviews a user has received along with their aggregate
+func (s *Store) GetUserReviews(ctx context.Context, userID string) ([]models.Review, models.RatingSummary, error) {
+	q := `SELECT ` + reviewColumns + ` FROM reviews WHERE reviewee_user_id=$1::uuid ORDER BY created_at DESC`
+	rows, err := s.P.Query(ctx, q, userID)
+	if err != nil {
+		return nil, models.RatingSummary{}, fmt.Errorf("failed to fetch reviews: %w", err)
+	}
+	reviews, err := collectReviews(rows)
+	if err != nil {
+		return nil, models.RatingSummary{}, err
+	}
+
+	// The rating is the user's seller rating, the same one listings show: only buyers'
+	// reviews of them count
+	var summary models.RatingSummary
+	for _, r := range reviews {
+		if r.ReviewerRole == models.ReviewerBuyer {
+			summary.Average += float64(r.Rating)
+			summary.ReviewCount++
+		}
+	}
+	if summary.ReviewCount > 0 {
+		summary.Average /= float64(summary.ReviewCount)
+	}
+	return reviews, summary, nil
+}
+
+// attachSellerInfo fills in each listing's seller rating with a single aggregate query
+func (s *Store) attachSellerInfo(ctx context.Context, listings []models.Listing) error {
+	if len(listings) == 0 {
+		return nil
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	var sellerIDs []string
+	for _, l := range listings {
+		if !seen[l.UserID] {
+			seen[l.UserID] = true
+			sellerIDs = append(sellerIDs, l.UserID.String())
+		}
+	}
+
+	rows, err := s.P.Query(ctx, `
+		SELECT reviewee_user_id, AVG(rating)::float8, COUNT(*)
+		FROM reviews
+		WHERE reviewee_user_id = ANY($1::uuid[]) AND reviewer_role = 'BUYER'
+		GROUP BY reviewee_user_id
+	`, sellerIDs)
+	if err != nil {
+		return fmt.Errorf("failed to fetch seller ratings: %w", err)
+	}
+	defer rows.Close()
+
+	ratings := make(map[uuid.UUID]models.RatingSummary)
+	for rows.Next() {
+		var id uuid.UUID
+		var summary models.RatingSummary
+		if err := rows.Scan(&id, &summary.Average, &summary.ReviewCount); err != nil {
+			return err
+		}
+		ratings[id] = summary
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range listings {
+		listings[i].Seller = &models.SellerInfo{UserID: listings[i].UserID, Rating: ratings[listings[i].UserID]}
+	}
+	return nil
+}
+
+const savedSearchColumns = `id, user_id, name, query, keywords, category, min_price, max_price, created_at, updated_at`
+
+func scanSavedSearch(row pgx.Row) (models.SavedSearch, error) {
+	var ss models.SavedSearch
+	err := row.Scan(&ss.ID, &ss.UserID, &ss.Name, &ss.Query, &ss.Keywords, &ss.Category, &ss.MinPrice, &ss.MaxPrice, &ss.CreatedAt, &ss.UpdatedAt)
+	return ss, err
+}
+
+// CreateSavedSearch stores a named search for the user
+func (s *Store) CreateSavedSearch(ctx context.Context, userID string, p models.SavedSearchParams) (models.SavedSearch, error) {
+	var count int
+	if err := s.P.QueryRow(ctx, `SELECT COUNT(*) FROM saved_searches WHERE user_id=$1::uuid`, userID).Scan(&count); err != nil {
+		return models.SavedSearch{}, fmt.Errorf("failed to count saved searches: %w", err)
+	}
+	if count >= models.MaxSavedSearchesPerUser {
+		return models.SavedSearch{}, fmt.Errorf("saved search limit reached")
+	}
+
+	keywords := p.Keywords
+	if keywords == nil {
+		keywords = []string{}
+	}
+
+	q := `
+		INSERT INTO saved_searches (user_id, name, query, keywords, category, min_price, max_price)
+		VALUES ($1::uuid, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + savedSearchColumns
+	ss, err := scanSavedSearch(s.P.QueryRow(ctx, q, userID, p.Name, p.Query, keywords, p.Category, p.MinPrice, p.MaxPrice))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return models.SavedSearch{}, fmt.Errorf("saved search name already exists")
+		}
+		return models.SavedSearch{}, fmt.Errorf("failed to save search: %w", err)
+	}
+	return ss, nil
+}
+
+// GetSavedSearches returns the user's saved searches, newest first
+func (s *Store) GetSavedSearches(ctx context.Context, userID string) ([]models.SavedSearch, error) {
+	q := `SELECT ` + savedSearchColumns + ` FROM saved_searches WHERE user_id=$1::uuid ORDER BY created_at DESC`
+	rows, err := s.P.Query(ctx, q, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch saved searches: %w", err)
+	}
+	defer rows.Close()
+
+	var out []models.SavedSearch
+	for rows.Next() {
+		ss, err := scanSavedSearch(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, ss)
+	}
+	return out, rows.Err()
+}
+
+// DeleteSavedSearch removes one of the user's saved searches
+func (s *Store) DeleteSavedSearch(ctx context.Context, searchID int64, userID string) error {
+	tag, err := s.P.Exec(ctx, `DELETE FROM saved_searches WHERE id=$1 AND user_id=$2::uuid`, searchID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete saved search: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("saved search not found")
+	}
+	return nil
+}
+
+const revisionColumns = `id, listing_id, revision, action, changed_by_user_id, changes, snapshot, created_at`
+
+// GetListingRevisions returns a listing's edit history, oldest first. History is kept
+// after the listing is deleted.
+func (s *Store) GetListingRevisions(ctx context.Context, listingID int64) ([]models.ListingRevision, error) {
+	rows, err := s.P.Query(ctx, `SELECT `+revisionColumns+` FROM listing_revisions WHERE listing_id=$1 ORDER BY revision`, listingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch listing revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var out []models.ListingRevision
+	for rows.Next() {
+		var r models.ListingRevision
+		if err := rows.Scan(&r.ID, &r.ListingID, &r.Revision, &r.Action, &r.ChangedByUserID, &r.Changes, &r.Snapshot, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan listing revision: %w", err)
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
@@ -34,22 +34,42 @@ func Routes(h *Handlers, orchReqId string) *chi.Mux {
 		r.Get("/", h.ListHandler)
 		r.Get("/{id}", h.GetHandler)
 		r.Get("/{id}/media", h.GetMediaUrlsHandler) // Public endpoint for fetching media
+		r.Get("/{id}/reviews", h.GetListingReviewsHandler)
+		r.Get("/reviews/users/{user_id}", h.GetUserReviewsHandler)
 	})
 
 	r.Group(func(r chi.Router) {
 		r.Use(userRoleProtected)
 		// Specific routes should come before parameterized routes
 		r.Get("/flagged", h.GetFlaggedListingsHandler)
+		r.Get("/{id}/history", h.GetListingHistoryHandler)
+		// Moderation queue routes (admin only)
+		r.Post("/moderation/claim", h.ClaimNextCaseHandler)
+		r.Get("/moderation/cases", h.GetCasesHandler)
+		r.Get("/moderation/cases/{case_id}", h.GetCaseHandler)
+		r.Post("/moderation/cases/{case_id}/assign", h.AssignCaseHandler)
+		r.Post("/moderation/cases/{case_id}/release", h.ReleaseCaseHandler)
+		r.Post("/moderation/cases/{case_id}/resolve", h.ResolveCaseHandler)
+		r.Get("/moderation/stats", h.GetModerationStatsHandler)
+		r.Post("/moderation/flags/bulk", h.BulkUpdateFlagsHandler)
+		// Appeal routes: sellers see decisions and appeal them, admins work the appeals queue
+		r.Get("/moderation/outcomes", h.GetModerationOutcomesHandler)
+		r.Post("/flag/{flag_id}/appeal", h.CreateAppealHandler)
+		r.Get("/appeals", h.GetAppealsHandler)
+		r.Get("/appeals/{appeal_id}", h.GetAppealHandler)
+		r.Post("/appeals/{appeal_id}/decide", h.DecideAppealHandler)
 		r.Patch("/flag/{flag_id}", h.UpdateFlagListingHandler)
 		r.Delete("/flag/{flag_id}", h.DeleteFlagListingHandler)
 		r.Get("/by-user-id", h.GetListingsByUserIDHandler)
 		r.Get("/user-lists/", h.GetUserListsHandler)
 		r.Post("/create", h.CreateHandler)
 		r.Post("/upload", h.UploadUserMedia)
+		r.Post("/attachments", h.CreateChatAttachmentHandler)
 		r.Get("/flag/{id}/check", h.HasUserFlaggedListingHandler)
 		r.Post("/flag/{id}", h.FlagListingHandler)
 		r.Patch("/update/{id}", h.UpdateHandler)
 		r.Delete("/delete/{id}", h.DeleteHandler)
+		r.Post("/{id}/renew", h.RenewListingHandler)
 		r.Post("/add-media-url/{id}", h.AddMediaURLHandler)
 		r.Patch("/{id}/media/{media_id}", h.UpdateMediaUrlHandler)
 		r.Delete("/{id}/media", h.DeleteMediaUrlHandler)
@@ -58,6 +78,23 @@ func Routes(h *Handlers, orchReqId string) *chi.Mux {
 		r.Get("/save/{id}/check", h.IsListingSavedHandler)
 		r.Post("/save/{id}", h.SaveListingHandler)
 		r.Delete("/save/{id}", h.UnsaveListingHandler)
+		r.Patch("/save/{id}/alerts", h.UpdateSavedListingAlertsHandler)
+		// Offer routes
+		r.Get("/offers", h.GetUserOffersHandler)
+		r.Post("/offers/{offer_id}/respond", h.RespondOfferHandler)
+		r.Get("/{id}/offers", h.GetListingOffersHandler)
+		r.Post("/{id}/offers", h.CreateOfferHandler)
+		// Transaction routes
+		r.Get("/transactions", h.GetUserTransactionsHandler)
+		r.Post("/transactions/{transaction_id}/confirm", h.ConfirmTransactionHandler)
+		r.Post("/transactions/{transaction_id}/cancel", h.CancelTransactionHandler)
+		r.Post("/{id}/sell", h.MarkSoldHandler)
+		// Review routes
+		r.Post("/{id}/reviews", h.CreateReviewHandler)
+		// Saved search routes
+		r.Get("/searches", h.GetSavedSearchesHandler)
+		r.Post("/searches", h.CreateSavedSearchHandler)
+		r.Delete("/searches/{search_id}", h.DeleteSavedSearchHandler)
 	})
 
 	return r
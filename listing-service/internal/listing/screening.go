@@ -0,0 +1,103 @@
+package listing
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/kunal768/cmpe202/http-lib/screening"
+	"github.com/kunal768/cmpe202/listing-service/internal/gemini"
+	"github.com/kunal768/cmpe202/listing-service/internal/models"
+	"github.com/kunal768/cmpe202/listing-service/internal/platform"
+)
+
+// geminiScreener runs listing text past the Gemini classifier
+type geminiScreener struct {
+	ai     *gemini.Client
+	action screening.Action
+}
+
+func (g geminiScreener) Screen(ctx context.Context, text string) ([]screening.Finding, error) {
+	verdict, err := g.ai.ClassifyContent(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	if !verdict.Violation {
+		return nil, nil
+	}
+	return []screening.Finding{{Rule: "llm_" + verdict.Category, Action: g.action, Reason: verdict.Reason}}, nil
+}
+
+// ScreenerFromEnv builds the listing content pipeline from the shared SCREEN_* rules
+// (see screening.FromEnv). SCREEN_LLM_ACTION adds the Gemini classifier as the last
+// step with that action; it is off by default.
+func ScreenerFromEnv(ai *gemini.Client) (screening.Pipeline, error) {
+	p, err := screening.FromEnv()
+	if err != nil {
+		return nil, err
+	}
+	action, err := screening.EnvAction("SCREEN_LLM_ACTION", "")
+	if err != nil {
+		return nil, err
+	}
+	if action != "" && ai != nil {
+		p = append(p, geminiScreener{ai: ai, action: action})
+	}
+	return p, nil
+}
+
+// screenListing screens the listing text a request writes. When a rule blocks it, it
+// writes a 422 and returns false.
+func (h *Handlers) screenListing(w http.ResponseWriter, r *http.Request, title, description *string) (screening.Result, bool) {
+	var parts []string
+	if title != nil {
+		parts = append(parts, *title)
+	}
+	if description != nil {
+		parts = append(parts, *description)
+	}
+	res := h.Screener.Screen(r.Context(), strings.Join(parts, "\n"))
+	if res.Blocked() {
+		platform.Error(w, http.StatusUnprocessableEntity, "listing was blocked: "+screening.Reasons(res.With(screening.ActionBlock)))
+		return res, false
+	}
+	return res, true
+}
+
+// FlagFromScreening opens a system flag (no reporter) on a listing that screening
+// wants reviewed. A listing only ever has one open screening flag; later matches
+// while it is open add nothing.
+func (s *Store) FlagFromScreening(ctx context.Context, listingID int64, findings []screening.Finding) error {
+	rules := make([]string, 0, len(findings))
+	for _, f := range findings {
+		rules = append(rules, f.Rule)
+	}
+	details := fmt.Sprintf("Automatic content screening (%s): %s", strings.Join(rules, ", "), screening.Reasons(findings))
+
+	_, err := s.P.Exec(ctx, `
+		INSERT INTO flagged_listings (listing_id, reporter_user_id, reason, details, status)
+		SELECT $1, NULL, $2, $3, 'OPEN'
+		WHERE NOT EXISTS (
+			SELECT 1 FROM flagged_listings
+			WHERE listing_id=$1 AND reporter_user_id IS NULL AND status IN ('OPEN', 'UNDER_REVIEW')
+		)
+	`, listingID, models.FlagReasonOther, details)
+	if err != nil {
+		return fmt.Errorf("failed to flag listing from screening: %w", err)
+	}
+	return nil
+}
+
+// applyScreening attaches the warnings in res to l and opens a screening flag when a
+// rule asked for one. The listing is already saved, so a failed flag is only logged.
+func (h *Handlers) applyScreening(r *http.Request, l *models.Listing, res screening.Result) {
+	l.ContentWarnings = res.With(screening.ActionWarn)
+	if !res.Flagged() {
+		return
+	}
+	if err := h.S.FlagFromScreening(r.Context(), l.ID, res.With(screening.ActionFlag)); err != nil {
+		log.Printf("Failed to flag listing %d from content screening: %v", l.ID, err)
+	}
+}
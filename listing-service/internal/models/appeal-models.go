@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AppealStatus string
+
+const (
+	AppealPending    AppealStatus = "PENDING"
+	AppealUpheld     AppealStatus = "UPHELD"
+	AppealOverturned AppealStatus = "OVERTURNED"
+)
+
+// ListingAppeal is a seller's appeal against a RESOLVED flag on their listing.
+// Each flag can be appealed once.
+type ListingAppeal struct {
+	ID             int64        `json:"id"`
+	FlagID         int64        `json:"flag_id"`
+	ListingID      int64        `json:"listing_id"`
+	SellerUserID   uuid.UUID    `json:"seller_user_id"`
+	Justification  string       `json:"justification"`
+	Status         AppealStatus `json:"status"`
+	ReviewerUserID *uuid.UUID   `json:"reviewer_user_id,omitempty"`
+	DecisionNotes  *string      `json:"decision_notes,omitempty"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+	DecidedAt      *time.Time   `json:"decided_at,omitempty"`
+
+	// Populated in the admin queue
+	Flag *FlaggedListing `json:"flag,omitempty"`
+}
+
+// ModerationOutcome is a closed flag on a seller's own listing, as the seller sees it.
+// The reporter is never revealed.
+type ModerationOutcome struct {
+	FlagID          int64          `json:"flag_id"`
+	ListingID       int64          `json:"listing_id"`
+	ListingTitle    string         `json:"listing_title"`
+	ListingStatus   Status         `json:"listing_status"`
+	Reason          FlagReason     `json:"reason"`
+	Status          FlagStatus     `json:"status"`
+	ResolutionNotes *string        `json:"resolution_notes,omitempty"`
+	ResolvedAt      *time.Time     `json:"resolved_at,omitempty"`
+	Appealable      bool           `json:"appealable"`
+	Appeal          *ListingAppeal `json:"appeal,omitempty"`
+}
+
+// CreateAppealParams is the seller's case for reversing a decision
+type CreateAppealParams struct {
+	Justification string `json:"justification"`
+}
+
+// DecideAppealParams closes an appeal. Status must be UPHELD or OVERTURNED.
+type DecideAppealParams struct {
+	Status        AppealStatus `json:"status"`
+	DecisionNotes *string      `json:"decision_notes,omitempty"`
+}
@@ -0,0 +1,18 @@
+package models
+
+// MaxChatAttachmentSize caps one chat image. chat-consumer checks the uploaded blob
+// against the same limit before the message is stored.
+const MaxChatAttachmentSize = 10 << 20 // 10 MB
+
+// ChatAttachmentTypes are the image types chat accepts, with the extension their blobs get
+var ChatAttachmentTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// CreateChatAttachmentParams describes the image a user is about to upload for chat
+type CreateChatAttachmentParams struct {
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
@@ -28,3 +28,10 @@ type ChatMessage struct {
 	Role    string `json:"role"`    // "user" or "assistant"
 	Content string `json:"content"`
 }
+
+// ContentClassification is Gemini's verdict on whether user text breaks marketplace rules
+type ContentClassification struct {
+	Violation bool   `json:"violation"`
+	Category  string `json:"category"` // e.g. "scam", "prohibited_item", "harassment"
+	Reason    string `json:"reason"`
+}
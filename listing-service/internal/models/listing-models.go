@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/kunal768/cmpe202/http-lib/screening"
 )
 
 type Category string
@@ -53,6 +54,15 @@ type Listing struct {
 	UserID      uuid.UUID `json:"user_id"`
 	Status      Status    `json:"status"`
 	CreatedAt   time.Time `json:"created_at"`
+
+	// Seller is populated on read paths with the seller's review aggregate
+	Seller *SellerInfo `json:"seller,omitempty"`
+	// PreviousPrice is set by Update when the price changed
+	PreviousPrice *int64 `json:"previous_price,omitempty"`
+	// ExpiresAt is when an AVAILABLE listing will be archived unless renewed (seller-facing reads)
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// ContentWarnings is set by Create and Update when content screening warned about the text
+	ContentWarnings []screening.Finding `json:"content_warnings,omitempty"`
 }
 
 type CreateParams struct {
@@ -75,6 +85,9 @@ type AddMediaParams struct {
 }
 
 type ListFilters struct {
+	// Query is a free-text search matched against the listings search_vector.
+	// When set it takes precedence over Keywords, which use the ILIKE fallback.
+	Query    string    `json:"query,omitempty"`
 	Keywords []string  `json:"keywords,omitempty"`
 	Category *Category `json:"category,omitempty"`
 	Status   *Status   `json:"status,omitempty"`
@@ -83,6 +96,14 @@ type ListFilters struct {
 	Limit    int
 	Offset   int
 	Sort     string // "created_at_desc", "price_asc", "price_desc"
+
+	// Cursor is an opaque next_cursor token from a previous page; when set, Offset is ignored.
+	// Keyset paging only applies to plain feeds - relevance-ranked searches keep using Offset.
+	Cursor string
+	// SkipCount skips the COUNT(*) query for callers that only page forward
+	SkipCount bool
+	// Admin lets an explicit Status filter reach listings suspended by moderation
+	Admin bool `json:"-"`
 }
 
 type FileMetadata struct {
@@ -108,6 +129,13 @@ const (
 	FlagStatusDismissed   FlagStatus = "DISMISSED"
 )
 
+var AllFlagStatuses = []FlagStatus{
+	FlagStatusOpen,
+	FlagStatusUnderReview,
+	FlagStatusResolved,
+	FlagStatusDismissed,
+}
+
 // CreateFlagParams represents the parameters for creating a flag
 type CreateFlagParams struct {
 	ListingID int64      `json:"listing_id"`
@@ -138,6 +166,12 @@ type FlaggedListing struct {
 
 	// Listing information
 	Listing Listing `json:"listing"`
+
+	// ListingRevision is the listing as it was when the flag was filed
+	ListingRevision *ListingRevision `json:"listing_revision,omitempty"`
+
+	// AppealStatus is set once the seller appeals a RESOLVED flag
+	AppealStatus *AppealStatus `json:"appeal_status,omitempty"`
 }
 
 // ListingMedia represents a media URL associated with a listing
@@ -159,7 +193,14 @@ type SavedListing struct {
 	UserID    uuid.UUID `json:"user_id" db:"user_id"`
 	ListingID int64     `json:"listing_id" db:"listing_id"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	// PriceAlerts is false when the user opted out of price-drop notifications for this listing
+	PriceAlerts bool `json:"price_alerts" db:"price_alerts"`
 	
 	// Listing information (populated when fetching saved listings)
 	Listing Listing `json:"listing,omitempty"`
 }
+
+// SavedListingAlertsParams toggles price-drop notifications for a saved listing
+type SavedListingAlertsParams struct {
+	PriceAlerts *bool `json:"price_alerts"`
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const DefaultClaimTimeout = 30 * time.Minute // how long a claimed case stays locked to an admin
+
+// ModerationCase groups the open flags on one listing into a unit of admin work.
+// Status follows the flag lifecycle: OPEN cases are in the queue, UNDER_REVIEW cases
+// are claimed by AssignedAdminID until ClaimExpiresAt.
+type ModerationCase struct {
+	ID              int64      `json:"id"`
+	ListingID       int64      `json:"listing_id"`
+	Status          FlagStatus `json:"status"`
+	AssignedAdminID *uuid.UUID `json:"assigned_admin_id,omitempty"`
+	ClaimedAt       *time.Time `json:"claimed_at,omitempty"`
+	ClaimExpiresAt  *time.Time `json:"claim_expires_at,omitempty"`
+	ResolutionNotes *string    `json:"resolution_notes,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	ResolvedAt      *time.Time `json:"resolved_at,omitempty"`
+	FlagCount       int        `json:"flag_count"`
+
+	// Populated when fetching a single case
+	Listing *Listing         `json:"listing,omitempty"`
+	Flags   []FlaggedListing `json:"flags,omitempty"`
+}
+
+// AssignCaseParams assigns a case to a specific admin
+type AssignCaseParams struct {
+	AdminUserID uuid.UUID `json:"admin_user_id"`
+}
+
+// ResolveCaseParams closes a case and all of its flags.
+// Status must be RESOLVED or DISMISSED.
+type ResolveCaseParams struct {
+	Status          FlagStatus `json:"status"`
+	ResolutionNotes *string    `json:"resolution_notes,omitempty"`
+}
+
+// AdminWorkload is one admin's moderation stats
+type AdminWorkload struct {
+	AdminUserID    uuid.UUID  `json:"admin_user_id"`
+	UserName       string     `json:"user_name"`
+	ActiveClaims   int        `json:"active_claims"`
+	ResolvedCases  int        `json:"resolved_cases"`
+	DismissedCases int        `json:"dismissed_cases"`
+	LastClosedAt   *time.Time `json:"last_closed_at,omitempty"`
+}
+
+// BulkFlagAction is what a bulk moderation request does to each selected flag
+type BulkFlagAction string
+
+const (
+	BulkDismiss           BulkFlagAction = "DISMISS"
+	BulkResolve           BulkFlagAction = "RESOLVE"
+	BulkResolveAndArchive BulkFlagAction = "RESOLVE_AND_ARCHIVE" // also archives the flagged listing
+
+	MaxBulkFlags = 500 // most flags one bulk request may touch
+)
+
+// BulkFlagFilter selects flags by attribute. Status defaults to the open statuses.
+type BulkFlagFilter struct {
+	Reason         *FlagReason `json:"reason,omitempty"`
+	Status         *FlagStatus `json:"status,omitempty"`
+	CreatedAfter   *time.Time  `json:"created_after,omitempty"`
+	CreatedBefore  *time.Time  `json:"created_before,omitempty"`
+	ListingOwnerID *uuid.UUID  `json:"listing_owner_id,omitempty"`
+}
+
+// BulkFlagParams applies Action to the flags in FlagIDs, or to every flag matching
+// Filter when FlagIDs is empty
+type BulkFlagParams struct {
+	FlagIDs         []int64         `json:"flag_ids,omitempty"`
+	Filter          *BulkFlagFilter `json:"filter,omitempty"`
+	Action          BulkFlagAction  `json:"action"`
+	ResolutionNotes *string         `json:"resolution_notes,omitempty"`
+}
+
+// Per-item outcomes of a bulk moderation request
+const (
+	BulkItemUpdated  = "updated"
+	BulkItemSkipped  = "skipped"
+	BulkItemNotFound = "not_found"
+)
+
+// BulkFlagItemResult is the outcome for one flag. Flag is set when it was updated.
+type BulkFlagItemResult struct {
+	FlagID          int64           `json:"flag_id"`
+	ListingID       int64           `json:"listing_id,omitempty"`
+	Result          string          `json:"result"`
+	Message         string          `json:"message,omitempty"`
+	ListingArchived bool            `json:"listing_archived,omitempty"`
+	Flag            *FlaggedListing `json:"flag,omitempty"`
+}
+
+// BulkFlagResult sums up a bulk moderation request
+type BulkFlagResult struct {
+	BulkActionID uuid.UUID            `json:"bulk_action_id"`
+	Action       BulkFlagAction       `json:"action"`
+	Updated      int                  `json:"updated"`
+	Skipped      int                  `json:"skipped"`
+	Results      []BulkFlagItemResult `json:"results"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OfferStatus string
+type OfferAction string
+
+const (
+	OfferPending   OfferStatus = "PENDING"
+	OfferAccepted  OfferStatus = "ACCEPTED"
+	OfferRejected  OfferStatus = "REJECTED"
+	OfferCountered OfferStatus = "COUNTERED"
+	OfferDeclined  OfferStatus = "DECLINED"
+	OfferExpired   OfferStatus = "EXPIRED"
+
+	OfferActionAccept  OfferAction = "accept"
+	OfferActionReject  OfferAction = "reject"
+	OfferActionCounter OfferAction = "counter"
+)
+
+const DefaultOfferTTL = 48 * time.Hour // how long an offer stays open when no expiry is given
+const MaxOfferTTL = 7 * 24 * time.Hour
+
+// Offer is one step of a buyer/seller negotiation on a listing
+type Offer struct {
+	ID              int64       `json:"id"`
+	ListingID       int64       `json:"listing_id"`
+	BuyerUserID     uuid.UUID   `json:"buyer_user_id"`
+	SellerUserID    uuid.UUID   `json:"seller_user_id"`
+	CreatedByUserID uuid.UUID   `json:"created_by_user_id"`
+	ParentOfferID   *int64      `json:"parent_offer_id,omitempty"`
+	Amount          int64       `json:"amount"`
+	Message         *string     `json:"message,omitempty"`
+	Status          OfferStatus `json:"status"`
+	ExpiresAt       time.Time   `json:"expires_at"`
+	CreatedAt       time.Time   `json:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at"`
+	RespondedAt     *time.Time  `json:"responded_at,omitempty"`
+}
+
+// CreateOfferParams represents the parameters for making an offer on a listing
+type CreateOfferParams struct {
+	Amount         int64   `json:"amount"`
+	Message        *string `json:"message,omitempty"`
+	ExpiresInHours *int    `json:"expires_in_hours,omitempty"`
+}
+
+// RespondOfferParams represents the other party's answer to an offer.
+// Amount is required when Action is "counter".
+type RespondOfferParams struct {
+	Action         OfferAction `json:"action"`
+	Amount         *int64      `json:"amount,omitempty"`
+	Message        *string     `json:"message,omitempty"`
+	ExpiresInHours *int        `json:"expires_in_hours,omitempty"`
+}
+
+// OfferResponse is the outcome of responding to an offer
+type OfferResponse struct {
+	Offer    Offer   `json:"offer"`
+	Counter  *Offer  `json:"counter,omitempty"`  // set when Action is "counter"
+	Declined []Offer `json:"declined,omitempty"` // competing offers closed by an accept
+}
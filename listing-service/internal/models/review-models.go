@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReviewerRole string
+
+const (
+	ReviewerBuyer  ReviewerRole = "BUYER"
+	ReviewerSeller ReviewerRole = "SELLER"
+)
+
+// Review is one party's rating of the other after a completed sale
+type Review struct {
+	ID             int64        `json:"id"`
+	TransactionID  int64        `json:"transaction_id"`
+	ListingID      int64        `json:"listing_id"`
+	ReviewerUserID uuid.UUID    `json:"reviewer_user_id"`
+	RevieweeUserID uuid.UUID    `json:"reviewee_user_id"`
+	ReviewerRole   ReviewerRole `json:"reviewer_role"`
+	Rating         int          `json:"rating"`
+	Comment        *string      `json:"comment,omitempty"`
+	CreatedAt      time.Time    `json:"created_at"`
+}
+
+// CreateReviewParams represents the parameters for reviewing the other party of a sale
+type CreateReviewParams struct {
+	Rating  int     `json:"rating"` // 1-5
+	Comment *string `json:"comment,omitempty"`
+}
+
+// RatingSummary is the aggregate of the reviews a user has received
+type RatingSummary struct {
+	Average     float64 `json:"average"` // 0 when ReviewCount is 0
+	ReviewCount int     `json:"review_count"`
+}
+
+// SellerInfo is the seller reputation embedded in listing responses
+type SellerInfo struct {
+	UserID uuid.UUID     `json:"user_id"`
+	Rating RatingSummary `json:"rating"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RevisionAction string
+
+const (
+	RevisionBaseline RevisionAction = "BASELINE" // state when history tracking was introduced
+	RevisionCreated  RevisionAction = "CREATED"
+	RevisionUpdated  RevisionAction = "UPDATED"
+	RevisionDeleted  RevisionAction = "DELETED"
+)
+
+// FieldChange is one field's value before and after a revision
+type FieldChange struct {
+	Old json.RawMessage `json:"old"`
+	New json.RawMessage `json:"new"`
+}
+
+// ListingSnapshot holds the tracked fields of a listing at a revision
+type ListingSnapshot struct {
+	Title       string   `json:"title"`
+	Description *string  `json:"description,omitempty"`
+	Price       int64    `json:"price"`
+	Category    Category `json:"category"`
+	Status      Status   `json:"status"`
+}
+
+// ListingRevision is an immutable record of one change to a listing.
+// Revisions are written by a database trigger; see database/14-listing-revisions.sql.
+type ListingRevision struct {
+	ID              int64                  `json:"id"`
+	ListingID       int64                  `json:"listing_id"`
+	Revision        int                    `json:"revision"`
+	Action          RevisionAction         `json:"action"`
+	ChangedByUserID *uuid.UUID             `json:"changed_by_user_id,omitempty"` // nil for system changes
+	Changes         map[string]FieldChange `json:"changes"`
+	Snapshot        ListingSnapshot        `json:"snapshot"`
+	CreatedAt       time.Time              `json:"created_at"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const MaxSavedSearchesPerUser = 20
+
+// SavedSearch is a named set of listing filters a user is alerted about
+// when a new listing matches
+type SavedSearch struct {
+	ID        int64     `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Name      string    `json:"name"`
+	Query     *string   `json:"query,omitempty"`
+	Keywords  []string  `json:"keywords"`
+	Category  *Category `json:"category,omitempty"`
+	MinPrice  *int64    `json:"min_price,omitempty"`
+	MaxPrice  *int64    `json:"max_price,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SavedSearchParams represents the parameters for saving a search
+type SavedSearchParams struct {
+	Name     string    `json:"name"`
+	Query    *string   `json:"query,omitempty"`
+	Keywords []string  `json:"keywords,omitempty"`
+	Category *Category `json:"category,omitempty"`
+	MinPrice *int64    `json:"min_price,omitempty"`
+	MaxPrice *int64    `json:"max_price,omitempty"`
+}
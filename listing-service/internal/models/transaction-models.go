@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TransactionStatus string
+
+const (
+	TxPendingConfirmation TransactionStatus = "PENDING_CONFIRMATION"
+	TxCompleted           TransactionStatus = "COMPLETED"
+	TxCancelled           TransactionStatus = "CANCELLED"
+)
+
+// Transaction records the sale of a listing from seller to buyer
+type Transaction struct {
+	ID           int64             `json:"id"`
+	ListingID    int64             `json:"listing_id"`
+	SellerUserID uuid.UUID         `json:"seller_user_id"`
+	BuyerUserID  uuid.UUID         `json:"buyer_user_id"`
+	OfferID      *int64            `json:"offer_id,omitempty"`
+	FinalPrice   int64             `json:"final_price"`
+	Status       TransactionStatus `json:"status"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+	CompletedAt  *time.Time        `json:"completed_at,omitempty"`
+	CancelledAt  *time.Time        `json:"cancelled_at,omitempty"`
+}
+
+// MarkSoldParams represents the seller marking a listing sold to a buyer.
+// FinalPrice defaults to the buyer's accepted offer, then to the listing price.
+type MarkSoldParams struct {
+	BuyerUserID uuid.UUID `json:"buyer_user_id"`
+	FinalPrice  *int64    `json:"final_price,omitempty"`
+}
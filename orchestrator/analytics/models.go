@@ -2,10 +2,12 @@ package analytics
 
 // OverviewStats represents the main dashboard overview statistics
 type OverviewStats struct {
-	TotalUsers    int `json:"total_users"`
-	TotalListings int `json:"total_listings"`
-	OpenFlags     int `json:"open_flags"`
-	TotalFlags    int `json:"total_flags"`
+	TotalUsers     int   `json:"total_users"`
+	TotalListings  int   `json:"total_listings"`
+	OpenFlags      int   `json:"open_flags"`
+	TotalFlags     int   `json:"total_flags"`
+	CompletedSales int   `json:"completed_sales"`
+	GMV            int64 `json:"gmv"` // sum of completed sale prices, smallest unit (e.g., cents)
 }
 
 // ListingsByStatus represents listings grouped by status
@@ -11,6 +11,7 @@ type Repository interface {
 	GetTotalListings(ctx context.Context) (int, error)
 	GetOpenFlags(ctx context.Context) (int, error)
 	GetTotalFlags(ctx context.Context) (int, error)
+	GetSalesSummary(ctx context.Context) (int, int64, error)
 	GetListingsByStatus(ctx context.Context) ([]ListingsByStatus, error)
 	GetListingsByCategory(ctx context.Context) ([]ListingsByCategory, error)
 	GetFlagsByStatus(ctx context.Context) ([]FlagsByStatus, error)
@@ -51,6 +52,14 @@ func (r *repo) GetTotalFlags(ctx context.Context) (int, error) {
 	return count, err
 }
 
+// GetSalesSummary returns the number of completed sales and their total value (GMV)
+func (r *repo) GetSalesSummary(ctx context.Context) (int, int64, error) {
+	var count int
+	var gmv int64
+	err := r.db.QueryRow(ctx, "SELECT COUNT(*), COALESCE(SUM(final_price), 0) FROM transactions WHERE status = 'COMPLETED'").Scan(&count, &gmv)
+	return count, gmv, err
+}
+
 func (r *repo) GetListingsByStatus(ctx context.Context) ([]ListingsByStatus, error) {
 	rows, err := r.db.Query(ctx, `
 		SELECT status, COUNT(*) as count
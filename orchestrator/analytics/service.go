@@ -38,6 +38,11 @@ func (s *service) GetAnalytics(ctx context.Context) (*AnalyticsResponse, error)
 		return nil, err
 	}
 
+	completedSales, gmv, err := s.repo.GetSalesSummary(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	listingsByStatus, err := s.repo.GetListingsByStatus(ctx)
 	if err != nil {
 		return nil, err
@@ -74,10 +79,12 @@ func (s *service) GetAnalytics(ctx context.Context) (*AnalyticsResponse, error)
 
 	return &AnalyticsResponse{
 		Overview: OverviewStats{
-			TotalUsers:    totalUsers,
-			TotalListings: totalListings,
-			OpenFlags:     openFlags,
-			TotalFlags:    totalFlags,
+			TotalUsers:     totalUsers,
+			TotalListings:  totalListings,
+			OpenFlags:      openFlags,
+			TotalFlags:     totalFlags,
+			CompletedSales: completedSales,
+			GMV:            gmv,
 		},
 		ListingsByStatus:   listingsByStatus,
 		ListingsByCategory: listingsByCategory,
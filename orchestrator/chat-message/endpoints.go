@@ -2,14 +2,19 @@ package chatmessage
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	httplib "github.com/kunal768/cmpe202/http-lib"
+	"github.com/kunal768/cmpe202/orchestrator/common"
 )
 
 type Endpoints struct {
@@ -177,8 +182,22 @@ func (e *Endpoints) GetMessagesHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// ?listing_id= narrows the history to that listing's thread
+	var listingID *int64
+	if raw := r.URL.Query().Get("listing_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid request",
+				Message: "listing_id must be a positive integer",
+			})
+			return
+		}
+		listingID = &id
+	}
+
 	// Call service to get messages
-	messages, err := e.service.GetMessages(r.Context(), userID, otherUserID)
+	messages, err := e.service.GetMessages(r.Context(), userID, otherUserID, listingID)
 	if err != nil {
 		if err.Error() == "mongo client not configured" {
 			httplib.WriteJSON(w, http.StatusOK, GetMessagesResponse{
@@ -237,21 +256,115 @@ func (e *Endpoints) GetConversationsWithUndeliveredCountHandler(w http.ResponseW
 	httplib.WriteJSON(w, http.StatusOK, response)
 }
 
+// MessageSellerHandler opens the authenticated user's thread with the seller of a listing
+func (e *Endpoints) MessageSellerHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(httplib.ContextKey("userId")).(string)
+	if !ok {
+		httplib.WriteJSON(w, http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User ID not found in context",
+		})
+		return
+	}
+
+	listingID, err := strconv.ParseInt(r.PathValue("listing_id"), 10, 64)
+	if err != nil || listingID <= 0 {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: "listing_id must be a positive integer",
+		})
+		return
+	}
+
+	// The body is optional
+	var req MessageSellerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: "Invalid JSON body",
+		})
+		return
+	}
+
+	response, err := e.service.MessageSeller(r.Context(), userID, listingID, req.Message)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, ErrListingNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, ErrOwnListing):
+			status = http.StatusBadRequest
+		case errors.Is(err, ErrListingUnavailable):
+			status = http.StatusConflict
+		case errors.Is(err, ErrChatUnavailable):
+			status = http.StatusServiceUnavailable
+		}
+		httplib.WriteJSON(w, status, ErrorResponse{
+			Error:   "Failed to message seller",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, response)
+}
+
+// CreateAttachmentUploadHandler issues an upload URL for an image to send in chat
+func (e *Endpoints) CreateAttachmentUploadHandler(w http.ResponseWriter, r *http.Request) {
+	var req CreateAttachmentUploadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: "Invalid JSON body",
+		})
+		return
+	}
+	if req.ContentType == "" || req.Size <= 0 {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Message: "contentType and size are required",
+		})
+		return
+	}
+
+	response, err := e.service.CreateAttachmentUpload(r.Context(), req)
+	if err != nil {
+		httplib.WriteJSON(w, common.MapToHTTPStatus(err), ErrorResponse{
+			Error:   "Failed to create attachment upload",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, response)
+}
+
 // RegisterRoutes registers all chat routes with proper middleware
 func (e *Endpoints) RegisterRoutes(mux *http.ServeMux, dbPool *pgxpool.Pool) {
-	// Undelivered messages endpoint (requires auth but not role injection)
-	mux.Handle("GET /api/chat/undelivered-messages", httplib.AuthMiddleWare(http.HandlerFunc(e.GetUndeliveredMessagesHandler)))
+	// Chat is only open to users who verified their campus email
+	verified := func(h http.Handler) http.Handler {
+		return httplib.AuthMiddleWare(httplib.VerifiedEmailMiddleWare(dbPool)(h))
+	}
+
+	// Undelivered messages endpoint (requires auth and a verified email)
+	mux.Handle("GET /api/chat/undelivered-messages", verified(http.HandlerFunc(e.GetUndeliveredMessagesHandler)))
+
+	// Fetch and republish undelivered messages endpoint (requires auth and a verified email)
+	mux.Handle("POST /api/chat/fetch-undelivered", verified(http.HandlerFunc(e.FetchAndRepublishUndeliveredMessagesHandler)))
+
+	// Get conversations endpoint (requires auth and a verified email)
+	mux.Handle("GET /api/chat/conversations", verified(http.HandlerFunc(e.GetConversationsHandler)))
 
-	// Fetch and republish undelivered messages endpoint (requires auth but not role injection)
-	mux.Handle("POST /api/chat/fetch-undelivered", httplib.AuthMiddleWare(http.HandlerFunc(e.FetchAndRepublishUndeliveredMessagesHandler)))
+	// Get messages endpoint (requires auth and a verified email)
+	mux.Handle("GET /api/chat/messages/", verified(http.HandlerFunc(e.GetMessagesHandler)))
 
-	// Get conversations endpoint (requires auth but not role injection)
-	mux.Handle("GET /api/chat/conversations", httplib.AuthMiddleWare(http.HandlerFunc(e.GetConversationsHandler)))
+	// Message seller endpoint: opens the listing thread with a listing card (requires auth and a verified email)
+	mux.Handle("POST /api/chat/listings/{listing_id}/message", verified(http.HandlerFunc(e.MessageSellerHandler)))
 
-	// Get messages endpoint (requires auth but not role injection)
-	mux.Handle("GET /api/chat/messages/", httplib.AuthMiddleWare(http.HandlerFunc(e.GetMessagesHandler)))
+	// Attachment upload endpoint: the listing service issues the URL on behalf of the user's role
+	mux.Handle("POST /api/chat/attachments", verified(httplib.RoleInjectionMiddleWare(dbPool)(http.HandlerFunc(e.CreateAttachmentUploadHandler))))
 
-	// Get conversations with undelivered count endpoint (requires auth but not role injection)
-	mux.Handle("GET /api/chat/conversations-with-undelivered-count", httplib.AuthMiddleWare(http.HandlerFunc(e.GetConversationsWithUndeliveredCountHandler)))
+	// Get conversations with undelivered count endpoint (requires auth and a verified email)
+	mux.Handle("GET /api/chat/conversations-with-undelivered-count", verified(http.HandlerFunc(e.GetConversationsWithUndeliveredCountHandler)))
 }
 
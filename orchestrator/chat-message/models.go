@@ -13,8 +13,27 @@ const (
 	StatusSent        MessageStatus = "SENT"
 	StatusDelivered   MessageStatus = "DELIVERED"
 	StatusUndelivered MessageStatus = "UNDELIVERED"
+	StatusRead        MessageStatus = "READ"
 )
 
+// Message types besides plain "text"
+const (
+	// TypeListingCard opens a listing thread; its content is the listing title
+	TypeListingCard = "listing_card"
+	// TypeImage carries an Attachment; its content is an optional caption
+	TypeImage = "image"
+)
+
+// Attachment is an uploaded file sent in chat, as inspected by chat-consumer
+type Attachment struct {
+	BlobName    string `bson:"blobName" json:"blobName"`
+	URL         string `bson:"url" json:"url"`
+	ContentType string `bson:"contentType" json:"contentType"`
+	Size        int64  `bson:"size" json:"size"`
+	Width       int    `bson:"width,omitempty" json:"width,omitempty"`
+	Height      int    `bson:"height,omitempty" json:"height,omitempty"`
+}
+
 // ChatMessage represents a chat message with delivery status
 type ChatMessage struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
@@ -27,16 +46,38 @@ type ChatMessage struct {
 	Status      MessageStatus      `bson:"status" json:"status"`
 	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`
 	UpdatedAt   time.Time          `bson:"updatedAt" json:"updatedAt"`
+	ReadAt      *time.Time         `bson:"readAt,omitempty" json:"readAt,omitempty"`
+
+	// ListingID is the listing the thread is about; nil for the general thread
+	ListingID *int64 `bson:"listingId,omitempty" json:"listingId,omitempty"`
+
+	// Attachment is set on image messages
+	Attachment *Attachment `bson:"attachment,omitempty" json:"attachment,omitempty"`
+
+	// Listing is set on listing cards: the listing as it was when the thread opened
+	Listing *ListingCard `bson:"listing,omitempty" json:"listing,omitempty"`
 }
 
-// Conversation represents a conversation preview with another user
+// ListingCard is what a listing thread shows of its listing
+type ListingCard struct {
+	Title     string `bson:"title" json:"title"`
+	Thumbnail string `bson:"thumbnail,omitempty" json:"thumbnail,omitempty"`
+}
+
+// Conversation represents a conversation preview with another user. A pair of users
+// has one general thread plus one per listing they have talked about.
 type Conversation struct {
 	OtherUserID   string    `json:"otherUserId"`
 	OtherUserName string    `json:"otherUserName,omitempty"`
 	LastMessage   string    `json:"lastMessage"`
 	LastTimestamp time.Time `json:"lastTimestamp"`
-	UnreadCount   int       `json:"unreadCount"`
+	UnreadCount   int       `json:"unreadCount"` // messages to the user they haven't read yet
 	IsLastFromMe  bool      `json:"isLastFromMe"` // true if last message was sent by current user
+
+	// The listing the thread is about, if any
+	ListingID        *int64 `json:"listingId,omitempty"`
+	ListingTitle     string `json:"listingTitle,omitempty"`
+	ListingThumbnail string `json:"listingThumbnail,omitempty"`
 }
 
 // ConversationSummary represents a summary of all conversations
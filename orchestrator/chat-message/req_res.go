@@ -24,6 +24,33 @@ type GetMessagesResponse struct {
 	Count    int            `json:"count"`
 }
 
+// Message Seller Request: the optional first message sent after the listing card
+type MessageSellerRequest struct {
+	Message string `json:"message"`
+}
+
+// Message Seller Response: the thread to open
+type MessageSellerResponse struct {
+	OtherUserID string   `json:"otherUserId"`
+	ListingID   int64    `json:"listingId"`
+	Seeded      bool     `json:"seeded"` // true if the listing card was sent now
+	MessageIDs  []string `json:"messageIds"`
+}
+
+// Create Attachment Upload Request: the image about to be uploaded
+type CreateAttachmentUploadRequest struct {
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+}
+
+// Create Attachment Upload Response: PUT the file to UploadURL, then send an image
+// message with BlobName
+type CreateAttachmentUploadResponse struct {
+	UploadURL string `json:"uploadUrl"`
+	URL       string `json:"url"`
+	BlobName  string `json:"blobName"`
+}
+
 // Get Conversations With Undelivered Count Response
 type GetConversationsWithUndeliveredCountResponse struct {
 	Count int `json:"count"`
@@ -3,32 +3,60 @@ package chatmessage
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/kunal768/cmpe202/orchestrator/internal/queue"
+	"github.com/kunal768/cmpe202/orchestrator/listings"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+var (
+	ErrListingNotFound    = errors.New("listing not found")
+	ErrOwnListing         = errors.New("cannot message yourself about your own listing")
+	ErrListingUnavailable = errors.New("listing is no longer available")
+	ErrChatUnavailable    = errors.New("chat queue not configured")
+)
+
+// ListingLookup is the part of the listings service chat needs to describe threads
+type ListingLookup interface {
+	FetchListing(ctx context.Context, req listings.FetchListingRequest) (*listings.FetchListingResponse, error)
+	FetchMediaURLs(ctx context.Context, listingID int64) (*listings.FetchMediaURLsResponse, error)
+}
+
+// AttachmentUploader issues upload URLs for chat attachments
+type AttachmentUploader interface {
+	CreateChatAttachment(ctx context.Context, req listings.CreateChatAttachmentRequest) (*listings.UploadSASResponse, error)
+}
+
 type svc struct {
 	mongoClient *mongo.Client
 	publisher   queue.Publisher
+	listings    ListingLookup
+	uploader    AttachmentUploader
 }
 
 type Service interface {
 	FetchUndeliveredMessages(ctx context.Context, recipientID string) ([]map[string]interface{}, error)
 	GetConversations(ctx context.Context, userID string) ([]Conversation, error)
-	GetMessages(ctx context.Context, userID, otherUserID string) ([]ChatMessage, error)
+	GetMessages(ctx context.Context, userID, otherUserID string, listingID *int64) ([]ChatMessage, error)
 	GetConversationsWithUndeliveredCount(ctx context.Context, userID string) (int, error)
+	MessageSeller(ctx context.Context, buyerID string, listingID int64, text string) (*MessageSellerResponse, error)
+	CreateAttachmentUpload(ctx context.Context, req CreateAttachmentUploadRequest) (*CreateAttachmentUploadResponse, error)
 }
 
-func NewChatService(mongoClient *mongo.Client, publisher queue.Publisher) Service {
+func NewChatService(mongoClient *mongo.Client, publisher queue.Publisher, listingLookup ListingLookup, uploader AttachmentUploader) Service {
 	return &svc{
 		mongoClient: mongoClient,
 		publisher:   publisher,
+		listings:    listingLookup,
+		uploader:    uploader,
 	}
 }
 
@@ -91,6 +119,15 @@ func (s *svc) FetchUndeliveredMessages(ctx context.Context, recipientID string)
 				"timestamp":   m["timestamp"],
 				"type":        m["type"],
 			}
+			if listingID, ok := m["listingId"]; ok {
+				messageForQueue["listingId"] = listingID
+			}
+			if attachment, ok := m["attachment"]; ok {
+				messageForQueue["attachment"] = attachment
+			}
+			if listing, ok := m["listing"]; ok {
+				messageForQueue["listing"] = listing
+			}
 
 			msgStart := time.Now()
 			b, err := json.Marshal(messageForQueue)
@@ -116,7 +153,25 @@ func (s *svc) FetchUndeliveredMessages(ctx context.Context, recipientID string)
 	return results, nil
 }
 
-// GetConversations returns all conversations for a user, sorted by most recent message
+// conversationKey identifies a thread: the other user and the listing (0 for none)
+type conversationKey struct {
+	otherUserID string
+	listingID   int64
+}
+
+func threadKey(userID string, msg ChatMessage) conversationKey {
+	key := conversationKey{otherUserID: msg.SenderID}
+	if msg.SenderID == userID {
+		key.otherUserID = msg.RecipientID
+	}
+	if msg.ListingID != nil {
+		key.listingID = *msg.ListingID
+	}
+	return key
+}
+
+// GetConversations returns all conversations for a user, one per other user and listing,
+// sorted by most recent message
 func (s *svc) GetConversations(ctx context.Context, userID string) ([]Conversation, error) {
 	if s.mongoClient == nil {
 		return nil, fmt.Errorf("mongo client not configured")
@@ -140,8 +195,8 @@ func (s *svc) GetConversations(ctx context.Context, userID string) ([]Conversati
 	}
 	defer cur.Close(ctx)
 
-	// Map to track conversations by other user ID
-	conversationsMap := make(map[string]*Conversation)
+	// Map to track conversations by other user ID and listing
+	conversationsMap := make(map[conversationKey]*Conversation)
 	// Track all messages for counting unread later
 	allMessages := make([]ChatMessage, 0)
 
@@ -152,41 +207,37 @@ func (s *svc) GetConversations(ctx context.Context, userID string) ([]Conversati
 		}
 		allMessages = append(allMessages, msg)
 
-		// Determine the other user ID
-		var otherUserID string
-		var isLastFromMe bool
-		if msg.SenderID == userID {
-			otherUserID = msg.RecipientID
-			isLastFromMe = true
-		} else {
-			otherUserID = msg.SenderID
-			isLastFromMe = false
-		}
+		key := threadKey(userID, msg)
 
 		// If we haven't seen this conversation yet, create it
 		// Since messages are sorted by timestamp descending, first message we see is the most recent
-		if _, exists := conversationsMap[otherUserID]; !exists {
-			conversationsMap[otherUserID] = &Conversation{
-				OtherUserID:   otherUserID,
+		if _, exists := conversationsMap[key]; !exists {
+			conversationsMap[key] = &Conversation{
+				OtherUserID:   key.otherUserID,
 				LastMessage:   msg.Content,
 				LastTimestamp: msg.Timestamp,
 				UnreadCount:   0, // Will be calculated below
-				IsLastFromMe:  isLastFromMe,
+				IsLastFromMe:  msg.SenderID == userID,
+				ListingID:     msg.ListingID,
 			}
 		}
-	}
 
-	// Count undelivered messages for each conversation
-	for _, msg := range allMessages {
-		var otherUserID string
-		if msg.SenderID == userID {
-			otherUserID = msg.RecipientID
-		} else {
-			otherUserID = msg.SenderID
+		// The card that opened a listing thread carries its preview, so the list needs no
+		// listing lookups. Cards stored without one still have the title as content.
+		// Messages come newest first, so the newest card wins.
+		if conv := conversationsMap[key]; msg.Type == TypeListingCard && conv.ListingTitle == "" {
+			conv.ListingTitle = msg.Content
+			if msg.Listing != nil {
+				conv.ListingTitle = msg.Listing.Title
+				conv.ListingThumbnail = msg.Listing.Thumbnail
+			}
 		}
+	}
 
-		if conv, exists := conversationsMap[otherUserID]; exists {
-			if msg.RecipientID == userID && msg.Status == StatusUndelivered {
+	// Count messages to the user that they haven't read yet
+	for _, msg := range allMessages {
+		if conv, exists := conversationsMap[threadKey(userID, msg)]; exists {
+			if msg.RecipientID == userID && msg.Status != StatusRead {
 				conv.UnreadCount++
 			}
 		}
@@ -210,8 +261,9 @@ func (s *svc) GetConversations(ctx context.Context, userID string) ([]Conversati
 	return conversations, nil
 }
 
-// GetMessages returns all messages between two users, sorted chronologically
-func (s *svc) GetMessages(ctx context.Context, userID, otherUserID string) ([]ChatMessage, error) {
+// GetMessages returns one thread between two users, sorted chronologically: the listing's
+// thread when listingID is set, and their general thread otherwise.
+func (s *svc) GetMessages(ctx context.Context, userID, otherUserID string, listingID *int64) ([]ChatMessage, error) {
 	if s.mongoClient == nil {
 		return nil, fmt.Errorf("mongo client not configured")
 	}
@@ -225,6 +277,11 @@ func (s *svc) GetMessages(ctx context.Context, userID, otherUserID string) ([]Ch
 			{"senderId": otherUserID, "recipientId": userID},
 		},
 	}
+	if listingID != nil {
+		filter["listingId"] = *listingID
+	} else {
+		filter["listingId"] = bson.M{"$exists": false}
+	}
 
 	// Sort by timestamp ascending for chronological display
 	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}})
@@ -269,3 +326,130 @@ func (s *svc) GetConversationsWithUndeliveredCount(ctx context.Context, userID s
 	return len(senderIDs), nil
 }
 
+
+// queuedMessage is a chat message in the format events-server puts on the chat queue
+type queuedMessage struct {
+	MessageID   string       `json:"messageId"`
+	SenderID    string       `json:"senderId"`
+	RecipientID string       `json:"recipientId"`
+	Content     string       `json:"content"`
+	Timestamp   time.Time    `json:"timestamp"`
+	Type        string       `json:"type"`
+	ListingID   *int64       `json:"listingId,omitempty"`
+	Listing     *ListingCard `json:"listing,omitempty"`
+}
+
+// MessageSeller opens the buyer's thread with the seller of a listing. A new thread starts
+// with a listing card; text, if any, is sent after it. Messages go through the chat queue
+// so they are screened, stored and delivered like any other.
+func (s *svc) MessageSeller(ctx context.Context, buyerID string, listingID int64, text string) (*MessageSellerResponse, error) {
+	if s.publisher == nil {
+		return nil, ErrChatUnavailable
+	}
+	if s.listings == nil {
+		return nil, fmt.Errorf("listing service not configured")
+	}
+
+	resp, err := s.listings.FetchListing(ctx, listings.FetchListingRequest{ID: listingID})
+	if err != nil || resp.Listing == nil {
+		return nil, fmt.Errorf("%w: %v", ErrListingNotFound, err)
+	}
+	listing := resp.Listing
+	sellerID := listing.UserID.String()
+	if sellerID == buyerID {
+		return nil, ErrOwnListing
+	}
+
+	// A thread that already exists is just opened; only new ones need the card
+	exists, err := s.threadExists(ctx, buyerID, sellerID, listingID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists && listing.Status != listings.StAvailable && listing.Status != listings.StPending {
+		return nil, ErrListingUnavailable
+	}
+
+	now := time.Now().UTC()
+	var msgs []queuedMessage
+	if !exists {
+		// The card keeps the listing's title and first photo, so the conversation list
+		// can show them without asking the listing service
+		card := &ListingCard{Title: listing.Title}
+		if media, err := s.listings.FetchMediaURLs(ctx, listingID); err == nil && len(media.Media) > 0 {
+			card.Thumbnail = media.Media[0].MediaURL
+		}
+		msgs = append(msgs, queuedMessage{
+			SenderID:    buyerID,
+			RecipientID: sellerID,
+			Content:     listing.Title,
+			Timestamp:   now,
+			Type:        TypeListingCard,
+			Listing:     card,
+		})
+	}
+	if text = strings.TrimSpace(text); text != "" {
+		msgs = append(msgs, queuedMessage{
+			SenderID:    buyerID,
+			RecipientID: sellerID,
+			Content:     text,
+			// Keep the text after the card in the thread
+			Timestamp: now.Add(time.Millisecond),
+			Type:      "text",
+		})
+	}
+
+	out := &MessageSellerResponse{OtherUserID: sellerID, ListingID: listingID, Seeded: !exists, MessageIDs: []string{}}
+	for _, m := range msgs {
+		m.MessageID = uuid.NewString()
+		m.ListingID = &listingID
+		b, err := json.Marshal(m)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal message: %w", err)
+		}
+		if err := s.publisher.Publish(ctx, b); err != nil {
+			return nil, fmt.Errorf("failed to queue message: %w", err)
+		}
+		out.MessageIDs = append(out.MessageIDs, m.MessageID)
+	}
+	return out, nil
+}
+
+// threadExists reports whether two users have exchanged messages about a listing
+func (s *svc) threadExists(ctx context.Context, userID, otherUserID string, listingID int64) (bool, error) {
+	if s.mongoClient == nil {
+		return false, nil
+	}
+	coll := s.mongoClient.Database("chatdb").Collection("chatmessages")
+	filter := bson.M{
+		"$or": []bson.M{
+			{"senderId": userID, "recipientId": otherUserID},
+			{"senderId": otherUserID, "recipientId": userID},
+		},
+		"listingId": listingID,
+	}
+	n, err := coll.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	if err != nil {
+		return false, fmt.Errorf("failed to look up thread: %w", err)
+	}
+	return n > 0, nil
+}
+
+// CreateAttachmentUpload returns where to upload an image before sending it. The image
+// message then references the returned blob name.
+func (s *svc) CreateAttachmentUpload(ctx context.Context, req CreateAttachmentUploadRequest) (*CreateAttachmentUploadResponse, error) {
+	if s.uploader == nil {
+		return nil, fmt.Errorf("attachment uploads not configured")
+	}
+	upload, err := s.uploader.CreateChatAttachment(ctx, listings.CreateChatAttachmentRequest{
+		ContentType: req.ContentType,
+		Size:        req.Size,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &CreateAttachmentUploadResponse{
+		UploadURL: upload.SASURL,
+		URL:       upload.PermanentPublicURL,
+		BlobName:  upload.BlobName,
+	}, nil
+}
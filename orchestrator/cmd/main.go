@@ -6,6 +6,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 
 	"github.com/joho/godotenv"
 	httplib "github.com/kunal768/cmpe202/http-lib"
@@ -13,8 +14,11 @@ import (
 	chatmessage "github.com/kunal768/cmpe202/orchestrator/chat-message"
 	dbclient "github.com/kunal768/cmpe202/orchestrator/clients/db"
 	mongoclient "github.com/kunal768/cmpe202/orchestrator/clients/mongo"
+	"github.com/kunal768/cmpe202/orchestrator/internal/events"
 	"github.com/kunal768/cmpe202/orchestrator/internal/queue"
+	"github.com/kunal768/cmpe202/orchestrator/internal/sessions"
 	"github.com/kunal768/cmpe202/orchestrator/listings"
+	"github.com/kunal768/cmpe202/orchestrator/notifications"
 	"github.com/kunal768/cmpe202/orchestrator/users"
 	"go.mongodb.org/mongo-driver/mongo"
 )
@@ -38,6 +42,17 @@ func main() {
 	}
 	defer dbPool.Close()
 
+	// Access tokens are signed with JWT_SIGNING_KEY_FILE (RS256/EdDSA) when set, and
+	// with JWT_TOKEN_SECRET otherwise; see httplib.KeySetFromEnv
+	keys, err := httplib.KeySetFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to load JWT keys: %v", err)
+	}
+	httplib.UseKeySet(keys)
+
+	// Turn away suspended and banned users on every authenticated route
+	httplib.EnableAccountStateCheck(dbPool)
+
 	// Initialize user components
 	userRepo := users.NewRepository(dbPool)
 
@@ -69,20 +84,88 @@ func main() {
 		defer pub.Close()
 	}
 
+	// Setup Redis event publisher if configured (pushes events to users' WebSockets via events-server)
+	// Revoked login sessions are cached in Redis too, so every instance rejects their
+	// access tokens; without Redis only this instance knows about them
+	var eventPublisher events.Publisher
+	var presence events.PresenceChecker
+	var revocations sessions.Revocations = sessions.NewMemoryRevocations(httplib.AccessTokenTTL)
+	if redisAddr := os.Getenv("REDIS_ADDR"); redisAddr != "" {
+		redisDB, _ := strconv.Atoi(os.Getenv("REDIS_DB"))
+		pub := events.NewRedisPublisher(redisAddr, os.Getenv("REDIS_PASSWORD"), redisDB)
+		eventPublisher = pub
+		presence = pub
+		defer pub.Close()
+
+		rev := sessions.NewRedisRevocations(redisAddr, os.Getenv("REDIS_PASSWORD"), redisDB, httplib.AccessTokenTTL)
+		revocations = rev
+		defer rev.Close()
+	}
+	httplib.EnableSessionRevocationCheck(revocations)
+
+	// Create notification service (persists notifications, pushes them over Redis when possible)
+	notificationRepo := notifications.NewRepository(dbPool)
+	notificationService := notifications.NewService(notificationRepo, eventPublisher, presence)
+
+	// Setup the listing events queue if configured: listing changes are published here and
+	// consumed in the background to alert users with matching saved searches
+	var listingEventPublisher queue.Publisher
+	listingEventsQueue := os.Getenv("RABBITMQ_LISTING_EVENTS_QUEUE")
+	if rabbitURL != "" && listingEventsQueue != "" {
+		pub, err := queue.NewRabbitMQPublisher(rabbitURL, listingEventsQueue)
+		if err != nil {
+			log.Fatalf("Failed to create listing events publisher: %v", err)
+		}
+		listingEventPublisher = pub
+		defer pub.Close()
+
+		consumer, err := queue.NewRabbitMQConsumer(rabbitURL, listingEventsQueue)
+		if err != nil {
+			log.Fatalf("Failed to create listing events consumer: %v", err)
+		}
+		defer consumer.Close()
+
+		consumerCtx, cancelConsumer := context.WithCancel(context.Background())
+		defer cancelConsumer()
+		if err := consumer.Start(consumerCtx, notificationService.HandleListingEvent); err != nil {
+			log.Fatalf("Failed to start listing events consumer: %v", err)
+		}
+	}
+
+	// Setup the notifications queue if configured: other services (e.g. the chat consumer)
+	// publish notification requests here to land in a user's inbox
+	notificationsQueue := os.Getenv("RABBITMQ_NOTIFICATIONS_QUEUE")
+	if rabbitURL != "" && notificationsQueue != "" {
+		consumer, err := queue.NewRabbitMQConsumer(rabbitURL, notificationsQueue)
+		if err != nil {
+			log.Fatalf("Failed to create notifications consumer: %v", err)
+		}
+		defer consumer.Close()
+
+		consumerCtx, cancelConsumer := context.WithCancel(context.Background())
+		defer cancelConsumer()
+		if err := consumer.Start(consumerCtx, notificationService.HandleNotificationRequest); err != nil {
+			log.Fatalf("Failed to start notifications consumer: %v", err)
+		}
+	}
+	notificationEndpoints := notifications.NewEndpoints(notificationService)
+
 	// Create user service and endpoints. Publisher is no longer needed for users service.
-	userService := users.NewService(userRepo, publisher)
+	// Verification emails go out over SMTP when SMTP_ADDR is set and are logged otherwise.
+	userService := users.NewService(userRepo, publisher, users.EmailConfigFromEnv(), revocations)
 	userEndpoints := users.NewEndpoints(userService)
 
-	// Create chat service and endpoints
-	chatService := chatmessage.NewChatService(mc, publisher)
-	chatEndpoints := chatmessage.NewEndpoints(chatService)
-
 	// Create listing service and endpoints
 	baseUrl := os.Getenv("LISTING_SERVICE_URL")
 	sharedSecret := os.Getenv("LISTING_SERVICE_SHARED_SECRET")
-	listingService := listings.NewListingService(baseUrl, sharedSecret)
+	listingService := listings.NewListingService(baseUrl, sharedSecret, eventPublisher, listingEventPublisher, notificationService)
 	listingEndpoints := listings.NewEndpoints(listingService)
 
+	// Create chat service and endpoints. The listing service describes listing threads and
+	// issues attachment upload URLs.
+	chatService := chatmessage.NewChatService(mc, publisher, listingService, listingService)
+	chatEndpoints := chatmessage.NewEndpoints(chatService)
+
 	// Create analytics service and endpoints
 	analyticsRepo := analytics.NewRepository(dbPool)
 	analyticsService := analytics.NewService(analyticsRepo)
@@ -103,6 +186,12 @@ func main() {
 	// Register analytics routes with middleware
 	analyticsEndpoints.RegisterRoutes(mux, dbPool)
 
+	// Register notification inbox routes with middleware
+	notificationEndpoints.RegisterRoutes(mux, dbPool)
+
+	// Public keys for services that verify access tokens themselves (events-server)
+	mux.Handle("GET /.well-known/jwks.json", httplib.JWKSHandler(keys))
+
 	// Health check endpoint
 	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -0,0 +1,20 @@
+package events
+
+import "time"
+
+// Listing event types published to the listing events queue
+const (
+	ListingCreated      = "listing_created"
+	ListingPriceDropped = "listing_price_dropped"
+)
+
+// ListingEvent is published to RabbitMQ when a listing changes so alerts can be
+// evaluated off the request path
+type ListingEvent struct {
+	Type       string    `json:"type"`
+	ListingID  int64     `json:"listingId"`
+	SellerID   string    `json:"sellerId"`
+	OldPrice   *int64    `json:"oldPrice,omitempty"` // price events only
+	NewPrice   *int64    `json:"newPrice,omitempty"`
+	OccurredAt time.Time `json:"occurredAt"`
+}
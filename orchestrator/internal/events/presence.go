@@ -0,0 +1,26 @@
+package events
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// PresenceChecker reports whether a user is connected to the events-server
+type PresenceChecker interface {
+	IsOnline(ctx context.Context, userID string) (bool, error)
+}
+
+// IsOnline reads the presence:{id} key the events-server keeps refreshed while the
+// user's WebSocket is open
+func (p *RedisPublisher) IsOnline(ctx context.Context, userID string) (bool, error) {
+	value, err := p.client.Get(ctx, fmt.Sprintf("presence:%s", userID)).Result()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check presence for user %s: %w", userID, err)
+	}
+	return value == "ONLINE", nil
+}
@@ -0,0 +1,57 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Publisher pushes server events to a user's Redis channel (user:{id}:messages).
+// The events-server is subscribed to that channel while the user is connected and
+// forwards the payload over their WebSocket.
+type Publisher interface {
+	// PublishToUser marshals event and publishes it; it returns the number of subscribers reached
+	PublishToUser(ctx context.Context, userID string, event any) (int64, error)
+	Close() error
+}
+
+// RedisPublisher implements Publisher using Redis pub/sub
+type RedisPublisher struct {
+	client *redis.Client
+}
+
+// NewRedisPublisher creates a new Redis event publisher
+func NewRedisPublisher(addr, password string, db int) *RedisPublisher {
+	return &RedisPublisher{
+		client: redis.NewClient(&redis.Options{
+			Addr:     addr,
+			Password: password,
+			DB:       db,
+		}),
+	}
+}
+
+func (p *RedisPublisher) PublishToUser(ctx context.Context, userID string, event any) (int64, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	channel := fmt.Sprintf("user:%s:messages", userID)
+	subscribers, err := p.client.Publish(ctx, channel, payload).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to publish event to user %s: %w", userID, err)
+	}
+	log.Printf("[Events] Published event to %s (subscribers: %d)", channel, subscribers)
+	return subscribers, nil
+}
+
+func (p *RedisPublisher) Close() error {
+	if err := p.client.Close(); err != nil {
+		return fmt.Errorf("failed to close Redis client: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Handler processes one message body. Returning an error requeues the message.
+type Handler func(ctx context.Context, message []byte) error
+
+// RabbitMQConsumer consumes a durable queue and hands each message to a Handler
+type RabbitMQConsumer struct {
+	queueName string
+	conn      *amqp.Connection
+	channel   *amqp.Channel
+	mu        sync.Mutex
+	closed    bool
+}
+
+// NewRabbitMQConsumer connects to RabbitMQ and declares the queue
+func NewRabbitMQConsumer(url, queueName string) (*RabbitMQConsumer, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open channel: %w", err)
+	}
+
+	// Declare queue (durable, matching the publisher)
+	if _, err := ch.QueueDeclare(queueName, true, false, false, false, nil); err != nil {
+		ch.Close()
+		conn.Close()
+		return nil, fmt.Errorf("failed to declare queue: %w", err)
+	}
+
+	// Process one message at a time
+	if err := ch.Qos(1, 0, false); err != nil {
+		ch.Close()
+		conn.Close()
+		return nil, fmt.Errorf("failed to set QoS: %w", err)
+	}
+
+	return &RabbitMQConsumer{
+		queueName: queueName,
+		conn:      conn,
+		channel:   ch,
+	}, nil
+}
+
+// Start consumes messages in a goroutine until ctx is cancelled
+func (c *RabbitMQConsumer) Start(ctx context.Context, handle Handler) error {
+	msgs, err := c.channel.Consume(c.queueName, "", false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to register consumer: %w", err)
+	}
+
+	log.Printf("Started consuming messages from queue: %s", c.queueName)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-msgs:
+				if !ok {
+					log.Printf("Message channel closed for queue: %s", c.queueName)
+					return
+				}
+				if err := handle(ctx, msg.Body); err != nil {
+					log.Printf("Failed to handle message from %s: %v", c.queueName, err)
+					// Requeue once; a message that fails again is dropped rather than looping forever
+					if nackErr := msg.Nack(false, !msg.Redelivered); nackErr != nil {
+						log.Printf("Failed to nack message: %v", nackErr)
+					}
+					continue
+				}
+				if ackErr := msg.Ack(false); ackErr != nil {
+					log.Printf("Failed to ack message: %v", ackErr)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Close closes the channel and connection
+func (c *RabbitMQConsumer) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+
+	var err error
+	if c.channel != nil {
+		err = c.channel.Close()
+	}
+	if c.conn != nil {
+		if closeErr := c.conn.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+
+	log.Printf("RabbitMQ consumer closed: %s", c.queueName)
+	return err
+}
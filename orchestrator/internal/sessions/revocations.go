@@ -0,0 +1,106 @@
+package sessions
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Revocations remembers revoked login sessions until their last access token has
+// expired, so AuthMiddleWare can reject those tokens early. The database remains the
+// record of which sessions are revoked; this is only the fast path for access tokens.
+type Revocations interface {
+	Revoke(ctx context.Context, sessionIDs ...string) error
+	IsRevoked(ctx context.Context, sessionID string) (bool, error)
+}
+
+// RedisRevocations implements Revocations with one expiring key per session
+// (revoked_session:{id}), shared by every orchestrator instance
+type RedisRevocations struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewRedisRevocations creates Redis-backed revocations that are kept for ttl, which
+// should be the access token lifetime
+func NewRedisRevocations(addr, password string, db int, ttl time.Duration) *RedisRevocations {
+	return &RedisRevocations{
+		client: redis.NewClient(&redis.Options{
+			Addr:     addr,
+			Password: password,
+			DB:       db,
+		}),
+		ttl: ttl,
+	}
+}
+
+func revokedKey(sessionID string) string {
+	return fmt.Sprintf("revoked_session:%s", sessionID)
+}
+
+func (r *RedisRevocations) Revoke(ctx context.Context, sessionIDs ...string) error {
+	if len(sessionIDs) == 0 {
+		return nil
+	}
+	pipe := r.client.Pipeline()
+	for _, id := range sessionIDs {
+		pipe.Set(ctx, revokedKey(id), 1, r.ttl)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record revoked sessions: %w", err)
+	}
+	return nil
+}
+
+func (r *RedisRevocations) IsRevoked(ctx context.Context, sessionID string) (bool, error) {
+	n, err := r.client.Exists(ctx, revokedKey(sessionID)).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check session %s: %w", sessionID, err)
+	}
+	return n > 0, nil
+}
+
+func (r *RedisRevocations) Close() error {
+	if err := r.client.Close(); err != nil {
+		return fmt.Errorf("failed to close Redis client: %w", err)
+	}
+	return nil
+}
+
+// MemoryRevocations implements Revocations in process memory, for a single instance
+// running without Redis
+type MemoryRevocations struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	revoked map[string]time.Time // session ID -> when to forget it
+}
+
+// NewMemoryRevocations creates in-memory revocations that are kept for ttl
+func NewMemoryRevocations(ttl time.Duration) *MemoryRevocations {
+	return &MemoryRevocations{ttl: ttl, revoked: make(map[string]time.Time)}
+}
+
+func (m *MemoryRevocations) Revoke(_ context.Context, sessionIDs ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	for id, until := range m.revoked {
+		if now.After(until) {
+			delete(m.revoked, id)
+		}
+	}
+	for _, id := range sessionIDs {
+		m.revoked[id] = now.Add(m.ttl)
+	}
+	return nil
+}
+
+func (m *MemoryRevocations) IsRevoked(_ context.Context, sessionID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	until, ok := m.revoked[sessionID]
+	return ok && time.Now().Before(until), nil
+}
@@ -2,13 +2,16 @@ package listings
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	httplib "github.com/kunal768/cmpe202/http-lib"
+	"github.com/kunal768/cmpe202/orchestrator/common"
 )
 
 type Endpoints struct {
@@ -48,6 +51,11 @@ func (e *Endpoints) GetAllListingsHandler(w http.ResponseWriter, r *http.Request
 		req.Sort = &sort
 	}
 
+	// q is the full-text search term, named as listing-service expects it
+	if query := r.URL.Query().Get("q"); query != "" {
+		req.Query = &query
+	}
+
 	if keywords := r.URL.Query().Get("keywords"); keywords != "" {
 		req.Keywords = &keywords
 	}
@@ -74,6 +82,12 @@ func (e *Endpoints) GetAllListingsHandler(w http.ResponseWriter, r *http.Request
 		}
 	}
 
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		req.Cursor = &cursor
+	}
+
+	req.SkipCount = r.URL.Query().Get("skip_count") == "true"
+
 	// Call service
 	response, err := e.service.FetchAllListings(r.Context(), req)
 	if err != nil {
@@ -148,10 +162,7 @@ func (e *Endpoints) CreateListingHandler(w http.ResponseWriter, r *http.Request)
 	// Call service (context should have userID and role from middleware)
 	response, err := e.service.CreateListing(r.Context(), req)
 	if err != nil {
-		httplib.WriteJSON(w, http.StatusInternalServerError, ErrorResponse{
-			Error:   "Failed to create listing",
-			Message: err.Error(),
-		})
+		writeServiceError(w, "Failed to create listing", err)
 		return
 	}
 
@@ -208,10 +219,7 @@ func (e *Endpoints) UpdateListingHandler(w http.ResponseWriter, r *http.Request)
 	// Call service
 	response, err := e.service.UpdateListing(r.Context(), req)
 	if err != nil {
-		httplib.WriteJSON(w, http.StatusInternalServerError, ErrorResponse{
-			Error:   "Failed to update listing",
-			Message: err.Error(),
-		})
+		writeServiceError(w, "Failed to update listing", err)
 		return
 	}
 
@@ -804,10 +812,7 @@ func (e *Endpoints) UpdateFlagListingHandler(w http.ResponseWriter, r *http.Requ
 	// Call service
 	response, err := e.service.UpdateFlagListing(r.Context(), req)
 	if err != nil {
-		httplib.WriteJSON(w, http.StatusInternalServerError, ErrorResponse{
-			Error:   "Failed to update flag listing",
-			Message: err.Error(),
-		})
+		writeServiceError(w, "Failed to update flag listing", err)
 		return
 	}
 
@@ -1049,23 +1054,46 @@ func (e *Endpoints) RegisterRoutes(mux *http.ServeMux, dbPool *pgxpool.Pool) {
 		)
 	}
 
+	// Publishing chain: protected, and the user must have verified their campus email
+	verified := func(h http.Handler) http.Handler {
+		return protected(httplib.VerifiedEmailMiddleWare(dbPool)(h))
+	}
+
 	// Protected routes (require auth + role injection)
 	// Specific routes must come before parameterized routes to avoid conflicts
 	// Routes with specific path segments (like /delete/, /update/, /flag/, etc.) must come first
 	mux.Handle("GET /api/listings/", protected(http.HandlerFunc(e.GetAllListingsHandler)))
 	mux.Handle("POST /api/listings/chatsearch", protected(http.HandlerFunc(e.ChatSearchHandler)))
-	mux.Handle("POST /api/listings/create", protected(http.HandlerFunc(e.CreateListingHandler)))
+	mux.Handle("POST /api/listings/create", verified(http.HandlerFunc(e.CreateListingHandler)))
 	mux.Handle("GET /api/listings/user-lists", protected(http.HandlerFunc(e.GetUserListingsHandler)))
 	mux.Handle("POST /api/listings/upload", httplib.AuthMiddleWare(
-		httplib.RoleInjectionMiddleWare(dbPool)(http.HandlerFunc(e.UploadMediaHandler)),
+		httplib.RoleInjectionMiddleWare(dbPool)(httplib.VerifiedEmailMiddleWare(dbPool)(http.HandlerFunc(e.UploadMediaHandler))),
 	))
-	mux.Handle("POST /api/listings/add-media-url/{id}", protected(http.HandlerFunc(e.AddMediaURLHandler)))
+	mux.Handle("POST /api/listings/add-media-url/{id}", verified(http.HandlerFunc(e.AddMediaURLHandler)))
 	mux.Handle("GET /api/listings/flag/{id}/check", protected(http.HandlerFunc(e.HasUserFlaggedListingHandler)))
 	mux.Handle("POST /api/listings/flag/{id}", protected(http.HandlerFunc(e.FlagListingHandler)))
 	mux.Handle("GET /api/listings/saved", protected(http.HandlerFunc(e.GetSavedListingsHandler)))
 	mux.Handle("GET /api/listings/save/{id}/check", protected(http.HandlerFunc(e.IsListingSavedHandler)))
 	mux.Handle("POST /api/listings/save/{id}", protected(http.HandlerFunc(e.SaveListingHandler)))
 	mux.Handle("DELETE /api/listings/save/{id}", protected(http.HandlerFunc(e.UnsaveListingHandler)))
+	mux.Handle("PATCH /api/listings/save/{id}/alerts", protected(http.HandlerFunc(e.UpdateSavedListingAlertsHandler)))
+	mux.Handle("GET /api/listings/offers", protected(http.HandlerFunc(e.GetUserOffersHandler)))
+	mux.Handle("GET /api/listings/offers/{id}", protected(http.HandlerFunc(e.GetListingOffersHandler)))
+	mux.Handle("POST /api/listings/offers/{id}", protected(http.HandlerFunc(e.CreateOfferHandler)))
+	mux.Handle("POST /api/listings/offers/respond/{offer_id}", protected(http.HandlerFunc(e.RespondOfferHandler)))
+	mux.Handle("GET /api/listings/transactions", protected(http.HandlerFunc(e.GetUserTransactionsHandler)))
+	mux.Handle("POST /api/listings/transactions/{transaction_id}/confirm", protected(http.HandlerFunc(e.ConfirmTransactionHandler)))
+	mux.Handle("POST /api/listings/transactions/{transaction_id}/cancel", protected(http.HandlerFunc(e.CancelTransactionHandler)))
+	mux.Handle("POST /api/listings/sell/{id}", protected(http.HandlerFunc(e.MarkListingSoldHandler)))
+	mux.Handle("POST /api/listings/renew/{id}", protected(http.HandlerFunc(e.RenewListingHandler)))
+	mux.Handle("GET /api/listings/reviews/user/{user_id}", protected(http.HandlerFunc(e.GetUserReviewsHandler)))
+	mux.Handle("GET /api/listings/reviews/{id}", protected(http.HandlerFunc(e.GetListingReviewsHandler)))
+	mux.Handle("POST /api/listings/reviews/{id}", protected(http.HandlerFunc(e.CreateReviewHandler)))
+	mux.Handle("GET /api/listings/searches", protected(http.HandlerFunc(e.GetSavedSearchesHandler)))
+	mux.Handle("POST /api/listings/searches", protected(http.HandlerFunc(e.CreateSavedSearchHandler)))
+	mux.Handle("DELETE /api/listings/searches/{search_id}", protected(http.HandlerFunc(e.DeleteSavedSearchHandler)))
+	mux.Handle("GET /api/listings/moderation/outcomes", protected(http.HandlerFunc(e.GetModerationOutcomesHandler)))
+	mux.Handle("POST /api/listings/flag/{flag_id}/appeal", protected(http.HandlerFunc(e.CreateAppealHandler)))
 	mux.Handle("PATCH /api/listings/update/{id}", protected(http.HandlerFunc(e.UpdateListingHandler)))
 	mux.Handle("DELETE /api/listings/delete/{id}", httplib.AuthMiddleWare(
 		httplib.RoleInjectionMiddleWare(dbPool)(http.HandlerFunc(e.DeleteListingHandler)),
@@ -1082,6 +1110,18 @@ func (e *Endpoints) RegisterRoutes(mux *http.ServeMux, dbPool *pgxpool.Pool) {
 	mux.Handle("PATCH /api/listings/flag/{flag_id}", adminProtected(http.HandlerFunc(e.UpdateFlagListingHandler)))
 	mux.Handle("DELETE /api/listings/flag/{flag_id}", adminProtected(http.HandlerFunc(e.DeleteFlagListingHandler)))
 	mux.Handle("GET /api/listings/by-user-id", adminProtected(http.HandlerFunc(e.GetListingsByUserIDHandler)))
+	mux.Handle("GET /api/listings/history/{id}", adminProtected(http.HandlerFunc(e.GetListingHistoryHandler)))
+	mux.Handle("POST /api/listings/moderation/claim", adminProtected(http.HandlerFunc(e.ClaimNextCaseHandler)))
+	mux.Handle("GET /api/listings/moderation/cases", adminProtected(http.HandlerFunc(e.GetCasesHandler)))
+	mux.Handle("GET /api/listings/moderation/cases/{case_id}", adminProtected(http.HandlerFunc(e.GetCaseHandler)))
+	mux.Handle("POST /api/listings/moderation/cases/{case_id}/assign", adminProtected(http.HandlerFunc(e.AssignCaseHandler)))
+	mux.Handle("POST /api/listings/moderation/cases/{case_id}/release", adminProtected(http.HandlerFunc(e.ReleaseCaseHandler)))
+	mux.Handle("POST /api/listings/moderation/cases/{case_id}/resolve", adminProtected(http.HandlerFunc(e.ResolveCaseHandler)))
+	mux.Handle("GET /api/listings/moderation/stats", adminProtected(http.HandlerFunc(e.GetModerationStatsHandler)))
+	mux.Handle("POST /api/listings/moderation/flags/bulk", adminProtected(http.HandlerFunc(e.BulkUpdateFlagsHandler)))
+	mux.Handle("GET /api/listings/moderation/appeals", adminProtected(http.HandlerFunc(e.GetAppealsHandler)))
+	mux.Handle("GET /api/listings/moderation/appeals/{appeal_id}", adminProtected(http.HandlerFunc(e.GetAppealHandler)))
+	mux.Handle("POST /api/listings/moderation/appeals/{appeal_id}/decide", adminProtected(http.HandlerFunc(e.DecideAppealHandler)))
 }
 
 // validateCreateListingRequest validates create listing request
@@ -1112,3 +1152,764 @@ func validateCreateListingRequest(req CreateListingRequest) error {
 	}
 	return nil
 }
+
+// writeServiceError writes err with the status the listing-service returned, falling back to 500
+func writeServiceError(w http.ResponseWriter, title string, err error) {
+	message := err.Error()
+	var appErr *common.AppError
+	if errors.As(err, &appErr) {
+		message = appErr.Message
+	}
+	httplib.WriteJSON(w, common.MapToHTTPStatus(err), ErrorResponse{
+		Error:   title,
+		Message: message,
+	})
+}
+
+// CreateOfferHandler handles a buyer making an offer on a listing
+func (e *Endpoints) CreateOfferHandler(w http.ResponseWriter, r *http.Request) {
+	listingID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: "Invalid listing ID format",
+		})
+		return
+	}
+
+	var req CreateOfferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: "Failed to decode request body",
+		})
+		return
+	}
+	if req.Amount <= 0 {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Message: "Amount must be greater than 0",
+		})
+		return
+	}
+	req.ListingID = listingID
+
+	response, err := e.service.CreateOffer(r.Context(), req)
+	if err != nil {
+		writeServiceError(w, "Failed to create offer", err)
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusCreated, response)
+}
+
+// GetListingOffersHandler returns the offers on a listing visible to the caller
+func (e *Endpoints) GetListingOffersHandler(w http.ResponseWriter, r *http.Request) {
+	listingID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: "Invalid listing ID format",
+		})
+		return
+	}
+
+	response, err := e.service.FetchListingOffers(r.Context(), listingID)
+	if err != nil {
+		writeServiceError(w, "Failed to fetch offers", err)
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, response)
+}
+
+// GetUserOffersHandler returns offers the caller has made or received
+func (e *Endpoints) GetUserOffersHandler(w http.ResponseWriter, r *http.Request) {
+	var status *OfferStatus
+	if statusStr := r.URL.Query().Get("status"); statusStr != "" {
+		st := OfferStatus(statusStr)
+		status = &st
+	}
+
+	response, err := e.service.FetchUserOffers(r.Context(), status)
+	if err != nil {
+		writeServiceError(w, "Failed to fetch offers", err)
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, response)
+}
+
+// RespondOfferHandler handles accepting, rejecting or countering an offer
+func (e *Endpoints) RespondOfferHandler(w http.ResponseWriter, r *http.Request) {
+	offerID, err := strconv.ParseInt(r.PathValue("offer_id"), 10, 64)
+	if err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: "Invalid offer ID format",
+		})
+		return
+	}
+
+	var req RespondOfferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: "Failed to decode request body",
+		})
+		return
+	}
+	switch req.Action {
+	case OfferActionAccept, OfferActionReject:
+	case OfferActionCounter:
+		if req.Amount == nil || *req.Amount <= 0 {
+			httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error:   "Validation error",
+				Message: "Counter offers require an amount greater than 0",
+			})
+			return
+		}
+	default:
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Message: "Action must be one of accept, reject, counter",
+		})
+		return
+	}
+	req.OfferID = offerID
+
+	response, err := e.service.RespondToOffer(r.Context(), req)
+	if err != nil {
+		writeServiceError(w, "Failed to respond to offer", err)
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, response)
+}
+
+// MarkListingSoldHandler handles the seller marking a listing sold to a buyer
+func (e *Endpoints) MarkListingSoldHandler(w http.ResponseWriter, r *http.Request) {
+	listingID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: "Invalid listing ID format",
+		})
+		return
+	}
+
+	var req MarkSoldRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: "Failed to decode request body",
+		})
+		return
+	}
+	if req.BuyerUserID == uuid.Nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Message: "buyer_user_id is required",
+		})
+		return
+	}
+	if req.FinalPrice != nil && *req.FinalPrice < 0 {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Message: "Final price must be non-negative",
+		})
+		return
+	}
+	req.ListingID = listingID
+
+	response, err := e.service.MarkListingSold(r.Context(), req)
+	if err != nil {
+		writeServiceError(w, "Failed to mark listing as sold", err)
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusCreated, response)
+}
+
+// ConfirmTransactionHandler handles the buyer confirming a purchase
+func (e *Endpoints) ConfirmTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	transactionID, err := strconv.ParseInt(r.PathValue("transaction_id"), 10, 64)
+	if err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: "Invalid transaction ID format",
+		})
+		return
+	}
+
+	response, err := e.service.ConfirmTransaction(r.Context(), transactionID)
+	if err != nil {
+		writeServiceError(w, "Failed to confirm transaction", err)
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, response)
+}
+
+// CancelTransactionHandler handles either party cancelling a sale before confirmation
+func (e *Endpoints) CancelTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	transactionID, err := strconv.ParseInt(r.PathValue("transaction_id"), 10, 64)
+	if err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: "Invalid transaction ID format",
+		})
+		return
+	}
+
+	response, err := e.service.CancelTransaction(r.Context(), transactionID)
+	if err != nil {
+		writeServiceError(w, "Failed to cancel transaction", err)
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, response)
+}
+
+// GetUserTransactionsHandler returns the caller's purchases and sales
+func (e *Endpoints) GetUserTransactionsHandler(w http.ResponseWriter, r *http.Request) {
+	var status *TransactionStatus
+	if statusStr := r.URL.Query().Get("status"); statusStr != "" {
+		st := TransactionStatus(statusStr)
+		status = &st
+	}
+
+	response, err := e.service.FetchUserTransactions(r.Context(), status)
+	if err != nil {
+		writeServiceError(w, "Failed to fetch transactions", err)
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, response)
+}
+
+// CreateReviewHandler handles a buyer or seller rating the other party of a completed sale
+func (e *Endpoints) CreateReviewHandler(w http.ResponseWriter, r *http.Request) {
+	listingID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: "Invalid listing ID format",
+		})
+		return
+	}
+
+	var req CreateReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: "Failed to decode request body",
+		})
+		return
+	}
+	if req.Rating < 1 || req.Rating > 5 {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Message: "Rating must be between 1 and 5",
+		})
+		return
+	}
+	req.ListingID = listingID
+
+	response, err := e.service.CreateReview(r.Context(), req)
+	if err != nil {
+		writeServiceError(w, "Failed to create review", err)
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusCreated, response)
+}
+
+// GetListingReviewsHandler returns the reviews left on a listing's sale
+func (e *Endpoints) GetListingReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	listingID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: "Invalid listing ID format",
+		})
+		return
+	}
+
+	response, err := e.service.FetchListingReviews(r.Context(), listingID)
+	if err != nil {
+		writeServiceError(w, "Failed to fetch reviews", err)
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, response)
+}
+
+// GetUserReviewsHandler returns the reviews a user has received and their rating
+func (e *Endpoints) GetUserReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("user_id")
+	if _, err := uuid.Parse(userID); err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: "Invalid user ID format",
+		})
+		return
+	}
+
+	response, err := e.service.FetchUserReviews(r.Context(), userID)
+	if err != nil {
+		writeServiceError(w, "Failed to fetch reviews", err)
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, response)
+}
+
+// CreateSavedSearchHandler handles saving a named search to be alerted about
+func (e *Endpoints) CreateSavedSearchHandler(w http.ResponseWriter, r *http.Request) {
+	var req CreateSavedSearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: "Failed to decode request body",
+		})
+		return
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Message: "Name is required",
+		})
+		return
+	}
+
+	response, err := e.service.CreateSavedSearch(r.Context(), req)
+	if err != nil {
+		writeServiceError(w, "Failed to save search", err)
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusCreated, response)
+}
+
+// GetSavedSearchesHandler returns the caller's saved searches
+func (e *Endpoints) GetSavedSearchesHandler(w http.ResponseWriter, r *http.Request) {
+	response, err := e.service.FetchSavedSearches(r.Context())
+	if err != nil {
+		writeServiceError(w, "Failed to fetch saved searches", err)
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, response)
+}
+
+// DeleteSavedSearchHandler removes one of the caller's saved searches
+func (e *Endpoints) DeleteSavedSearchHandler(w http.ResponseWriter, r *http.Request) {
+	searchID, err := strconv.ParseInt(r.PathValue("search_id"), 10, 64)
+	if err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: "Invalid saved search ID format",
+		})
+		return
+	}
+
+	if err := e.service.DeleteSavedSearch(r.Context(), searchID); err != nil {
+		writeServiceError(w, "Failed to delete saved search", err)
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, map[string]string{"message": "Saved search deleted"})
+}
+
+// UpdateSavedListingAlertsHandler turns price-drop notifications for a saved listing on or off
+func (e *Endpoints) UpdateSavedListingAlertsHandler(w http.ResponseWriter, r *http.Request) {
+	listingID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: "Invalid listing ID format",
+		})
+		return
+	}
+
+	var req UpdateSavedListingAlertsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.PriceAlerts == nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: "price_alerts is required",
+		})
+		return
+	}
+	req.ListingID = listingID
+
+	response, err := e.service.UpdateSavedListingAlerts(r.Context(), req)
+	if err != nil {
+		writeServiceError(w, "Failed to update saved listing", err)
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, response)
+}
+
+// RenewListingHandler handles the seller extending a listing's expiry
+func (e *Endpoints) RenewListingHandler(w http.ResponseWriter, r *http.Request) {
+	listingID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: "Invalid listing ID format",
+		})
+		return
+	}
+
+	response, err := e.service.RenewListing(r.Context(), listingID)
+	if err != nil {
+		writeServiceError(w, "Failed to renew listing", err)
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, response)
+}
+
+// GetListingHistoryHandler returns a listing's edit history (admin only)
+func (e *Endpoints) GetListingHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	listingID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: "Invalid listing ID format",
+		})
+		return
+	}
+
+	response, err := e.service.FetchListingHistory(r.Context(), listingID)
+	if err != nil {
+		if err.Error() == "admin access required" {
+			httplib.WriteJSON(w, http.StatusForbidden, ErrorResponse{
+				Error:   "Forbidden",
+				Message: "Admin access required",
+			})
+			return
+		}
+		writeServiceError(w, "Failed to fetch listing history", err)
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, response)
+}
+
+// parseCaseID reads the {case_id} path value, writing a 400 when it is invalid
+func parseCaseID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	caseID, err := strconv.ParseInt(r.PathValue("case_id"), 10, 64)
+	if err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: "Invalid case ID format",
+		})
+		return 0, false
+	}
+	return caseID, true
+}
+
+// ClaimNextCaseHandler hands the calling admin the next case in the moderation queue (admin only)
+func (e *Endpoints) ClaimNextCaseHandler(w http.ResponseWriter, r *http.Request) {
+	response, err := e.service.ClaimNextCase(r.Context())
+	if err != nil {
+		writeServiceError(w, "Failed to claim case", err)
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, response)
+}
+
+// GetCasesHandler lists moderation cases (admin only)
+func (e *Endpoints) GetCasesHandler(w http.ResponseWriter, r *http.Request) {
+	req := FetchCasesRequest{}
+	if status := r.URL.Query().Get("status"); status != "" {
+		st := FlagStatus(status)
+		req.Status = &st
+	}
+	if assignedTo := r.URL.Query().Get("assigned_to"); assignedTo != "" {
+		req.AssignedTo = &assignedTo
+	}
+
+	response, err := e.service.FetchCases(r.Context(), req)
+	if err != nil {
+		writeServiceError(w, "Failed to fetch cases", err)
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, response)
+}
+
+// GetCaseHandler returns a moderation case with its listing and flags (admin only)
+func (e *Endpoints) GetCaseHandler(w http.ResponseWriter, r *http.Request) {
+	caseID, ok := parseCaseID(w, r)
+	if !ok {
+		return
+	}
+
+	response, err := e.service.FetchCase(r.Context(), caseID)
+	if err != nil {
+		writeServiceError(w, "Failed to fetch case", err)
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, response)
+}
+
+// AssignCaseHandler assigns a moderation case to a specific admin (admin only)
+func (e *Endpoints) AssignCaseHandler(w http.ResponseWriter, r *http.Request) {
+	caseID, ok := parseCaseID(w, r)
+	if !ok {
+		return
+	}
+
+	var req AssignCaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: "Failed to decode request body",
+		})
+		return
+	}
+	if req.AdminUserID == uuid.Nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Message: "admin_user_id is required",
+		})
+		return
+	}
+	req.CaseID = caseID
+
+	response, err := e.service.AssignCase(r.Context(), req)
+	if err != nil {
+		writeServiceError(w, "Failed to assign case", err)
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, response)
+}
+
+// ReleaseCaseHandler returns the calling admin's claimed case to the queue (admin only)
+func (e *Endpoints) ReleaseCaseHandler(w http.ResponseWriter, r *http.Request) {
+	caseID, ok := parseCaseID(w, r)
+	if !ok {
+		return
+	}
+
+	response, err := e.service.ReleaseCase(r.Context(), caseID)
+	if err != nil {
+		writeServiceError(w, "Failed to release case", err)
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, response)
+}
+
+// ResolveCaseHandler closes the calling admin's claimed case and all of its flags (admin only)
+func (e *Endpoints) ResolveCaseHandler(w http.ResponseWriter, r *http.Request) {
+	caseID, ok := parseCaseID(w, r)
+	if !ok {
+		return
+	}
+
+	var req ResolveCaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: "Failed to decode request body",
+		})
+		return
+	}
+	if req.Status != FlagStatusResolved && req.Status != FlagStatusDismissed {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Message: "Status must be RESOLVED or DISMISSED",
+		})
+		return
+	}
+	req.CaseID = caseID
+
+	response, err := e.service.ResolveCase(r.Context(), req)
+	if err != nil {
+		writeServiceError(w, "Failed to resolve case", err)
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, response)
+}
+
+// BulkUpdateFlagsHandler dismisses or resolves many flags at once, by id or by filter (admin only)
+func (e *Endpoints) BulkUpdateFlagsHandler(w http.ResponseWriter, r *http.Request) {
+	var req BulkFlagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: "Failed to decode request body",
+		})
+		return
+	}
+	switch req.Action {
+	case BulkDismiss, BulkResolve, BulkResolveAndArchive:
+	default:
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Message: "Action must be DISMISS, RESOLVE or RESOLVE_AND_ARCHIVE",
+		})
+		return
+	}
+	if (len(req.FlagIDs) == 0) == (req.Filter == nil) {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Message: "Provide either flag_ids or filter",
+		})
+		return
+	}
+
+	response, err := e.service.BulkUpdateFlags(r.Context(), req)
+	if err != nil {
+		writeServiceError(w, "Failed to apply bulk moderation", err)
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, response)
+}
+
+// GetModerationStatsHandler returns per-admin moderation workload (admin only)
+func (e *Endpoints) GetModerationStatsHandler(w http.ResponseWriter, r *http.Request) {
+	response, err := e.service.FetchModerationStats(r.Context())
+	if err != nil {
+		writeServiceError(w, "Failed to fetch moderation stats", err)
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, response)
+}
+
+func parseAppealID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	appealID, err := strconv.ParseInt(r.PathValue("appeal_id"), 10, 64)
+	if err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: "Invalid appeal ID format",
+		})
+		return 0, false
+	}
+	return appealID, true
+}
+
+// GetModerationOutcomesHandler returns the moderation decisions on the caller's listings
+func (e *Endpoints) GetModerationOutcomesHandler(w http.ResponseWriter, r *http.Request) {
+	response, err := e.service.FetchModerationOutcomes(r.Context())
+	if err != nil {
+		writeServiceError(w, "Failed to fetch moderation outcomes", err)
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, response)
+}
+
+// CreateAppealHandler appeals the decision on a flag against the caller's listing
+func (e *Endpoints) CreateAppealHandler(w http.ResponseWriter, r *http.Request) {
+	flagID, err := strconv.ParseInt(r.PathValue("flag_id"), 10, 64)
+	if err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: "Invalid flag ID format",
+		})
+		return
+	}
+
+	var req CreateAppealRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: "Failed to decode request body",
+		})
+		return
+	}
+	if strings.TrimSpace(req.Justification) == "" {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Message: "Justification is required",
+		})
+		return
+	}
+	req.FlagID = flagID
+
+	response, err := e.service.CreateAppeal(r.Context(), req)
+	if err != nil {
+		writeServiceError(w, "Failed to create appeal", err)
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusCreated, response)
+}
+
+// GetAppealsHandler lists the appeals queue (admin only)
+func (e *Endpoints) GetAppealsHandler(w http.ResponseWriter, r *http.Request) {
+	var status *AppealStatus
+	if v := r.URL.Query().Get("status"); v != "" {
+		st := AppealStatus(v)
+		status = &st
+	}
+
+	response, err := e.service.FetchAppeals(r.Context(), status)
+	if err != nil {
+		writeServiceError(w, "Failed to fetch appeals", err)
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, response)
+}
+
+// GetAppealHandler returns an appeal with the flag and listing it concerns (admin only)
+func (e *Endpoints) GetAppealHandler(w http.ResponseWriter, r *http.Request) {
+	appealID, ok := parseAppealID(w, r)
+	if !ok {
+		return
+	}
+
+	response, err := e.service.FetchAppeal(r.Context(), appealID)
+	if err != nil {
+		writeServiceError(w, "Failed to fetch appeal", err)
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, response)
+}
+
+// DecideAppealHandler upholds or overturns an appeal (admin only)
+func (e *Endpoints) DecideAppealHandler(w http.ResponseWriter, r *http.Request) {
+	appealID, ok := parseAppealID(w, r)
+	if !ok {
+		return
+	}
+
+	var req DecideAppealRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: "Failed to decode request body",
+		})
+		return
+	}
+	if req.Status != AppealUpheld && req.Status != AppealOverturned {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Message: "Status must be UPHELD or OVERTURNED",
+		})
+		return
+	}
+	req.AppealID = appealID
+
+	response, err := e.service.DecideAppeal(r.Context(), req)
+	if err != nil {
+		writeServiceError(w, "Failed to decide appeal", err)
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,63 @@
+package listings
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestGetAllListingsHandlerProxiesSearch(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		wantQ     string
+		wantSkip  bool
+		wantCount bool
+	}{
+		{"search", "?q=road+bike", "road bike", false, true},
+		{"search without count", "?q=desk&skip_count=true", "desk", true, false},
+		{"feed", "?category=BOOKS", "", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var upstream url.Values
+			listingService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				upstream = r.URL.Query()
+				resp := map[string]any{"items": []Listing{}}
+				if upstream.Get("skip_count") != "true" {
+					resp["count"] = 0
+				}
+				json.NewEncoder(w).Encode(resp)
+			}))
+			defer listingService.Close()
+
+			e := NewEndpoints(NewListingService(listingService.URL, "test-secret", nil, nil, nil))
+			rec := httptest.NewRecorder()
+			e.GetAllListingsHandler(rec, httptest.NewRequest(http.MethodGet, "/api/listings/"+tt.query, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+			}
+
+			if got := upstream.Get("q"); got != tt.wantQ {
+				t.Errorf("listing-service got q=%q, want %q", got, tt.wantQ)
+			}
+			if upstream.Has("query") {
+				t.Errorf("listing-service got a query parameter: %v", upstream)
+			}
+			if got := upstream.Get("skip_count") == "true"; got != tt.wantSkip {
+				t.Errorf("listing-service got skip_count=%v, want %v", got, tt.wantSkip)
+			}
+
+			var body map[string]any
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if _, ok := body["count"]; ok != tt.wantCount {
+				t.Errorf("count present = %v, want %v (%s)", ok, tt.wantCount, rec.Body)
+			}
+		})
+	}
+}
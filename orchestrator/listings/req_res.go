@@ -1,9 +1,11 @@
 package listings
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/kunal768/cmpe202/http-lib/screening"
 )
 
 // Category and Status types matching listing-service
@@ -35,6 +37,12 @@ type Listing struct {
 	UserID      uuid.UUID `json:"user_id"`
 	Status      Status    `json:"status"`
 	CreatedAt   time.Time `json:"created_at"`
+
+	Seller        *SellerInfo `json:"seller,omitempty"`
+	PreviousPrice *int64      `json:"previous_price,omitempty"` // set on update when the price changed
+	ExpiresAt     *time.Time  `json:"expires_at,omitempty"`     // when an AVAILABLE listing is archived unless renewed
+
+	ContentWarnings []screening.Finding `json:"content_warnings,omitempty"` // set on create/update when screening warned about the text
 }
 
 // CreateListingRequest for creating a new listing
@@ -55,17 +63,24 @@ type FetchAllListingsRequest struct {
 	Limit    *int      `json:"limit,omitempty"`
 	Offset   *int      `json:"offset,omitempty"`
 	Sort     *string   `json:"sort,omitempty"`
+	Query    *string   `json:"q,omitempty"`
 	Keywords *string   `json:"keywords,omitempty"`
 	Category *Category `json:"category,omitempty"`
 	Status   *Status   `json:"status,omitempty"`
 	MinPrice *int64    `json:"min_price,omitempty"`
 	MaxPrice *int64    `json:"max_price,omitempty"`
+	// Cursor is the next_cursor of a previous page; SkipCount omits the total count
+	Cursor    *string `json:"cursor,omitempty"`
+	SkipCount bool    `json:"skip_count,omitempty"`
 }
 
 // FetchAllListingsResponse returns a list of listings with count
+// NextCursor is empty on the last page and for relevance-ranked searches; Count is nil
+// when SkipCount was requested
 type FetchAllListingsResponse struct {
-	Items []Listing `json:"items"`
-	Count int       `json:"count"`
+	Items      []Listing `json:"items"`
+	Count      *int64    `json:"count,omitempty"`
+	NextCursor string    `json:"next_cursor,omitempty"`
 }
 
 // FetchListingRequest for getting a single listing
@@ -126,6 +141,12 @@ type UploadSASResponse struct {
 	BlobName           string `json:"blob_name"`
 }
 
+// CreateChatAttachmentRequest describes an image about to be uploaded for chat
+type CreateChatAttachmentRequest struct {
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
 // UploadMediaResponse returns SAS URLs for file uploads
 type UploadMediaResponse struct {
 	Message string              `json:"message"`
@@ -199,6 +220,12 @@ type FlaggedListing struct {
 
 	// Listing information
 	Listing Listing `json:"listing"`
+
+	// ListingRevision is the listing as it was when the flag was filed
+	ListingRevision *ListingRevision `json:"listing_revision,omitempty"`
+
+	// AppealStatus is set once the seller appeals a RESOLVED flag
+	AppealStatus *AppealStatus `json:"appeal_status,omitempty"`
 }
 
 // FetchFlaggedListingsRequest for filtering flagged listings
@@ -293,6 +320,8 @@ type SavedListing struct {
 	UserID    uuid.UUID `json:"user_id"`
 	ListingID int64     `json:"listing_id"`
 	CreatedAt time.Time `json:"created_at"`
+	// PriceAlerts is false when the user opted out of price-drop notifications
+	PriceAlerts bool `json:"price_alerts"`
 	
 	// Listing information (populated when fetching saved listings)
 	Listing Listing `json:"listing,omitempty"`
@@ -327,3 +356,454 @@ type IsListingSavedResponse struct {
 type FetchSavedListingsResponse struct {
 	SavedListings []SavedListing `json:"saved_listings"`
 }
+
+// OfferStatus represents where an offer is in the negotiation
+type OfferStatus string
+
+const (
+	OfferPending   OfferStatus = "PENDING"
+	OfferAccepted  OfferStatus = "ACCEPTED"
+	OfferRejected  OfferStatus = "REJECTED"
+	OfferCountered OfferStatus = "COUNTERED"
+	OfferDeclined  OfferStatus = "DECLINED"
+	OfferExpired   OfferStatus = "EXPIRED"
+)
+
+// OfferAction is the other party's answer to an offer
+type OfferAction string
+
+const (
+	OfferActionAccept  OfferAction = "accept"
+	OfferActionReject  OfferAction = "reject"
+	OfferActionCounter OfferAction = "counter"
+)
+
+// Offer represents one step of a buyer/seller negotiation on a listing
+type Offer struct {
+	ID              int64       `json:"id"`
+	ListingID       int64       `json:"listing_id"`
+	BuyerUserID     uuid.UUID   `json:"buyer_user_id"`
+	SellerUserID    uuid.UUID   `json:"seller_user_id"`
+	CreatedByUserID uuid.UUID   `json:"created_by_user_id"`
+	ParentOfferID   *int64      `json:"parent_offer_id,omitempty"`
+	Amount          int64       `json:"amount"`
+	Message         *string     `json:"message,omitempty"`
+	Status          OfferStatus `json:"status"`
+	ExpiresAt       time.Time   `json:"expires_at"`
+	CreatedAt       time.Time   `json:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at"`
+	RespondedAt     *time.Time  `json:"responded_at,omitempty"`
+}
+
+// CreateOfferRequest for making an offer on a listing
+type CreateOfferRequest struct {
+	ListingID      int64   `json:"-"`
+	Amount         int64   `json:"amount"`
+	Message        *string `json:"message,omitempty"`
+	ExpiresInHours *int    `json:"expires_in_hours,omitempty"`
+}
+
+// CreateOfferResponse returns the created offer
+type CreateOfferResponse struct {
+	Offer Offer `json:"offer"`
+}
+
+// RespondOfferRequest for accepting, rejecting or countering an offer
+type RespondOfferRequest struct {
+	OfferID        int64       `json:"-"`
+	Action         OfferAction `json:"action"`
+	Amount         *int64      `json:"amount,omitempty"` // required for counter
+	Message        *string     `json:"message,omitempty"`
+	ExpiresInHours *int        `json:"expires_in_hours,omitempty"`
+}
+
+// RespondOfferResponse returns the answered offer, the counter offer if any,
+// and competing offers that were declined by an accept
+type RespondOfferResponse struct {
+	Offer    Offer   `json:"offer"`
+	Counter  *Offer  `json:"counter,omitempty"`
+	Declined []Offer `json:"declined,omitempty"`
+}
+
+// FetchOffersResponse returns a list of offers
+type FetchOffersResponse struct {
+	Offers []Offer `json:"offers"`
+	Count  int     `json:"count"`
+}
+
+// OfferEvent is pushed to a user's WebSocket when an offer they are party to changes
+type OfferEvent struct {
+	Type        string `json:"type"`    // "offer"
+	SubType     string `json:"subType"` // "created", "accepted", "rejected", "countered", "declined"
+	RecipientID string `json:"recipientId"`
+	Offer       Offer  `json:"offer"`
+}
+
+// TransactionStatus represents where a sale is in the seller/buyer confirmation flow
+type TransactionStatus string
+
+const (
+	TransactionPendingConfirmation TransactionStatus = "PENDING_CONFIRMATION"
+	TransactionCompleted           TransactionStatus = "COMPLETED"
+	TransactionCancelled           TransactionStatus = "CANCELLED"
+)
+
+// Transaction represents the sale of a listing from seller to buyer
+type Transaction struct {
+	ID           int64             `json:"id"`
+	ListingID    int64             `json:"listing_id"`
+	SellerUserID uuid.UUID         `json:"seller_user_id"`
+	BuyerUserID  uuid.UUID         `json:"buyer_user_id"`
+	OfferID      *int64            `json:"offer_id,omitempty"`
+	FinalPrice   int64             `json:"final_price"`
+	Status       TransactionStatus `json:"status"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+	CompletedAt  *time.Time        `json:"completed_at,omitempty"`
+	CancelledAt  *time.Time        `json:"cancelled_at,omitempty"`
+}
+
+// MarkSoldRequest for the seller marking a listing sold to a buyer
+type MarkSoldRequest struct {
+	ListingID   int64     `json:"-"`
+	BuyerUserID uuid.UUID `json:"buyer_user_id"`
+	FinalPrice  *int64    `json:"final_price,omitempty"` // defaults to the accepted offer, then the listing price
+}
+
+// TransactionResponse returns a single transaction
+type TransactionResponse struct {
+	Transaction Transaction `json:"transaction"`
+}
+
+// FetchTransactionsResponse returns a list of transactions
+type FetchTransactionsResponse struct {
+	Transactions []Transaction `json:"transactions"`
+	Count        int           `json:"count"`
+}
+
+// ReviewerRole is the side of the sale that left a review
+type ReviewerRole string
+
+const (
+	ReviewerBuyer  ReviewerRole = "BUYER"
+	ReviewerSeller ReviewerRole = "SELLER"
+)
+
+// Review represents one party's rating of the other after a completed sale
+type Review struct {
+	ID             int64        `json:"id"`
+	TransactionID  int64        `json:"transaction_id"`
+	ListingID      int64        `json:"listing_id"`
+	ReviewerUserID uuid.UUID    `json:"reviewer_user_id"`
+	RevieweeUserID uuid.UUID    `json:"reviewee_user_id"`
+	ReviewerRole   ReviewerRole `json:"reviewer_role"`
+	Rating         int          `json:"rating"`
+	Comment        *string      `json:"comment,omitempty"`
+	CreatedAt      time.Time    `json:"created_at"`
+}
+
+// RatingSummary is the aggregate of the reviews a user has received
+type RatingSummary struct {
+	Average     float64 `json:"average"`
+	ReviewCount int     `json:"review_count"`
+}
+
+// SellerInfo is the seller reputation embedded with each listing
+type SellerInfo struct {
+	UserID uuid.UUID     `json:"user_id"`
+	Rating RatingSummary `json:"rating"`
+}
+
+// CreateReviewRequest for rating the other party of a listing's completed sale
+type CreateReviewRequest struct {
+	ListingID int64   `json:"-"`
+	Rating    int     `json:"rating"` // 1-5
+	Comment   *string `json:"comment,omitempty"`
+}
+
+// CreateReviewResponse returns the created review
+type CreateReviewResponse struct {
+	Review Review `json:"review"`
+}
+
+// FetchReviewsResponse returns a list of reviews
+type FetchReviewsResponse struct {
+	Reviews []Review `json:"reviews"`
+	Count   int      `json:"count"`
+}
+
+// FetchUserReviewsResponse returns the reviews a user has received and their rating
+type FetchUserReviewsResponse struct {
+	Reviews []Review      `json:"reviews"`
+	Rating  RatingSummary `json:"rating"`
+}
+
+// SavedSearch represents a named search the user is alerted about
+type SavedSearch struct {
+	ID        int64     `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Name      string    `json:"name"`
+	Query     *string   `json:"query,omitempty"`
+	Keywords  []string  `json:"keywords"`
+	Category  *Category `json:"category,omitempty"`
+	MinPrice  *int64    `json:"min_price,omitempty"`
+	MaxPrice  *int64    `json:"max_price,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreateSavedSearchRequest for saving a named search
+type CreateSavedSearchRequest struct {
+	Name     string    `json:"name"`
+	Query    *string   `json:"query,omitempty"`
+	Keywords []string  `json:"keywords,omitempty"`
+	Category *Category `json:"category,omitempty"`
+	MinPrice *int64    `json:"min_price,omitempty"`
+	MaxPrice *int64    `json:"max_price,omitempty"`
+}
+
+// CreateSavedSearchResponse returns the saved search
+type CreateSavedSearchResponse struct {
+	Search SavedSearch `json:"search"`
+}
+
+// FetchSavedSearchesResponse returns the user's saved searches
+type FetchSavedSearchesResponse struct {
+	Searches []SavedSearch `json:"searches"`
+	Count    int           `json:"count"`
+}
+
+// UpdateSavedListingAlertsRequest toggles price-drop notifications for a saved listing
+type UpdateSavedListingAlertsRequest struct {
+	ListingID   int64 `json:"-"`
+	PriceAlerts *bool `json:"price_alerts"`
+}
+
+// UpdateSavedListingAlertsResponse returns the saved listing's alert setting
+type UpdateSavedListingAlertsResponse struct {
+	ListingID   int64 `json:"listing_id"`
+	PriceAlerts bool  `json:"price_alerts"`
+}
+
+// RenewListingResponse returns the renewed listing with its new expiry
+type RenewListingResponse struct {
+	Listing Listing `json:"listing"`
+}
+
+type RevisionAction string
+
+const (
+	RevisionBaseline RevisionAction = "BASELINE"
+	RevisionCreated  RevisionAction = "CREATED"
+	RevisionUpdated  RevisionAction = "UPDATED"
+	RevisionDeleted  RevisionAction = "DELETED"
+)
+
+// FieldChange is one field's value before and after a revision
+type FieldChange struct {
+	Old json.RawMessage `json:"old"`
+	New json.RawMessage `json:"new"`
+}
+
+// ListingSnapshot holds the tracked fields of a listing at a revision
+type ListingSnapshot struct {
+	Title       string   `json:"title"`
+	Description *string  `json:"description,omitempty"`
+	Price       int64    `json:"price"`
+	Category    Category `json:"category"`
+	Status      Status   `json:"status"`
+}
+
+// ListingRevision is an immutable record of one change to a listing
+type ListingRevision struct {
+	ID              int64                  `json:"id"`
+	ListingID       int64                  `json:"listing_id"`
+	Revision        int                    `json:"revision"`
+	Action          RevisionAction         `json:"action"`
+	ChangedByUserID *uuid.UUID             `json:"changed_by_user_id,omitempty"` // nil for system changes
+	Changes         map[string]FieldChange `json:"changes"`
+	Snapshot        ListingSnapshot        `json:"snapshot"`
+	CreatedAt       time.Time              `json:"created_at"`
+}
+
+// FetchListingHistoryResponse returns a listing's revisions, oldest first
+type FetchListingHistoryResponse struct {
+	Revisions []ListingRevision `json:"revisions"`
+	Count     int               `json:"count"`
+}
+
+// ModerationCase groups the open flags on one listing into a unit of admin work
+type ModerationCase struct {
+	ID              int64      `json:"id"`
+	ListingID       int64      `json:"listing_id"`
+	Status          FlagStatus `json:"status"`
+	AssignedAdminID *uuid.UUID `json:"assigned_admin_id,omitempty"`
+	ClaimedAt       *time.Time `json:"claimed_at,omitempty"`
+	ClaimExpiresAt  *time.Time `json:"claim_expires_at,omitempty"`
+	ResolutionNotes *string    `json:"resolution_notes,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	ResolvedAt      *time.Time `json:"resolved_at,omitempty"`
+	FlagCount       int        `json:"flag_count"`
+
+	Listing *Listing         `json:"listing,omitempty"`
+	Flags   []FlaggedListing `json:"flags,omitempty"`
+}
+
+// ModerationCaseResponse returns a single moderation case
+type ModerationCaseResponse struct {
+	Case ModerationCase `json:"case"`
+}
+
+// FetchCasesRequest filters the moderation queue
+type FetchCasesRequest struct {
+	Status     *FlagStatus `json:"status,omitempty"`
+	AssignedTo *string     `json:"assigned_to,omitempty"`
+}
+
+// FetchCasesResponse returns moderation cases
+type FetchCasesResponse struct {
+	Cases []ModerationCase `json:"cases"`
+	Count int              `json:"count"`
+}
+
+// AssignCaseRequest assigns a case to a specific admin
+type AssignCaseRequest struct {
+	CaseID      int64     `json:"-"`
+	AdminUserID uuid.UUID `json:"admin_user_id"`
+}
+
+// ResolveCaseRequest closes a case and all of its flags
+type ResolveCaseRequest struct {
+	CaseID          int64      `json:"-"`
+	Status          FlagStatus `json:"status"`
+	ResolutionNotes *string    `json:"resolution_notes,omitempty"`
+}
+
+// AdminWorkload is one admin's moderation stats
+type AdminWorkload struct {
+	AdminUserID    uuid.UUID  `json:"admin_user_id"`
+	UserName       string     `json:"user_name"`
+	ActiveClaims   int        `json:"active_claims"`
+	ResolvedCases  int        `json:"resolved_cases"`
+	DismissedCases int        `json:"dismissed_cases"`
+	LastClosedAt   *time.Time `json:"last_closed_at,omitempty"`
+}
+
+// FetchModerationStatsResponse returns per-admin moderation workload
+type FetchModerationStatsResponse struct {
+	Admins []AdminWorkload `json:"admins"`
+}
+
+// BulkFlagAction is what a bulk moderation request does to each selected flag
+type BulkFlagAction string
+
+const (
+	BulkDismiss           BulkFlagAction = "DISMISS"
+	BulkResolve           BulkFlagAction = "RESOLVE"
+	BulkResolveAndArchive BulkFlagAction = "RESOLVE_AND_ARCHIVE" // also archives the flagged listing
+)
+
+// BulkFlagFilter selects flags by attribute. Status defaults to the open statuses.
+type BulkFlagFilter struct {
+	Reason         *FlagReason `json:"reason,omitempty"`
+	Status         *FlagStatus `json:"status,omitempty"`
+	CreatedAfter   *time.Time  `json:"created_after,omitempty"`
+	CreatedBefore  *time.Time  `json:"created_before,omitempty"`
+	ListingOwnerID *uuid.UUID  `json:"listing_owner_id,omitempty"`
+}
+
+// BulkFlagRequest applies Action to the flags in FlagIDs, or to every flag matching Filter
+type BulkFlagRequest struct {
+	FlagIDs         []int64         `json:"flag_ids,omitempty"`
+	Filter          *BulkFlagFilter `json:"filter,omitempty"`
+	Action          BulkFlagAction  `json:"action"`
+	ResolutionNotes *string         `json:"resolution_notes,omitempty"`
+}
+
+// BulkFlagItemResult is the outcome for one flag: "updated", "skipped" or "not_found"
+type BulkFlagItemResult struct {
+	FlagID          int64           `json:"flag_id"`
+	ListingID       int64           `json:"listing_id,omitempty"`
+	Result          string          `json:"result"`
+	Message         string          `json:"message,omitempty"`
+	ListingArchived bool            `json:"listing_archived,omitempty"`
+	Flag            *FlaggedListing `json:"flag,omitempty"`
+}
+
+// BulkFlagResponse sums up a bulk moderation request
+type BulkFlagResponse struct {
+	BulkActionID uuid.UUID            `json:"bulk_action_id"`
+	Action       BulkFlagAction       `json:"action"`
+	Updated      int                  `json:"updated"`
+	Skipped      int                  `json:"skipped"`
+	Results      []BulkFlagItemResult `json:"results"`
+}
+
+type AppealStatus string
+
+const (
+	AppealPending    AppealStatus = "PENDING"
+	AppealUpheld     AppealStatus = "UPHELD"
+	AppealOverturned AppealStatus = "OVERTURNED"
+)
+
+// ListingAppeal is a seller's appeal against a RESOLVED flag on their listing
+type ListingAppeal struct {
+	ID             int64        `json:"id"`
+	FlagID         int64        `json:"flag_id"`
+	ListingID      int64        `json:"listing_id"`
+	SellerUserID   uuid.UUID    `json:"seller_user_id"`
+	Justification  string       `json:"justification"`
+	Status         AppealStatus `json:"status"`
+	ReviewerUserID *uuid.UUID   `json:"reviewer_user_id,omitempty"`
+	DecisionNotes  *string      `json:"decision_notes,omitempty"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+	DecidedAt      *time.Time   `json:"decided_at,omitempty"`
+
+	Flag *FlaggedListing `json:"flag,omitempty"`
+}
+
+// ModerationOutcome is a closed flag on the caller's own listing
+type ModerationOutcome struct {
+	FlagID          int64          `json:"flag_id"`
+	ListingID       int64          `json:"listing_id"`
+	ListingTitle    string         `json:"listing_title"`
+	ListingStatus   Status         `json:"listing_status"`
+	Reason          FlagReason     `json:"reason"`
+	Status          FlagStatus     `json:"status"`
+	ResolutionNotes *string        `json:"resolution_notes,omitempty"`
+	ResolvedAt      *time.Time     `json:"resolved_at,omitempty"`
+	Appealable      bool           `json:"appealable"`
+	Appeal          *ListingAppeal `json:"appeal,omitempty"`
+}
+
+// FetchModerationOutcomesResponse returns the moderation decisions on the caller's listings
+type FetchModerationOutcomesResponse struct {
+	Outcomes []ModerationOutcome `json:"outcomes"`
+}
+
+// CreateAppealRequest appeals the decision on a flag
+type CreateAppealRequest struct {
+	FlagID        int64  `json:"-"`
+	Justification string `json:"justification"`
+}
+
+// AppealResponse returns a single appeal
+type AppealResponse struct {
+	Appeal ListingAppeal `json:"appeal"`
+}
+
+// FetchAppealsResponse returns the appeals queue
+type FetchAppealsResponse struct {
+	Appeals []ListingAppeal `json:"appeals"`
+	Count   int             `json:"count"`
+}
+
+// DecideAppealRequest upholds or overturns an appeal
+type DecideAppealRequest struct {
+	AppealID      int64        `json:"-"`
+	Status        AppealStatus `json:"status"`
+	DecisionNotes *string      `json:"decision_notes,omitempty"`
+}
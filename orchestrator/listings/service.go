@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -13,6 +14,9 @@ import (
 
 	httplib "github.com/kunal768/cmpe202/http-lib"
 	"github.com/kunal768/cmpe202/orchestrator/common"
+	"github.com/kunal768/cmpe202/orchestrator/internal/events"
+	"github.com/kunal768/cmpe202/orchestrator/internal/queue"
+	"github.com/kunal768/cmpe202/orchestrator/notifications"
 )
 
 type serviceConfig struct {
@@ -21,7 +25,10 @@ type serviceConfig struct {
 }
 
 type svc struct {
-	config serviceConfig
+	config        serviceConfig
+	events        events.Publisher      // optional; nil disables WebSocket events
+	listingEvents queue.Publisher       // optional; nil disables saved-search alerts
+	notifier      notifications.Service // optional; nil disables inbox notifications
 }
 
 type Service interface {
@@ -48,9 +55,40 @@ type Service interface {
 	UnsaveListing(ctx context.Context, req UnsaveListingRequest) (*UnsaveListingResponse, error)
 	IsListingSaved(ctx context.Context, listingID int64) (bool, error)
 	FetchSavedListings(ctx context.Context) (*FetchSavedListingsResponse, error)
+	CreateOffer(ctx context.Context, req CreateOfferRequest) (*CreateOfferResponse, error)
+	FetchListingOffers(ctx context.Context, listingID int64) (*FetchOffersResponse, error)
+	FetchUserOffers(ctx context.Context, status *OfferStatus) (*FetchOffersResponse, error)
+	RespondToOffer(ctx context.Context, req RespondOfferRequest) (*RespondOfferResponse, error)
+	MarkListingSold(ctx context.Context, req MarkSoldRequest) (*TransactionResponse, error)
+	ConfirmTransaction(ctx context.Context, transactionID int64) (*TransactionResponse, error)
+	CancelTransaction(ctx context.Context, transactionID int64) (*TransactionResponse, error)
+	FetchUserTransactions(ctx context.Context, status *TransactionStatus) (*FetchTransactionsResponse, error)
+	CreateReview(ctx context.Context, req CreateReviewRequest) (*CreateReviewResponse, error)
+	FetchListingReviews(ctx context.Context, listingID int64) (*FetchReviewsResponse, error)
+	FetchUserReviews(ctx context.Context, userID string) (*FetchUserReviewsResponse, error)
+	CreateSavedSearch(ctx context.Context, req CreateSavedSearchRequest) (*CreateSavedSearchResponse, error)
+	FetchSavedSearches(ctx context.Context) (*FetchSavedSearchesResponse, error)
+	DeleteSavedSearch(ctx context.Context, searchID int64) error
+	UpdateSavedListingAlerts(ctx context.Context, req UpdateSavedListingAlertsRequest) (*UpdateSavedListingAlertsResponse, error)
+	RenewListing(ctx context.Context, listingID int64) (*RenewListingResponse, error)
+	FetchListingHistory(ctx context.Context, listingID int64) (*FetchListingHistoryResponse, error)
+	ClaimNextCase(ctx context.Context) (*ModerationCaseResponse, error)
+	FetchCases(ctx context.Context, req FetchCasesRequest) (*FetchCasesResponse, error)
+	FetchCase(ctx context.Context, caseID int64) (*ModerationCaseResponse, error)
+	AssignCase(ctx context.Context, req AssignCaseRequest) (*ModerationCaseResponse, error)
+	ReleaseCase(ctx context.Context, caseID int64) (*ModerationCaseResponse, error)
+	ResolveCase(ctx context.Context, req ResolveCaseRequest) (*ModerationCaseResponse, error)
+	FetchModerationStats(ctx context.Context) (*FetchModerationStatsResponse, error)
+	BulkUpdateFlags(ctx context.Context, req BulkFlagRequest) (*BulkFlagResponse, error)
+	FetchModerationOutcomes(ctx context.Context) (*FetchModerationOutcomesResponse, error)
+	CreateAppeal(ctx context.Context, req CreateAppealRequest) (*AppealResponse, error)
+	FetchAppeals(ctx context.Context, status *AppealStatus) (*FetchAppealsResponse, error)
+	FetchAppeal(ctx context.Context, appealID int64) (*AppealResponse, error)
+	DecideAppeal(ctx context.Context, req DecideAppealRequest) (*AppealResponse, error)
+	CreateChatAttachment(ctx context.Context, req CreateChatAttachmentRequest) (*UploadSASResponse, error)
 }
 
-func NewListingService(baseUrl string, sharedSecret string) Service {
+func NewListingService(baseUrl string, sharedSecret string, eventPublisher events.Publisher, listingEvents queue.Publisher, notifier notifications.Service) Service {
 	// 1. Define the default headers
 	defaultHeaders := http.Header{}
 	defaultHeaders.Add("X-Request-ID", sharedSecret)
@@ -78,6 +116,9 @@ func NewListingService(baseUrl string, sharedSecret string) Service {
 			Client: httpClient,
 			URL:    baseUrl, // Store the base URL
 		},
+		events:        eventPublisher,
+		listingEvents: listingEvents,
+		notifier:      notifier,
 	}
 }
 
@@ -133,8 +174,7 @@ func (s *svc) CreateListing(ctx context.Context, req CreateListingRequest) (*Cre
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusCreated {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(bodyBytes))
+		return nil, upstreamError(resp)
 	}
 
 	var listing Listing
@@ -142,9 +182,38 @@ func (s *svc) CreateListing(ctx context.Context, req CreateListingRequest) (*Cre
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
+	s.publishListingEvent(ctx, events.ListingCreated, listing)
+
 	return &CreateListingResponse{Listing: &listing}, nil
 }
 
+// publishListingEvent queues a listing change for asynchronous processing (saved-search
+// alerts, price drops). Like offer events it is best-effort and never fails the request.
+func (s *svc) publishListingEvent(ctx context.Context, eventType string, listing Listing) {
+	if s.listingEvents == nil {
+		return
+	}
+	event := events.ListingEvent{
+		Type:       eventType,
+		ListingID:  listing.ID,
+		SellerID:   listing.UserID.String(),
+		OccurredAt: time.Now().UTC(),
+	}
+	if eventType == events.ListingPriceDropped {
+		price := listing.Price
+		event.OldPrice = listing.PreviousPrice
+		event.NewPrice = &price
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Failed to marshal listing event %s for listing %d: %v", eventType, listing.ID, err)
+		return
+	}
+	if err := s.listingEvents.Publish(ctx, payload); err != nil {
+		log.Printf("Failed to publish listing event %s for listing %d: %v", eventType, listing.ID, err)
+	}
+}
+
 func (s *svc) FetchAllListings(ctx context.Context, req FetchAllListingsRequest) (*FetchAllListingsResponse, error) {
 	fullURL := s.config.URL + "/listings/"
 	u, err := url.Parse(fullURL)
@@ -162,6 +231,9 @@ func (s *svc) FetchAllListings(ctx context.Context, req FetchAllListingsRequest)
 	if req.Sort != nil {
 		q.Set("sort", *req.Sort)
 	}
+	if req.Query != nil {
+		q.Set("q", *req.Query)
+	}
 	if req.Keywords != nil {
 		q.Set("keywords", *req.Keywords)
 	}
@@ -177,12 +249,23 @@ func (s *svc) FetchAllListings(ctx context.Context, req FetchAllListingsRequest)
 	if req.MaxPrice != nil {
 		q.Set("max_price", strconv.FormatInt(*req.MaxPrice, 10))
 	}
+	if req.Cursor != nil {
+		q.Set("cursor", *req.Cursor)
+	}
+	if req.SkipCount {
+		q.Set("skip_count", "true")
+	}
 	u.RawQuery = q.Encode()
 
 	httpReq, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
+	// The caller's role lets admins filter the feed by moderation status
+	if userID, roleID, err := s.extractUserAndRole(ctx); err == nil {
+		httpReq.Header.Set("X-User-ID", userID)
+		httpReq.Header.Set("X-Role-ID", roleID)
+	}
 
 	resp, err := s.config.Client.Do(httpReq)
 	if err != nil {
@@ -196,16 +279,18 @@ func (s *svc) FetchAllListings(ctx context.Context, req FetchAllListingsRequest)
 	}
 
 	var result struct {
-		Items []Listing `json:"items"`
-		Count int       `json:"count"`
+		Items      []Listing `json:"items"`
+		Count      *int64    `json:"count"`
+		NextCursor string    `json:"next_cursor"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	return &FetchAllListingsResponse{
-		Items: result.Items,
-		Count: result.Count,
+		Items:      result.Items,
+		Count:      result.Count,
+		NextCursor: result.NextCursor,
 	}, nil
 }
 
@@ -356,8 +441,7 @@ func (s *svc) UpdateListing(ctx context.Context, req UpdateListingRequest) (*Upd
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(bodyBytes))
+		return nil, upstreamError(resp)
 	}
 
 	var listing Listing
@@ -365,6 +449,21 @@ func (s *svc) UpdateListing(ctx context.Context, req UpdateListingRequest) (*Upd
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
+	if listing.PreviousPrice != nil && listing.Price < *listing.PreviousPrice {
+		s.publishListingEvent(ctx, events.ListingPriceDropped, listing)
+	}
+
+	// Let the owner know when someone else (an admin) changed their listing's status
+	if req.Status != nil && listing.UserID.String() != userID {
+		s.notify(ctx, &notifications.Notification{
+			UserID: listing.UserID.String(),
+			Type:   notifications.TypeListingStatus,
+			Title:  fmt.Sprintf("Your listing is now %s", listing.Status),
+			Body:   &listing.Title,
+			Data:   notificationData(map[string]any{"listing_id": listing.ID, "status": listing.Status}),
+		})
+	}
+
 	return &UpdateListingResponse{Listing: &listing}, nil
 }
 
@@ -768,8 +867,7 @@ func (s *svc) UpdateFlagListing(ctx context.Context, req UpdateFlagListingReques
 
 	// Listing-service returns StatusCreated for updates, but StatusOK is also acceptable
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(bodyBytes))
+		return nil, upstreamError(resp)
 	}
 
 	var flaggedListing FlaggedListing
@@ -777,9 +875,51 @@ func (s *svc) UpdateFlagListing(ctx context.Context, req UpdateFlagListingReques
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
+	s.notifyFlagClosed(ctx, flaggedListing)
+	if flaggedListing.Status == FlagStatusResolved {
+		s.notifyModerationOutcome(ctx, flaggedListing.Listing, flaggedListing.ResolutionNotes,
+			fmt.Sprintf("moderation_outcome:flag:%d", flaggedListing.FlagID))
+	}
+
 	return &UpdateFlagListingResponse{FlaggedListing: flaggedListing}, nil
 }
 
+// notifyFlagClosed closes the loop with the reporter once their flag is decided
+func (s *svc) notifyFlagClosed(ctx context.Context, fl FlaggedListing) {
+	if fl.ReporterUserID == nil || (fl.Status != FlagStatusResolved && fl.Status != FlagStatusDismissed) {
+		return
+	}
+	title := "Your report was reviewed and action was taken"
+	if fl.Status == FlagStatusDismissed {
+		title = "Your report was reviewed and dismissed"
+	}
+	dedupeKey := fmt.Sprintf("flag_resolved:flag:%d", fl.FlagID)
+	s.notify(ctx, &notifications.Notification{
+		UserID:    fl.ReporterUserID.String(),
+		Type:      notifications.TypeFlagResolved,
+		Title:     title,
+		Body:      fl.ResolutionNotes,
+		Data:      notificationData(map[string]any{"flag_id": fl.FlagID, "listing_id": fl.ListingID, "status": fl.Status}),
+		DedupeKey: &dedupeKey,
+	})
+}
+
+// notifyModerationOutcome tells the seller an admin upheld a report against their listing.
+// The notes are the admin's resolution notes; the seller can appeal from their outcomes list.
+func (s *svc) notifyModerationOutcome(ctx context.Context, listing Listing, notes *string, dedupeKey string) {
+	if listing.ID == 0 {
+		return
+	}
+	s.notify(ctx, &notifications.Notification{
+		UserID:    listing.UserID.String(),
+		Type:      notifications.TypeModerationOutcome,
+		Title:     "A moderator took action on your listing",
+		Body:      notes,
+		Data:      notificationData(map[string]any{"listing_id": listing.ID, "status": listing.Status}),
+		DedupeKey: &dedupeKey,
+	})
+}
+
 func (s *svc) DeleteFlagListing(ctx context.Context, req DeleteFlagListingRequest) (*DeleteFlagListingResponse, error) {
 	// Extract and validate user authentication
 	userID, roleID, err := s.extractUserAndRole(ctx)
@@ -1103,3 +1243,845 @@ func (s *svc) FetchSavedListings(ctx context.Context) (*FetchSavedListingsRespon
 
 	return &FetchSavedListingsResponse{SavedListings: savedListings}, nil
 }
+
+// upstreamError turns a non-2xx listing-service response into an AppError that keeps the status code
+func upstreamError(resp *http.Response) error {
+	bodyBytes, _ := io.ReadAll(resp.Body)
+	var errorResp struct {
+		Error string `json:"error"`
+	}
+	msg := string(bodyBytes)
+	if err := json.Unmarshal(bodyBytes, &errorResp); err == nil && errorResp.Error != "" {
+		msg = errorResp.Error
+	}
+	return common.NewAppError("LISTING_SERVICE_ERROR", resp.StatusCode, msg, nil)
+}
+
+// notify sends n to the recipient's inbox. Failures are logged and never fail the request.
+func (s *svc) notify(ctx context.Context, n *notifications.Notification) {
+	if s.notifier == nil {
+		return
+	}
+	if err := s.notifier.Notify(ctx, n); err != nil {
+		log.Printf("Failed to send %s notification to user %s: %v", n.Type, n.UserID, err)
+	}
+}
+
+func notificationData(v map[string]any) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// publishOfferEvent pushes an offer update to the recipient's WebSocket. Delivery is
+// best-effort: a failure is logged and never fails the request that caused it.
+func (s *svc) publishOfferEvent(ctx context.Context, recipientID string, subType string, offer Offer) {
+	if s.events == nil {
+		return
+	}
+	event := OfferEvent{
+		Type:        "offer",
+		SubType:     subType,
+		RecipientID: recipientID,
+		Offer:       offer,
+	}
+	if _, err := s.events.PublishToUser(ctx, recipientID, event); err != nil {
+		log.Printf("Failed to publish offer event %s for offer %d: %v", subType, offer.ID, err)
+	}
+}
+
+func (s *svc) CreateOffer(ctx context.Context, req CreateOfferRequest) (*CreateOfferResponse, error) {
+	userID, roleID, err := s.extractUserAndRole(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bodyBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	fullURL := fmt.Sprintf("%s/listings/%d/offers", s.config.URL, req.ListingID)
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", fullURL, bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-User-ID", userID)
+	httpReq.Header.Set("X-Role-ID", roleID)
+
+	resp, err := s.config.Client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, upstreamError(resp)
+	}
+
+	var offer Offer
+	if err := json.NewDecoder(resp.Body).Decode(&offer); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	s.publishOfferEvent(ctx, offer.SellerUserID.String(), "created", offer)
+
+	return &CreateOfferResponse{Offer: offer}, nil
+}
+
+func (s *svc) FetchListingOffers(ctx context.Context, listingID int64) (*FetchOffersResponse, error) {
+	fullURL := fmt.Sprintf("%s/listings/%d/offers", s.config.URL, listingID)
+	return s.fetchOffers(ctx, fullURL)
+}
+
+func (s *svc) FetchUserOffers(ctx context.Context, status *OfferStatus) (*FetchOffersResponse, error) {
+	u, err := url.Parse(s.config.URL + "/listings/offers")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+	if status != nil {
+		q := u.Query()
+		q.Set("status", string(*status))
+		u.RawQuery = q.Encode()
+	}
+	return s.fetchOffers(ctx, u.String())
+}
+
+func (s *svc) fetchOffers(ctx context.Context, fullURL string) (*FetchOffersResponse, error) {
+	userID, roleID, err := s.extractUserAndRole(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("X-User-ID", userID)
+	httpReq.Header.Set("X-Role-ID", roleID)
+
+	resp, err := s.config.Client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, upstreamError(resp)
+	}
+
+	var result FetchOffersResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if result.Offers == nil {
+		result.Offers = []Offer{}
+	}
+	return &result, nil
+}
+
+func (s *svc) RespondToOffer(ctx context.Context, req RespondOfferRequest) (*RespondOfferResponse, error) {
+	userID, roleID, err := s.extractUserAndRole(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bodyBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	fullURL := fmt.Sprintf("%s/listings/offers/%d/respond", s.config.URL, req.OfferID)
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", fullURL, bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-User-ID", userID)
+	httpReq.Header.Set("X-Role-ID", roleID)
+
+	resp, err := s.config.Client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, upstreamError(resp)
+	}
+
+	var result RespondOfferResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	// The offer's creator is the one waiting on this answer
+	creatorID := result.Offer.CreatedByUserID.String()
+	switch req.Action {
+	case OfferActionAccept:
+		s.publishOfferEvent(ctx, creatorID, "accepted", result.Offer)
+		for _, declined := range result.Declined {
+			s.publishOfferEvent(ctx, declined.BuyerUserID.String(), "declined", declined)
+		}
+	case OfferActionReject:
+		s.publishOfferEvent(ctx, creatorID, "rejected", result.Offer)
+	case OfferActionCounter:
+		if result.Counter != nil {
+			s.publishOfferEvent(ctx, creatorID, "countered", *result.Counter)
+		}
+	}
+
+	return &result, nil
+}
+
+func (s *svc) MarkListingSold(ctx context.Context, req MarkSoldRequest) (*TransactionResponse, error) {
+	bodyBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	fullURL := fmt.Sprintf("%s/listings/%d/sell", s.config.URL, req.ListingID)
+	return s.postTransaction(ctx, fullURL, bodyBytes, http.StatusCreated)
+}
+
+func (s *svc) ConfirmTransaction(ctx context.Context, transactionID int64) (*TransactionResponse, error) {
+	fullURL := fmt.Sprintf("%s/listings/transactions/%d/confirm", s.config.URL, transactionID)
+	resp, err := s.postTransaction(ctx, fullURL, nil, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := resp.Transaction
+	dedupeKey := fmt.Sprintf("listing_status:transaction:%d", tx.ID)
+	s.notify(ctx, &notifications.Notification{
+		UserID:    tx.SellerUserID.String(),
+		Type:      notifications.TypeListingStatus,
+		Title:     "The buyer confirmed your sale; your listing is now SOLD",
+		Data:      notificationData(map[string]any{"listing_id": tx.ListingID, "transaction_id": tx.ID, "status": StSold}),
+		DedupeKey: &dedupeKey,
+	})
+	return resp, nil
+}
+
+func (s *svc) CancelTransaction(ctx context.Context, transactionID int64) (*TransactionResponse, error) {
+	fullURL := fmt.Sprintf("%s/listings/transactions/%d/cancel", s.config.URL, transactionID)
+	return s.postTransaction(ctx, fullURL, nil, http.StatusOK)
+}
+
+func (s *svc) postTransaction(ctx context.Context, fullURL string, body []byte, wantStatus int) (*TransactionResponse, error) {
+	userID, roleID, err := s.extractUserAndRole(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", fullURL, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-User-ID", userID)
+	httpReq.Header.Set("X-Role-ID", roleID)
+
+	resp, err := s.config.Client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != wantStatus {
+		return nil, upstreamError(resp)
+	}
+
+	var transaction Transaction
+	if err := json.NewDecoder(resp.Body).Decode(&transaction); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &TransactionResponse{Transaction: transaction}, nil
+}
+
+func (s *svc) FetchUserTransactions(ctx context.Context, status *TransactionStatus) (*FetchTransactionsResponse, error) {
+	userID, roleID, err := s.extractUserAndRole(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(s.config.URL + "/listings/transactions")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+	if status != nil {
+		q := u.Query()
+		q.Set("status", string(*status))
+		u.RawQuery = q.Encode()
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("X-User-ID", userID)
+	httpReq.Header.Set("X-Role-ID", roleID)
+
+	resp, err := s.config.Client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, upstreamError(resp)
+	}
+
+	var result FetchTransactionsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if result.Transactions == nil {
+		result.Transactions = []Transaction{}
+	}
+	return &result, nil
+}
+
+func (s *svc) CreateReview(ctx context.Context, req CreateReviewRequest) (*CreateReviewResponse, error) {
+	userID, roleID, err := s.extractUserAndRole(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bodyBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	fullURL := fmt.Sprintf("%s/listings/%d/reviews", s.config.URL, req.ListingID)
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", fullURL, bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-User-ID", userID)
+	httpReq.Header.Set("X-Role-ID", roleID)
+
+	resp, err := s.config.Client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, upstreamError(resp)
+	}
+
+	var review Review
+	if err := json.NewDecoder(resp.Body).Decode(&review); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &CreateReviewResponse{Review: review}, nil
+}
+
+func (s *svc) FetchListingReviews(ctx context.Context, listingID int64) (*FetchReviewsResponse, error) {
+	fullURL := fmt.Sprintf("%s/listings/%d/reviews", s.config.URL, listingID)
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.config.Client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, upstreamError(resp)
+	}
+
+	var result FetchReviewsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if result.Reviews == nil {
+		result.Reviews = []Review{}
+	}
+	return &result, nil
+}
+
+func (s *svc) FetchUserReviews(ctx context.Context, userID string) (*FetchUserReviewsResponse, error) {
+	fullURL := fmt.Sprintf("%s/listings/reviews/users/%s", s.config.URL, url.PathEscape(userID))
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.config.Client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, upstreamError(resp)
+	}
+
+	var result FetchUserReviewsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if result.Reviews == nil {
+		result.Reviews = []Review{}
+	}
+	return &result, nil
+}
+
+func (s *svc) CreateSavedSearch(ctx context.Context, req CreateSavedSearchRequest) (*CreateSavedSearchResponse, error) {
+	userID, roleID, err := s.extractUserAndRole(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bodyBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	fullURL := s.config.URL + "/listings/searches"
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", fullURL, bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-User-ID", userID)
+	httpReq.Header.Set("X-Role-ID", roleID)
+
+	resp, err := s.config.Client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, upstreamError(resp)
+	}
+
+	var search SavedSearch
+	if err := json.NewDecoder(resp.Body).Decode(&search); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &CreateSavedSearchResponse{Search: search}, nil
+}
+
+func (s *svc) FetchSavedSearches(ctx context.Context) (*FetchSavedSearchesResponse, error) {
+	userID, roleID, err := s.extractUserAndRole(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	fullURL := s.config.URL + "/listings/searches"
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("X-User-ID", userID)
+	httpReq.Header.Set("X-Role-ID", roleID)
+
+	resp, err := s.config.Client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, upstreamError(resp)
+	}
+
+	var result FetchSavedSearchesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if result.Searches == nil {
+		result.Searches = []SavedSearch{}
+	}
+	return &result, nil
+}
+
+func (s *svc) DeleteSavedSearch(ctx context.Context, searchID int64) error {
+	userID, roleID, err := s.extractUserAndRole(ctx)
+	if err != nil {
+		return err
+	}
+
+	fullURL := fmt.Sprintf("%s/listings/searches/%d", s.config.URL, searchID)
+	httpReq, err := http.NewRequestWithContext(ctx, "DELETE", fullURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("X-User-ID", userID)
+	httpReq.Header.Set("X-Role-ID", roleID)
+
+	resp, err := s.config.Client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return upstreamError(resp)
+	}
+	return nil
+}
+
+func (s *svc) UpdateSavedListingAlerts(ctx context.Context, req UpdateSavedListingAlertsRequest) (*UpdateSavedListingAlertsResponse, error) {
+	userID, roleID, err := s.extractUserAndRole(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bodyBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	fullURL := fmt.Sprintf("%s/listings/save/%d/alerts", s.config.URL, req.ListingID)
+	httpReq, err := http.NewRequestWithContext(ctx, "PATCH", fullURL, bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-User-ID", userID)
+	httpReq.Header.Set("X-Role-ID", roleID)
+
+	resp, err := s.config.Client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, upstreamError(resp)
+	}
+
+	var result UpdateSavedListingAlertsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &result, nil
+}
+
+func (s *svc) RenewListing(ctx context.Context, listingID int64) (*RenewListingResponse, error) {
+	userID, roleID, err := s.extractUserAndRole(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	fullURL := fmt.Sprintf("%s/listings/%d/renew", s.config.URL, listingID)
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", fullURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-User-ID", userID)
+	httpReq.Header.Set("X-Role-ID", roleID)
+
+	resp, err := s.config.Client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, upstreamError(resp)
+	}
+
+	var listing Listing
+	if err := json.NewDecoder(resp.Body).Decode(&listing); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &RenewListingResponse{Listing: listing}, nil
+}
+
+func (s *svc) FetchListingHistory(ctx context.Context, listingID int64) (*FetchListingHistoryResponse, error) {
+	userID, roleID, err := s.extractUserAndRole(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if roleID != string(httplib.ADMIN) {
+		return nil, fmt.Errorf("admin access required")
+	}
+
+	fullURL := fmt.Sprintf("%s/listings/%d/history", s.config.URL, listingID)
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("X-User-ID", userID)
+	httpReq.Header.Set("X-Role-ID", roleID)
+
+	resp, err := s.config.Client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, upstreamError(resp)
+	}
+
+	var revisions []ListingRevision
+	if err := json.NewDecoder(resp.Body).Decode(&revisions); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &FetchListingHistoryResponse{Revisions: revisions, Count: len(revisions)}, nil
+}
+
+// moderationRequest calls a listing-service moderation route and decodes the 2xx response into out
+func (s *svc) moderationRequest(ctx context.Context, method string, fullURL string, body any, out any) error {
+	userID, roleID, err := s.extractUserAndRole(ctx)
+	if err != nil {
+		return err
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reqBody = bytes.NewBuffer(bodyBytes)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, fullURL, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-User-ID", userID)
+	httpReq.Header.Set("X-Role-ID", roleID)
+
+	resp, err := s.config.Client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return upstreamError(resp)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+func (s *svc) ClaimNextCase(ctx context.Context) (*ModerationCaseResponse, error) {
+	var c ModerationCase
+	if err := s.moderationRequest(ctx, "POST", s.config.URL+"/listings/moderation/claim", nil, &c); err != nil {
+		return nil, err
+	}
+	return &ModerationCaseResponse{Case: c}, nil
+}
+
+func (s *svc) FetchCases(ctx context.Context, req FetchCasesRequest) (*FetchCasesResponse, error) {
+	u, err := url.Parse(s.config.URL + "/listings/moderation/cases")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+	q := u.Query()
+	if req.Status != nil {
+		q.Set("status", string(*req.Status))
+	}
+	if req.AssignedTo != nil {
+		q.Set("assigned_to", *req.AssignedTo)
+	}
+	u.RawQuery = q.Encode()
+
+	var cases []ModerationCase
+	if err := s.moderationRequest(ctx, "GET", u.String(), nil, &cases); err != nil {
+		return nil, err
+	}
+	if cases == nil {
+		cases = []ModerationCase{}
+	}
+	return &FetchCasesResponse{Cases: cases, Count: len(cases)}, nil
+}
+
+func (s *svc) FetchCase(ctx context.Context, caseID int64) (*ModerationCaseResponse, error) {
+	var c ModerationCase
+	fullURL := fmt.Sprintf("%s/listings/moderation/cases/%d", s.config.URL, caseID)
+	if err := s.moderationRequest(ctx, "GET", fullURL, nil, &c); err != nil {
+		return nil, err
+	}
+	return &ModerationCaseResponse{Case: c}, nil
+}
+
+func (s *svc) AssignCase(ctx context.Context, req AssignCaseRequest) (*ModerationCaseResponse, error) {
+	var c ModerationCase
+	fullURL := fmt.Sprintf("%s/listings/moderation/cases/%d/assign", s.config.URL, req.CaseID)
+	if err := s.moderationRequest(ctx, "POST", fullURL, req, &c); err != nil {
+		return nil, err
+	}
+	return &ModerationCaseResponse{Case: c}, nil
+}
+
+func (s *svc) ReleaseCase(ctx context.Context, caseID int64) (*ModerationCaseResponse, error) {
+	var c ModerationCase
+	fullURL := fmt.Sprintf("%s/listings/moderation/cases/%d/release", s.config.URL, caseID)
+	if err := s.moderationRequest(ctx, "POST", fullURL, nil, &c); err != nil {
+		return nil, err
+	}
+	return &ModerationCaseResponse{Case: c}, nil
+}
+
+func (s *svc) ResolveCase(ctx context.Context, req ResolveCaseRequest) (*ModerationCaseResponse, error) {
+	var c ModerationCase
+	fullURL := fmt.Sprintf("%s/listings/moderation/cases/%d/resolve", s.config.URL, req.CaseID)
+	if err := s.moderationRequest(ctx, "POST", fullURL, req, &c); err != nil {
+		return nil, err
+	}
+
+	for _, fl := range c.Flags {
+		s.notifyFlagClosed(ctx, fl)
+	}
+	if c.Status == FlagStatusResolved && c.Listing != nil {
+		s.notifyModerationOutcome(ctx, *c.Listing, c.ResolutionNotes, fmt.Sprintf("moderation_outcome:case:%d", c.ID))
+	}
+	return &ModerationCaseResponse{Case: c}, nil
+}
+
+func (s *svc) FetchModerationStats(ctx context.Context) (*FetchModerationStatsResponse, error) {
+	var stats []AdminWorkload
+	if err := s.moderationRequest(ctx, "GET", s.config.URL+"/listings/moderation/stats", nil, &stats); err != nil {
+		return nil, err
+	}
+	if stats == nil {
+		stats = []AdminWorkload{}
+	}
+	return &FetchModerationStatsResponse{Admins: stats}, nil
+}
+
+// BulkUpdateFlags applies one moderation action to many flags. Reporters hear about each
+// of their flags; sellers get one notice per listing when reports were upheld.
+func (s *svc) BulkUpdateFlags(ctx context.Context, req BulkFlagRequest) (*BulkFlagResponse, error) {
+	var res BulkFlagResponse
+	if err := s.moderationRequest(ctx, "POST", s.config.URL+"/listings/moderation/flags/bulk", req, &res); err != nil {
+		return nil, err
+	}
+
+	notified := make(map[int64]bool)
+	for _, item := range res.Results {
+		if item.Flag == nil {
+			continue
+		}
+		s.notifyFlagClosed(ctx, *item.Flag)
+		if item.Flag.Status == FlagStatusResolved && !notified[item.ListingID] {
+			notified[item.ListingID] = true
+			s.notifyModerationOutcome(ctx, item.Flag.Listing, item.Flag.ResolutionNotes,
+				fmt.Sprintf("moderation_outcome:bulk:%s:listing:%d", res.BulkActionID, item.ListingID))
+		}
+	}
+	return &res, nil
+}
+
+func (s *svc) FetchModerationOutcomes(ctx context.Context) (*FetchModerationOutcomesResponse, error) {
+	var outcomes []ModerationOutcome
+	if err := s.moderationRequest(ctx, "GET", s.config.URL+"/listings/moderation/outcomes", nil, &outcomes); err != nil {
+		return nil, err
+	}
+	if outcomes == nil {
+		outcomes = []ModerationOutcome{}
+	}
+	return &FetchModerationOutcomesResponse{Outcomes: outcomes}, nil
+}
+
+func (s *svc) CreateAppeal(ctx context.Context, req CreateAppealRequest) (*AppealResponse, error) {
+	var a ListingAppeal
+	fullURL := fmt.Sprintf("%s/listings/flag/%d/appeal", s.config.URL, req.FlagID)
+	if err := s.moderationRequest(ctx, "POST", fullURL, req, &a); err != nil {
+		return nil, err
+	}
+	return &AppealResponse{Appeal: a}, nil
+}
+
+func (s *svc) FetchAppeals(ctx context.Context, status *AppealStatus) (*FetchAppealsResponse, error) {
+	u, err := url.Parse(s.config.URL + "/listings/appeals")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+	if status != nil {
+		q := u.Query()
+		q.Set("status", string(*status))
+		u.RawQuery = q.Encode()
+	}
+
+	var appeals []ListingAppeal
+	if err := s.moderationRequest(ctx, "GET", u.String(), nil, &appeals); err != nil {
+		return nil, err
+	}
+	if appeals == nil {
+		appeals = []ListingAppeal{}
+	}
+	return &FetchAppealsResponse{Appeals: appeals, Count: len(appeals)}, nil
+}
+
+func (s *svc) FetchAppeal(ctx context.Context, appealID int64) (*AppealResponse, error) {
+	var a ListingAppeal
+	fullURL := fmt.Sprintf("%s/listings/appeals/%d", s.config.URL, appealID)
+	if err := s.moderationRequest(ctx, "GET", fullURL, nil, &a); err != nil {
+		return nil, err
+	}
+	return &AppealResponse{Appeal: a}, nil
+}
+
+func (s *svc) DecideAppeal(ctx context.Context, req DecideAppealRequest) (*AppealResponse, error) {
+	var a ListingAppeal
+	fullURL := fmt.Sprintf("%s/listings/appeals/%d/decide", s.config.URL, req.AppealID)
+	if err := s.moderationRequest(ctx, "POST", fullURL, req, &a); err != nil {
+		return nil, err
+	}
+
+	title := "Your appeal was reviewed and the decision stands"
+	if a.Status == AppealOverturned {
+		// Other upheld or open flags keep the listing down even when this one is overturned
+		title = "Your appeal was accepted"
+		if a.Flag != nil && a.Flag.Listing.Status == StAvailable {
+			title = "Your appeal was accepted and your listing reinstated"
+		}
+	}
+	dedupeKey := fmt.Sprintf("appeal_decided:appeal:%d", a.ID)
+	s.notify(ctx, &notifications.Notification{
+		UserID:    a.SellerUserID.String(),
+		Type:      notifications.TypeAppealDecided,
+		Title:     title,
+		Body:      a.DecisionNotes,
+		Data:      notificationData(map[string]any{"appeal_id": a.ID, "flag_id": a.FlagID, "listing_id": a.ListingID, "status": a.Status}),
+		DedupeKey: &dedupeKey,
+	})
+	return &AppealResponse{Appeal: a}, nil
+}
+
+// CreateChatAttachment gets an upload URL for an image the caller will send in chat. The
+// listing service owns blob storage, so chat uploads are issued there too.
+func (s *svc) CreateChatAttachment(ctx context.Context, req CreateChatAttachmentRequest) (*UploadSASResponse, error) {
+	var upload UploadSASResponse
+	if err := s.moderationRequest(ctx, "POST", s.config.URL+"/listings/attachments", req, &upload); err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
@@ -9,6 +9,23 @@ const (
 	USER  UserRole = "1" // buyer, seller both are same roles
 )
 
+type AccountStatus string
+
+const (
+	AccountActive    AccountStatus = "ACTIVE"
+	AccountSuspended AccountStatus = "SUSPENDED" // until SuspendedUntil, or until an admin lifts it
+	AccountBanned    AccountStatus = "BANNED"
+)
+
+// AccountAction is an admin action recorded in a user's account status history
+type AccountAction string
+
+const (
+	AccountActionSuspend   AccountAction = "SUSPEND"
+	AccountActionUnsuspend AccountAction = "UNSUSPEND"
+	AccountActionBan       AccountAction = "BAN"
+)
+
 type Contact struct {
 	Email string
 }
@@ -22,6 +39,47 @@ type User struct {
 	Contact   Contact   `json:"contact" db:"contact"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	// Account state, set by admins. Empty in search results.
+	AccountStatus  AccountStatus `json:"account_status,omitempty" db:"account_status"`
+	SuspendedUntil *time.Time    `json:"suspended_until,omitempty" db:"suspended_until"`
+	StatusReason   *string       `json:"status_reason,omitempty" db:"status_reason"`
+
+	// EmailVerifiedAt is when the user confirmed their campus email; nil until they do
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty" db:"email_verified_at"`
+
+	// Rating aggregates the reviews buyers left for the user as a seller; only set on profile reads
+	Rating *RatingSummary `json:"rating,omitempty" db:"-"`
+}
+
+// Restricted reports whether the user is banned, or suspended and the suspension hasn't lapsed
+func (u *User) Restricted(now time.Time) bool {
+	switch u.AccountStatus {
+	case AccountBanned:
+		return true
+	case AccountSuspended:
+		return u.SuspendedUntil == nil || u.SuspendedUntil.After(now)
+	}
+	return false
+}
+
+// AccountStatusEvent is one entry in the audit trail of admin actions on an account
+type AccountStatusEvent struct {
+	ID             int64         `json:"id" db:"id"`
+	UserID         string        `json:"user_id" db:"user_id"`
+	Action         AccountAction `json:"action" db:"action"`
+	PreviousStatus AccountStatus `json:"previous_status" db:"previous_status"`
+	NewStatus      AccountStatus `json:"new_status" db:"new_status"`
+	SuspendedUntil *time.Time    `json:"suspended_until,omitempty" db:"suspended_until"`
+	Reason         *string       `json:"reason,omitempty" db:"reason"`
+	AdminUserID    *string       `json:"admin_user_id,omitempty" db:"admin_user_id"`
+	CreatedAt      time.Time     `json:"created_at" db:"created_at"`
+}
+
+// RatingSummary is the average and count of a user's reviews
+type RatingSummary struct {
+	Average     float64 `json:"average"` // 0 when ReviewCount is 0
+	ReviewCount int     `json:"review_count"`
 }
 
 // UserAuth contains static authentication details (password)
@@ -32,12 +90,30 @@ type UserAuth struct {
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
 
-// UserLoginAuth contains current login session details (tokens)
-type UserLoginAuth struct {
-	UserId       string    `json:"user_id" db:"user_id"`
-	AccessToken  string    `json:"-" db:"access_token"`  // Hidden from JSON responses
-	RefreshToken string    `json:"-" db:"refresh_token"` // Hidden from JSON responses
-	ExpiresAt    time.Time `json:"expires_at" db:"expires_at"`
-	CreatedAt    time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+// SessionRevokeReason records why a login session ended early
+type SessionRevokeReason string
+
+const (
+	SessionLogout            SessionRevokeReason = "LOGOUT"
+	SessionUserRevoked       SessionRevokeReason = "USER_REVOKED" // signed out from another device
+	SessionRefreshTokenReuse SessionRevokeReason = "REFRESH_TOKEN_REUSE"
+	SessionPasswordChanged   SessionRevokeReason = "PASSWORD_CHANGED"
+	SessionAccountRestricted SessionRevokeReason = "ACCOUNT_RESTRICTED"
+)
+
+// UserSession is a login on one device. It accepts a single refresh token at a time;
+// each refresh rotates RefreshTokenID.
+type UserSession struct {
+	SessionID      string     `json:"session_id" db:"session_id"`
+	UserId         string     `json:"user_id" db:"user_id"`
+	RefreshTokenID string     `json:"-" db:"refresh_token_id"`
+	UserAgent      string     `json:"user_agent" db:"user_agent"`
+	IPAddress      string     `json:"ip_address" db:"ip_address"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	LastUsedAt     time.Time  `json:"last_used_at" db:"last_used_at"`
+	ExpiresAt      time.Time  `json:"expires_at" db:"expires_at"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+
+	// Current marks the session the request was made from
+	Current bool `json:"current" db:"-"`
 }
@@ -0,0 +1,217 @@
+package notifications
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	httplib "github.com/kunal768/cmpe202/http-lib"
+)
+
+type Endpoints struct {
+	service Service
+}
+
+func NewEndpoints(service Service) *Endpoints {
+	return &Endpoints{
+		service: service,
+	}
+}
+
+// userIDFromContext returns the authenticated user ID set by AuthMiddleware
+func userIDFromContext(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID, ok := r.Context().Value(httplib.ContextKey("userId")).(string)
+	if !ok || userID == "" {
+		httplib.WriteJSON(w, http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User ID not found in context",
+		})
+		return "", false
+	}
+	return userID, true
+}
+
+// checkAdminRole checks if the current user is an admin
+func checkAdminRole(r *http.Request) bool {
+	userRole, ok := r.Context().Value(httplib.ContextKey("userRole")).(string)
+	return ok && userRole == "0"
+}
+
+// ListNotificationsHandler returns a page of the caller's inbox, newest first
+func (e *Endpoints) ListNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	page := 1
+	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+	limit := 20
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+
+	response, err := e.service.ListNotifications(r.Context(), userID, unreadOnly, page, limit)
+	if err != nil {
+		httplib.WriteJSON(w, http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to fetch notifications",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, response)
+}
+
+// UnreadCountHandler returns the caller's unread notification count
+func (e *Endpoints) UnreadCountHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	count, err := e.service.UnreadCount(r.Context(), userID)
+	if err != nil {
+		httplib.WriteJSON(w, http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to count notifications",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, map[string]int{"unread_count": count})
+}
+
+// MarkReadHandler marks one of the caller's notifications read
+func (e *Endpoints) MarkReadHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	notificationID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: "Invalid notification ID format",
+		})
+		return
+	}
+
+	if err := e.service.MarkRead(r.Context(), userID, notificationID); err != nil {
+		if errors.Is(err, ErrNotificationNotFound) {
+			httplib.WriteJSON(w, http.StatusNotFound, ErrorResponse{
+				Error:   "Notification not found",
+				Message: err.Error(),
+			})
+			return
+		}
+		httplib.WriteJSON(w, http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to mark notification read",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, map[string]string{"message": "Notification marked as read"})
+}
+
+// MarkAllReadHandler marks all of the caller's notifications read
+func (e *Endpoints) MarkAllReadHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	updated, err := e.service.MarkAllRead(r.Context(), userID)
+	if err != nil {
+		httplib.WriteJSON(w, http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to mark notifications read",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, map[string]int64{"updated": updated})
+}
+
+// SendAdminMessageHandler sends an admin notice to a user's inbox (admin only)
+func (e *Endpoints) SendAdminMessageHandler(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminRole(r) {
+		httplib.WriteJSON(w, http.StatusForbidden, ErrorResponse{
+			Error:   "Forbidden",
+			Message: "Admin access required",
+		})
+		return
+	}
+
+	var req AdminMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: "Failed to decode request body",
+		})
+		return
+	}
+	if _, err := uuid.Parse(req.UserID); err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Message: "A valid user_id is required",
+		})
+		return
+	}
+	if strings.TrimSpace(req.Title) == "" || len(req.Title) > 255 {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Message: "Title is required and must be at most 255 characters",
+		})
+		return
+	}
+
+	n := &Notification{
+		UserID: req.UserID,
+		Type:   TypeAdminMessage,
+		Title:  req.Title,
+		Body:   req.Body,
+	}
+	if err := e.service.Notify(r.Context(), n); err != nil {
+		httplib.WriteJSON(w, http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to send message",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusCreated, n)
+}
+
+// RegisterRoutes registers all notification routes with proper middleware
+func (e *Endpoints) RegisterRoutes(mux *http.ServeMux, dbPool *pgxpool.Pool) {
+	// Default protected chain: JSON -> Auth -> Role
+	protected := func(h http.Handler) http.Handler {
+		return httplib.AuthMiddleWare(
+			httplib.RoleInjectionMiddleWare(dbPool)(
+				httplib.JSONRequestDecoder(h),
+			),
+		)
+	}
+
+	mux.Handle("GET /api/notifications", protected(http.HandlerFunc(e.ListNotificationsHandler)))
+	mux.Handle("GET /api/notifications/unread-count", protected(http.HandlerFunc(e.UnreadCountHandler)))
+	mux.Handle("PATCH /api/notifications/{id}/read", protected(http.HandlerFunc(e.MarkReadHandler)))
+	mux.Handle("POST /api/notifications/read-all", protected(http.HandlerFunc(e.MarkAllReadHandler)))
+
+	// Admin-only route
+	mux.Handle("POST /api/notifications/admin", protected(http.HandlerFunc(e.SendAdminMessageHandler)))
+}
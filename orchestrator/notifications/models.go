@@ -0,0 +1,98 @@
+package notifications
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Notification types, sent as the subType of the WebSocket notification message
+const (
+	TypeNewMessage    = "new_message"
+	TypeFlagResolved  = "flag_resolved"
+	TypeListingStatus = "listing_status"
+	// TypeListingExpiring is written directly to the notifications table by the
+	// listing-service expiry worker
+	TypeListingExpiring = "listing_expiring"
+	TypeAdminMessage    = "admin_message"
+	TypeSavedSearch     = "saved_search"
+	TypePriceDrop       = "price_drop"
+	// TypeModerationOutcome tells a seller an admin acted on their listing;
+	// TypeAppealDecided tells them how their appeal of that decision went
+	TypeModerationOutcome = "moderation_outcome"
+	TypeAppealDecided     = "appeal_decided"
+
+	// SubTypeRead is pushed with the new unread count after notifications are marked read,
+	// so every open tab updates its badge
+	SubTypeRead = "read"
+)
+
+// Notification is a persisted, per-user notification. It is pushed live when the
+// user is connected and stays in the table for when they are not.
+type Notification struct {
+	ID        int64           `json:"id"`
+	UserID    string          `json:"user_id"`
+	Type      string          `json:"type"`
+	Title     string          `json:"title"`
+	Body      *string         `json:"body,omitempty"`
+	Data      json.RawMessage `json:"data"`
+	DedupeKey *string         `json:"-"`
+	ReadAt    *time.Time      `json:"read_at,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// NotificationEvent is the payload published to user:{id}:messages. The events-server
+// hub forwards type "notification" messages to the user's WebSocket.
+type NotificationEvent struct {
+	Type         string        `json:"type"`    // "notification"
+	SubType      string        `json:"subType"` // the notification type
+	Count        int           `json:"count"`   // unread notifications
+	RecipientID  string        `json:"recipientId"`
+	Notification *Notification `json:"data,omitempty"`
+}
+
+// SavedSearchMatch is a saved search that a newly created listing satisfies
+type SavedSearchMatch struct {
+	SearchID     int64
+	SearchName   string
+	UserID       string
+	ListingID    int64
+	ListingTitle string
+	ListingPrice int64
+}
+
+// PriceDropRecipient is a user who saved a listing and still wants its price alerts
+type PriceDropRecipient struct {
+	UserID       string
+	ListingTitle string
+}
+
+// NotificationRequest is a notification published by another service (e.g. the chat
+// consumer) to the notifications queue
+type NotificationRequest struct {
+	UserID    string          `json:"userId"`
+	Type      string          `json:"type"`
+	Title     string          `json:"title"`
+	Body      *string         `json:"body,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	DedupeKey *string         `json:"dedupeKey,omitempty"`
+}
+
+// ListNotificationsResponse is one page of a user's inbox
+type ListNotificationsResponse struct {
+	Notifications []Notification `json:"notifications"`
+	UnreadCount   int            `json:"unread_count"`
+	HasMore       bool           `json:"has_more"`
+}
+
+// AdminMessageRequest is an admin notice sent to a user's inbox
+type AdminMessageRequest struct {
+	UserID string  `json:"user_id"`
+	Title  string  `json:"title"`
+	Body   *string `json:"body,omitempty"`
+}
+
+// ErrorResponse represents an error response
+type ErrorResponse struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
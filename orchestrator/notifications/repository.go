@@ -0,0 +1,174 @@
+package notifications
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository interface {
+	// CreateNotification inserts n and fills in its ID and CreatedAt. It returns false
+	// when a notification with the same dedupe key already exists for the user.
+	CreateNotification(ctx context.Context, n *Notification) (bool, error)
+	CountUnread(ctx context.Context, userID string) (int, error)
+	ListNotifications(ctx context.Context, userID string, unreadOnly bool, limit int, offset int) ([]Notification, error)
+	MarkRead(ctx context.Context, userID string, notificationID int64) (bool, error)
+	MarkAllRead(ctx context.Context, userID string) (int64, error)
+	MatchSavedSearches(ctx context.Context, listingID int64) ([]SavedSearchMatch, error)
+	GetPriceDropRecipients(ctx context.Context, listingID int64) ([]PriceDropRecipient, error)
+}
+
+type repo struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Repository {
+	return &repo{
+		db: db,
+	}
+}
+
+func (r *repo) CreateNotification(ctx context.Context, n *Notification) (bool, error) {
+	query := `
+		INSERT INTO notifications (user_id, type, title, body, data, dedupe_key)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, dedupe_key) DO NOTHING
+		RETURNING id, created_at
+	`
+	data := n.Data
+	if data == nil {
+		data = []byte("{}")
+	}
+
+	err := r.db.QueryRow(ctx, query, n.UserID, n.Type, n.Title, n.Body, string(data), n.DedupeKey).Scan(&n.ID, &n.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *repo) CountUnread(ctx context.Context, userID string) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL", userID).Scan(&count)
+	return count, err
+}
+
+func (r *repo) ListNotifications(ctx context.Context, userID string, unreadOnly bool, limit int, offset int) ([]Notification, error) {
+	query := `
+		SELECT id, user_id::text, type, title, body, data, read_at, created_at
+		FROM notifications
+		WHERE user_id = $1 AND ($2 = false OR read_at IS NULL)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.db.Query(ctx, query, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Notification
+	for rows.Next() {
+		var n Notification
+		var data []byte
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Type, &n.Title, &n.Body, &data, &n.ReadAt, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		n.Data = data
+		out = append(out, n)
+	}
+	return out, rows.Err()
+}
+
+// MarkRead marks one of the user's notifications read. It returns false when the
+// notification does not exist or belongs to someone else.
+func (r *repo) MarkRead(ctx context.Context, userID string, notificationID int64) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE notifications SET read_at = COALESCE(read_at, NOW())
+		WHERE id = $1 AND user_id = $2
+	`, notificationID, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *repo) MarkAllRead(ctx context.Context, userID string) (int64, error) {
+	tag, err := r.db.Exec(ctx, "UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL", userID)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// MatchSavedSearches evaluates a listing against every other user's saved searches.
+// The conditions mirror the listing feed: a full-text query takes precedence over
+// keywords, which match any keyword in the title or description.
+func (r *repo) MatchSavedSearches(ctx context.Context, listingID int64) ([]SavedSearchMatch, error) {
+	query := `
+		SELECT s.id, s.name, s.user_id::text, l.id, l.title, l.price
+		FROM listings l
+		JOIN saved_searches s ON s.user_id <> l.user_id
+		WHERE l.id = $1
+			AND l.status = 'AVAILABLE'
+			AND (s.category IS NULL OR s.category = l.category)
+			AND (s.min_price IS NULL OR l.price >= s.min_price)
+			AND (s.max_price IS NULL OR l.price <= s.max_price)
+			AND (s.query IS NULL OR l.search_vector @@ websearch_to_tsquery('english', s.query))
+			AND (s.query IS NOT NULL OR cardinality(s.keywords) = 0 OR EXISTS (
+				SELECT 1 FROM unnest(s.keywords) AS k
+				WHERE l.title ILIKE '%' || k || '%' OR l.description ILIKE '%' || k || '%'
+			))
+		ORDER BY s.user_id, s.id
+	`
+
+	rows, err := r.db.Query(ctx, query, listingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matches []SavedSearchMatch
+	for rows.Next() {
+		var m SavedSearchMatch
+		if err := rows.Scan(&m.SearchID, &m.SearchName, &m.UserID, &m.ListingID, &m.ListingTitle, &m.ListingPrice); err != nil {
+			return nil, err
+		}
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
+
+// GetPriceDropRecipients returns the users who saved the listing without opting out of
+// price alerts. The seller is excluded in case they saved their own listing.
+func (r *repo) GetPriceDropRecipients(ctx context.Context, listingID int64) ([]PriceDropRecipient, error) {
+	query := `
+		SELECT sl.user_id::text, l.title
+		FROM saved_listings sl
+		JOIN listings l ON l.id = sl.listing_id
+		WHERE sl.listing_id = $1
+			AND sl.price_alerts
+			AND sl.user_id <> l.user_id
+	`
+
+	rows, err := r.db.Query(ctx, query, listingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recipients []PriceDropRecipient
+	for rows.Next() {
+		var rcpt PriceDropRecipient
+		if err := rows.Scan(&rcpt.UserID, &rcpt.ListingTitle); err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, rcpt)
+	}
+	return recipients, rows.Err()
+}
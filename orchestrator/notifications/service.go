@@ -0,0 +1,280 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/kunal768/cmpe202/orchestrator/internal/events"
+)
+
+type Service interface {
+	// HandleListingEvent is the queue handler for the listing events queue
+	HandleListingEvent(ctx context.Context, message []byte) error
+	// HandleNotificationRequest is the queue handler for the notifications queue
+	HandleNotificationRequest(ctx context.Context, message []byte) error
+	// Notify persists n and pushes it to the user if they are connected
+	Notify(ctx context.Context, n *Notification) error
+	ListNotifications(ctx context.Context, userID string, unreadOnly bool, page int, limit int) (*ListNotificationsResponse, error)
+	UnreadCount(ctx context.Context, userID string) (int, error)
+	MarkRead(ctx context.Context, userID string, notificationID int64) error
+	MarkAllRead(ctx context.Context, userID string) (int64, error)
+}
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+type service struct {
+	repo      Repository
+	publisher events.Publisher
+	presence  events.PresenceChecker
+}
+
+// NewService creates the notification service. publisher may be nil, in which case
+// notifications are only persisted; presence may be nil to always attempt delivery.
+func NewService(repo Repository, publisher events.Publisher, presence events.PresenceChecker) Service {
+	return &service{
+		repo:      repo,
+		publisher: publisher,
+		presence:  presence,
+	}
+}
+
+func (s *service) HandleListingEvent(ctx context.Context, message []byte) error {
+	var event events.ListingEvent
+	if err := json.Unmarshal(message, &event); err != nil {
+		// A malformed message will never succeed; drop it
+		log.Printf("Failed to unmarshal listing event: %v", err)
+		return nil
+	}
+
+	switch event.Type {
+	case events.ListingCreated:
+		return s.alertSavedSearches(ctx, event.ListingID)
+	case events.ListingPriceDropped:
+		return s.alertPriceDrop(ctx, event)
+	default:
+		return nil
+	}
+}
+
+func (s *service) HandleNotificationRequest(ctx context.Context, message []byte) error {
+	var req NotificationRequest
+	if err := json.Unmarshal(message, &req); err != nil {
+		// A malformed message will never succeed; drop it
+		log.Printf("Failed to unmarshal notification request: %v", err)
+		return nil
+	}
+	if req.UserID == "" || req.Type == "" || req.Title == "" {
+		log.Printf("Dropping notification request missing userId, type or title")
+		return nil
+	}
+
+	return s.Notify(ctx, &Notification{
+		UserID:    req.UserID,
+		Type:      req.Type,
+		Title:     req.Title,
+		Body:      req.Body,
+		Data:      req.Data,
+		DedupeKey: req.DedupeKey,
+	})
+}
+
+// alertSavedSearches notifies each user with a saved search matching the listing.
+// A user with several matching searches gets a single notification.
+func (s *service) alertSavedSearches(ctx context.Context, listingID int64) error {
+	matches, err := s.repo.MatchSavedSearches(ctx, listingID)
+	if err != nil {
+		return fmt.Errorf("failed to match saved searches for listing %d: %w", listingID, err)
+	}
+
+	notified := make(map[string]bool)
+	for _, m := range matches {
+		if notified[m.UserID] {
+			continue
+		}
+		notified[m.UserID] = true
+
+		data, err := json.Marshal(map[string]any{
+			"listing_id":        m.ListingID,
+			"listing_title":     m.ListingTitle,
+			"listing_price":     m.ListingPrice,
+			"saved_search_id":   m.SearchID,
+			"saved_search_name": m.SearchName,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal notification data: %w", err)
+		}
+		dedupeKey := fmt.Sprintf("saved_search:listing:%d", m.ListingID)
+		body := m.ListingTitle
+		n := &Notification{
+			UserID:    m.UserID,
+			Type:      TypeSavedSearch,
+			Title:     fmt.Sprintf("New listing matches \"%s\"", m.SearchName),
+			Body:      &body,
+			Data:      data,
+			DedupeKey: &dedupeKey,
+		}
+		if err := s.Notify(ctx, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// alertPriceDrop notifies every user who saved the listing and kept price alerts on
+func (s *service) alertPriceDrop(ctx context.Context, event events.ListingEvent) error {
+	if event.OldPrice == nil || event.NewPrice == nil || *event.NewPrice >= *event.OldPrice {
+		return nil
+	}
+
+	recipients, err := s.repo.GetPriceDropRecipients(ctx, event.ListingID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch savers of listing %d: %w", event.ListingID, err)
+	}
+
+	for _, rcpt := range recipients {
+		data, err := json.Marshal(map[string]any{
+			"listing_id":    event.ListingID,
+			"listing_title": rcpt.ListingTitle,
+			"old_price":     *event.OldPrice,
+			"new_price":     *event.NewPrice,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal notification data: %w", err)
+		}
+		// One notification per price change event, so a redelivered event is not sent twice
+		dedupeKey := fmt.Sprintf("price_drop:listing:%d:%d", event.ListingID, event.OccurredAt.UnixNano())
+		body := rcpt.ListingTitle
+		n := &Notification{
+			UserID:    rcpt.UserID,
+			Type:      TypePriceDrop,
+			Title:     "Price drop on a listing you saved",
+			Body:      &body,
+			Data:      data,
+			DedupeKey: &dedupeKey,
+		}
+		if err := s.Notify(ctx, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *service) Notify(ctx context.Context, n *Notification) error {
+	created, err := s.repo.CreateNotification(ctx, n)
+	if err != nil {
+		return fmt.Errorf("failed to save notification for user %s: %w", n.UserID, err)
+	}
+	if !created {
+		// Already notified (redelivered event)
+		return nil
+	}
+	s.push(ctx, n)
+	return nil
+}
+
+// push sends n to the user's WebSocket if they are online. Delivery is best-effort: the
+// notification is already persisted, so offline users find it in their inbox.
+func (s *service) push(ctx context.Context, n *Notification) {
+	if s.publisher == nil {
+		return
+	}
+	if s.presence != nil {
+		online, err := s.presence.IsOnline(ctx, n.UserID)
+		if err != nil {
+			log.Printf("Failed to check presence for user %s: %v", n.UserID, err)
+			return
+		}
+		if !online {
+			return
+		}
+	}
+
+	unread, err := s.repo.CountUnread(ctx, n.UserID)
+	if err != nil {
+		log.Printf("Failed to count unread notifications for user %s: %v", n.UserID, err)
+		return
+	}
+
+	event := NotificationEvent{
+		Type:         "notification",
+		SubType:      n.Type,
+		Count:        unread,
+		RecipientID:  n.UserID,
+		Notification: n,
+	}
+	if _, err := s.publisher.PublishToUser(ctx, n.UserID, event); err != nil {
+		log.Printf("Failed to publish notification %d to user %s: %v", n.ID, n.UserID, err)
+	}
+}
+
+func (s *service) ListNotifications(ctx context.Context, userID string, unreadOnly bool, page int, limit int) (*ListNotificationsResponse, error) {
+	// Fetch one extra row to know whether there is another page
+	items, err := s.repo.ListNotifications(ctx, userID, unreadOnly, limit+1, (page-1)*limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	unread, err := s.repo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+
+	hasMore := len(items) > limit
+	if hasMore {
+		items = items[:limit]
+	}
+	if items == nil {
+		items = []Notification{}
+	}
+	return &ListNotificationsResponse{Notifications: items, UnreadCount: unread, HasMore: hasMore}, nil
+}
+
+func (s *service) UnreadCount(ctx context.Context, userID string) (int, error) {
+	return s.repo.CountUnread(ctx, userID)
+}
+
+func (s *service) MarkRead(ctx context.Context, userID string, notificationID int64) error {
+	found, err := s.repo.MarkRead(ctx, userID, notificationID)
+	if err != nil {
+		return fmt.Errorf("failed to mark notification read: %w", err)
+	}
+	if !found {
+		return ErrNotificationNotFound
+	}
+	s.pushUnreadCount(ctx, userID)
+	return nil
+}
+
+func (s *service) MarkAllRead(ctx context.Context, userID string) (int64, error) {
+	updated, err := s.repo.MarkAllRead(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+	if updated > 0 {
+		s.pushUnreadCount(ctx, userID)
+	}
+	return updated, nil
+}
+
+// pushUnreadCount tells the user's connected clients the new unread count
+func (s *service) pushUnreadCount(ctx context.Context, userID string) {
+	if s.publisher == nil {
+		return
+	}
+	unread, err := s.repo.CountUnread(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to count unread notifications for user %s: %v", userID, err)
+		return
+	}
+	event := NotificationEvent{
+		Type:        "notification",
+		SubType:     SubTypeRead,
+		Count:       unread,
+		RecipientID: userID,
+	}
+	if _, err := s.publisher.PublishToUser(ctx, userID, event); err != nil {
+		log.Printf("Failed to publish unread count to user %s: %v", userID, err)
+	}
+}
@@ -7,16 +7,21 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"regexp"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
+	httplib "github.com/kunal768/cmpe202/http-lib"
 	dbclient "github.com/kunal768/cmpe202/orchestrator/clients/db"
 	mongoclient "github.com/kunal768/cmpe202/orchestrator/clients/mongo"
+	"github.com/kunal768/cmpe202/orchestrator/internal/mail"
 	"github.com/kunal768/cmpe202/orchestrator/internal/queue"
+	"github.com/kunal768/cmpe202/orchestrator/internal/sessions"
 	"github.com/kunal768/cmpe202/orchestrator/listings"
 	"github.com/kunal768/cmpe202/orchestrator/users"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -39,8 +44,42 @@ var (
 	mu              sync.Mutex
 	setupOnce       sync.Once
 	teardownOnce    sync.Once
+	testMail        = &testMailer{}
 )
 
+// testMailer keeps the mail the service sends so tests can follow emailed links
+type testMailer struct {
+	mu       sync.Mutex
+	messages []mail.Message
+}
+
+func (m *testMailer) Send(_ context.Context, msg mail.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+var mailTokenPattern = regexp.MustCompile(`token=([^\s&]+)`)
+
+// lastMailToken returns the token in the latest mail sent to email with subject
+func (m *testMailer) lastMailToken(email, subject string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		msg := m.messages[i]
+		if msg.To != email || msg.Subject != subject {
+			continue
+		}
+		match := mailTokenPattern.FindStringSubmatch(msg.Body)
+		if match == nil {
+			return "", fmt.Errorf("no token in mail to %s", email)
+		}
+		return url.QueryUnescape(match[1])
+	}
+	return "", fmt.Errorf("no %q mail sent to %s", subject, email)
+}
+
 // setupTestServer initializes the test HTTP server with real database connection
 // Uses sync.Once to ensure setup only happens once across all test files
 // t can be nil when called from TestMain
@@ -108,9 +147,19 @@ func setupTestServerOnce(t *testing.T) {
 		}
 	}
 
+	// Signed-out sessions and restricted accounts are turned away, as in cmd/main.go
+	revocations := sessions.NewMemoryRevocations(httplib.AccessTokenTTL)
+	httplib.EnableSessionRevocationCheck(revocations)
+	httplib.EnableAccountStateCheck(testDBPool)
+
 	// Initialize user components
 	userRepo := users.NewRepository(testDBPool)
-	userService := users.NewService(userRepo, publisher)
+	userService := users.NewService(userRepo, publisher, users.EmailConfig{
+		VerifyTTL: time.Hour,
+		ResetTTL:  time.Hour,
+		ResetURL:  "http://localhost:3000/reset-password",
+		Mailer:    testMail,
+	}, revocations)
 	userEndpoints := users.NewEndpoints(userService)
 
 	// Initialize listing components
@@ -122,7 +171,7 @@ func setupTestServerOnce(t *testing.T) {
 	if listingSharedSecret == "" {
 		listingSharedSecret = "test-secret" // Default for testing
 	}
-	listingService := listings.NewListingService(listingBaseURL, listingSharedSecret)
+	listingService := listings.NewListingService(listingBaseURL, listingSharedSecret, nil, nil, nil)
 	listingEndpoints := listings.NewEndpoints(listingService)
 
 	// Setup HTTP server
@@ -205,6 +254,11 @@ func createTestUser(t *testing.T, email, username, password string) (string, err
 		return "", fmt.Errorf("user_id not found in response")
 	}
 
+	// Test users skip the emailed link so they can reach routes that need a verified email
+	if _, err := testDBPool.Exec(context.Background(), `UPDATE users SET email_verified_at = now() WHERE user_id = $1`, userID); err != nil {
+		return "", fmt.Errorf("failed to verify test user email: %w", err)
+	}
+
 	// Track created user and store credentials for cleanup
 	mu.Lock()
 	createdUsers = append(createdUsers, userID)
@@ -257,6 +311,15 @@ func loginTestUser(t *testing.T, email, password string) (accessToken, refreshTo
 	return accessToken, refreshToken, nil
 }
 
+// postJSON posts reqBody as JSON to path on the test server
+func postJSON(t *testing.T, path string, reqBody interface{}) (*http.Response, error) {
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	return http.Post(testServer.URL+path, "application/json", bytes.NewBuffer(body))
+}
+
 // makeAuthenticatedRequest makes an HTTP request with authentication token
 func makeAuthenticatedRequest(t *testing.T, method, url string, body []byte, accessToken string) (*http.Response, error) {
 	req, err := http.NewRequest(method, url, bytes.NewBuffer(body))
@@ -310,9 +373,9 @@ func cleanupTestData(t *testing.T) {
 		}
 	}
 
-	// Delete user_login_auth records
+	// Delete user_sessions records
 	for _, userID := range usersToDelete {
-		_, _ = testDBPool.Exec(ctx, "DELETE FROM user_login_auth WHERE user_id = $1", userID)
+		_, _ = testDBPool.Exec(ctx, "DELETE FROM user_sessions WHERE user_id = $1", userID)
 	}
 
 	// Delete user_auth records
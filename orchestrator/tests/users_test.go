@@ -670,6 +670,305 @@ func TestEventsVerifyHandler(t *testing.T) {
 	})
 }
 
+func TestPasswordResetHandler(t *testing.T) {
+	const resetSubject = "Reset your password"
+
+	// requestReset asks for a reset link for email and returns the emailed token
+	requestReset := func(t *testing.T, email string) string {
+		resp, err := postJSON(t, "/api/users/password/forgot", map[string]string{"email": email})
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			var errResp map[string]interface{}
+			json.NewDecoder(resp.Body).Decode(&errResp)
+			t.Fatalf("Expected status 200, got %d: %v", resp.StatusCode, errResp)
+		}
+
+		token, err := testMail.lastMailToken(email, resetSubject)
+		if err != nil {
+			t.Fatalf("Failed to read reset email: %v", err)
+		}
+		return token
+	}
+
+	resetPassword := func(t *testing.T, token, newPassword string) int {
+		resp, err := postJSON(t, "/api/users/password/reset", map[string]string{
+			"token":        token,
+			"new_password": newPassword,
+		})
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		email := generateTestEmail()
+		password := "testpass123"
+		newPassword := "newpass456"
+
+		if _, err := createTestUser(t, email, generateTestUsername(), password); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+		_, refreshToken, err := loginTestUser(t, email, password)
+		if err != nil {
+			t.Fatalf("Failed to login: %v", err)
+		}
+
+		token := requestReset(t, email)
+		if status := resetPassword(t, token, newPassword); status != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", status)
+		}
+
+		if _, _, err := loginTestUser(t, email, password); err == nil {
+			t.Error("Expected login with the old password to fail")
+		}
+		if _, _, err := loginTestUser(t, email, newPassword); err != nil {
+			t.Errorf("Failed to login with the new password: %v", err)
+		}
+
+		// The reset signs the user out everywhere
+		resp, err := postJSON(t, "/api/users/refresh", map[string]string{"refresh_token": refreshToken})
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected refresh of a pre-reset session to return 401, got %d", resp.StatusCode)
+		}
+
+		// Links work once
+		if status := resetPassword(t, token, "another789"); status != http.StatusBadRequest {
+			t.Errorf("Expected reusing the reset token to return 400, got %d", status)
+		}
+	})
+
+	t.Run("NewerLinkReplacesOlder", func(t *testing.T) {
+		email := generateTestEmail()
+		if _, err := createTestUser(t, email, generateTestUsername(), "testpass123"); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+
+		first := requestReset(t, email)
+		second := requestReset(t, email)
+		if first == second {
+			t.Fatal("Expected a new token for each reset request")
+		}
+
+		if status := resetPassword(t, first, "newpass456"); status != http.StatusBadRequest {
+			t.Errorf("Expected the older token to return 400, got %d", status)
+		}
+		if status := resetPassword(t, second, "newpass456"); status != http.StatusOK {
+			t.Errorf("Expected the newest token to return 200, got %d", status)
+		}
+	})
+
+	t.Run("UnknownEmail", func(t *testing.T) {
+		email := generateTestEmail()
+		resp, err := postJSON(t, "/api/users/password/forgot", map[string]string{"email": email})
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		// Same answer as for a real account, and nothing is sent
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		if _, err := testMail.lastMailToken(email, resetSubject); err == nil {
+			t.Error("Expected no reset email for an unknown address")
+		}
+	})
+
+	t.Run("InvalidInput", func(t *testing.T) {
+		tests := []struct {
+			name        string
+			token       string
+			newPassword string
+			status      int
+		}{
+			{"MissingToken", "", "newpass456", http.StatusBadRequest},
+			{"ShortPassword", "some-token", "abc", http.StatusBadRequest},
+			{"UnknownToken", "not-a-real-token", "newpass456", http.StatusBadRequest},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if status := resetPassword(t, tt.token, tt.newPassword); status != tt.status {
+					t.Errorf("Expected status %d, got %d", tt.status, status)
+				}
+			})
+		}
+	})
+}
+
+func TestSessionRotation(t *testing.T) {
+	// refresh exchanges a refresh token and returns the status with the new token pair
+	refresh := func(t *testing.T, refreshToken string) (status int, accessToken, newRefreshToken string) {
+		resp, err := postJSON(t, "/api/users/refresh", map[string]string{"refresh_token": refreshToken})
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var refreshResp map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&refreshResp)
+		accessToken, _ = refreshResp["access_token"].(string)
+		newRefreshToken, _ = refreshResp["refresh_token"].(string)
+		return resp.StatusCode, accessToken, newRefreshToken
+	}
+
+	profileStatus := func(t *testing.T, accessToken string) int {
+		resp, err := makeAuthenticatedRequest(t, "GET", testServer.URL+"/api/users/profile", nil, accessToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	newSession := func(t *testing.T) (email, password, accessToken, refreshToken string) {
+		email, password = generateTestEmail(), "testpass123"
+		if _, err := createTestUser(t, email, generateTestUsername(), password); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+		accessToken, refreshToken, err := loginTestUser(t, email, password)
+		if err != nil {
+			t.Fatalf("Failed to login: %v", err)
+		}
+		return email, password, accessToken, refreshToken
+	}
+
+	t.Run("RefreshRotatesToken", func(t *testing.T) {
+		_, _, _, first := newSession(t)
+
+		status, accessToken, second := refresh(t, first)
+		if status != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", status)
+		}
+		if second == "" || second == first {
+			t.Fatal("Expected a new refresh token")
+		}
+		if profileStatus(t, accessToken) != http.StatusOK {
+			t.Error("Expected the refreshed access token to work")
+		}
+
+		if status, _, _ := refresh(t, second); status != http.StatusOK {
+			t.Errorf("Expected the rotated token to refresh, got %d", status)
+		}
+	})
+
+	t.Run("ReuseRevokesSession", func(t *testing.T) {
+		_, _, _, first := newSession(t)
+
+		status, accessToken, second := refresh(t, first)
+		if status != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", status)
+		}
+
+		// Presenting the rotated-out token again means it was copied
+		if status, _, _ := refresh(t, first); status != http.StatusUnauthorized {
+			t.Fatalf("Expected reuse to return 401, got %d", status)
+		}
+		if status, _, _ := refresh(t, second); status != http.StatusUnauthorized {
+			t.Errorf("Expected the latest token of a revoked session to return 401, got %d", status)
+		}
+		if status := profileStatus(t, accessToken); status != http.StatusUnauthorized {
+			t.Errorf("Expected the access token of a revoked session to return 401, got %d", status)
+		}
+	})
+
+	t.Run("LogoutEndsOnlyThisSession", func(t *testing.T) {
+		email, password, accessToken, refreshToken := newSession(t)
+		otherAccess, otherRefresh, err := loginTestUser(t, email, password)
+		if err != nil {
+			t.Fatalf("Failed to login: %v", err)
+		}
+
+		resp, err := makeAuthenticatedRequest(t, "POST", testServer.URL+"/api/users/logout", nil, accessToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+
+		if status, _, _ := refresh(t, refreshToken); status != http.StatusUnauthorized {
+			t.Errorf("Expected refresh after logout to return 401, got %d", status)
+		}
+		if status := profileStatus(t, accessToken); status != http.StatusUnauthorized {
+			t.Errorf("Expected the logged out access token to return 401, got %d", status)
+		}
+		if status := profileStatus(t, otherAccess); status != http.StatusOK {
+			t.Errorf("Expected the other session to stay signed in, got %d", status)
+		}
+		if status, _, _ := refresh(t, otherRefresh); status != http.StatusOK {
+			t.Errorf("Expected the other session to refresh, got %d", status)
+		}
+	})
+
+	t.Run("RevokeOtherSessions", func(t *testing.T) {
+		email, password, accessToken, refreshToken := newSession(t)
+		_, otherRefresh, err := loginTestUser(t, email, password)
+		if err != nil {
+			t.Fatalf("Failed to login: %v", err)
+		}
+
+		resp, err := makeAuthenticatedRequest(t, "GET", testServer.URL+"/api/users/sessions", nil, accessToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		var sessionsResp struct {
+			Sessions []struct {
+				SessionID string `json:"session_id"`
+				Current   bool   `json:"current"`
+			} `json:"sessions"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&sessionsResp)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if len(sessionsResp.Sessions) != 2 {
+			t.Fatalf("Expected 2 sessions, got %d", len(sessionsResp.Sessions))
+		}
+		current := 0
+		for _, s := range sessionsResp.Sessions {
+			if s.Current {
+				current++
+			}
+		}
+		if current != 1 {
+			t.Errorf("Expected exactly one current session, got %d", current)
+		}
+
+		resp, err = makeAuthenticatedRequest(t, "DELETE", testServer.URL+"/api/users/sessions", nil, accessToken)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		var revokeResp map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&revokeResp)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %v", resp.StatusCode, revokeResp)
+		}
+		if revokeResp["revoked"] != float64(1) {
+			t.Errorf("Expected 1 revoked session, got %v", revokeResp["revoked"])
+		}
+
+		if status, _, _ := refresh(t, otherRefresh); status != http.StatusUnauthorized {
+			t.Errorf("Expected the other session to be signed out, got %d", status)
+		}
+		if status, _, _ := refresh(t, refreshToken); status != http.StatusOK {
+			t.Errorf("Expected the current session to stay signed in, got %d", status)
+		}
+	})
+}
+
 func TestHealthEndpoint(t *testing.T) {
 	resp, err := http.Get(testServer.URL + "/health")
 	if err != nil {
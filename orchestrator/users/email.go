@@ -0,0 +1,86 @@
+package users
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kunal768/cmpe202/orchestrator/internal/mail"
+)
+
+// EmailConfig controls the account emails: campus verification and password resets
+type EmailConfig struct {
+	// CampusDomains are the email domains allowed to sign up; their subdomains are
+	// allowed too. Empty allows any domain.
+	CampusDomains []string
+	// VerifyTTL is how long a verification link stays valid
+	VerifyTTL time.Duration
+	// VerifyURL is the page the verification link opens; the token is added as ?token=
+	VerifyURL string
+	// ResetTTL is how long a password reset link stays valid
+	ResetTTL time.Duration
+	// ResetURL is the page the password reset link opens; the token is added as ?token=
+	ResetURL string
+	Mailer   mail.Sender
+}
+
+// EmailConfigFromEnv reads CAMPUS_EMAIL_DOMAINS (comma-separated),
+// EMAIL_VERIFICATION_TTL (default 24h), EMAIL_VERIFICATION_URL, PASSWORD_RESET_TTL
+// (default 1h) and PASSWORD_RESET_URL, and sends mail with mail.SenderFromEnv
+func EmailConfigFromEnv() EmailConfig {
+	cfg := EmailConfig{
+		VerifyTTL: envDuration("EMAIL_VERIFICATION_TTL", 24*time.Hour),
+		VerifyURL: os.Getenv("EMAIL_VERIFICATION_URL"),
+		ResetTTL:  envDuration("PASSWORD_RESET_TTL", time.Hour),
+		ResetURL:  os.Getenv("PASSWORD_RESET_URL"),
+		Mailer:    mail.SenderFromEnv(),
+	}
+	for _, d := range strings.Split(os.Getenv("CAMPUS_EMAIL_DOMAINS"), ",") {
+		if d = strings.ToLower(strings.TrimSpace(d)); d != "" {
+			cfg.CampusDomains = append(cfg.CampusDomains, strings.TrimPrefix(d, "@"))
+		}
+	}
+	if cfg.VerifyURL == "" {
+		cfg.VerifyURL = "http://localhost:3000/verify-email"
+	}
+	if cfg.ResetURL == "" {
+		cfg.ResetURL = "http://localhost:3000/reset-password"
+	}
+	return cfg
+}
+
+func envDuration(key string, def time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(key)); err == nil && d > 0 {
+		return d
+	}
+	return def
+}
+
+// checkDomain rejects addresses outside the campus domains
+func (c EmailConfig) checkDomain(email string) error {
+	if len(c.CampusDomains) == 0 {
+		return nil
+	}
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ErrEmailDomainNotAllowed
+	}
+	domain := strings.ToLower(email[at+1:])
+	for _, d := range c.CampusDomains {
+		if domain == d || strings.HasSuffix(domain, "."+d) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: sign up with your %s address", ErrEmailDomainNotAllowed, strings.Join(c.CampusDomains, " or "))
+}
+
+// withToken adds token to a link as the token query parameter
+func withToken(link, token string) string {
+	sep := "?"
+	if strings.Contains(link, "?") {
+		sep = "&"
+	}
+	return link + sep + "token=" + url.QueryEscape(token)
+}
@@ -2,15 +2,20 @@ package users
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net"
 	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	httplib "github.com/kunal768/cmpe202/http-lib"
+	"github.com/kunal768/cmpe202/orchestrator/models"
 )
 
 type Endpoints struct {
@@ -46,7 +51,14 @@ func (e *Endpoints) SignupHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Call service
-	response, err := e.service.Signup(r.Context(), req)
+	response, err := e.service.Signup(r.Context(), req, clientInfo(r))
+	if errors.Is(err, ErrEmailDomainNotAllowed) {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Message: err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		httplib.WriteJSON(w, http.StatusConflict, ErrorResponse{
 			Error:   "Signup failed",
@@ -81,8 +93,11 @@ func (e *Endpoints) LoginHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Call service
-	response, err := e.service.Login(r.Context(), req)
+	response, err := e.service.Login(r.Context(), req, clientInfo(r))
 	if err != nil {
+		if writeAccountRestricted(w, err) {
+			return
+		}
 		httplib.WriteJSON(w, http.StatusUnauthorized, ErrorResponse{
 			Error:   "Login failed",
 			Message: err.Error(),
@@ -109,7 +124,7 @@ func (e *Endpoints) GetUserHandler(w http.ResponseWriter, r *http.Request) {
 	userRole, _ := r.Context().Value(httplib.ContextKey("userRole")).(string)
 
 	// Call service
-	user, err := e.service.GetUserByID(r.Context(), userID)
+	user, err := e.service.GetUserProfile(r.Context(), userID)
 	if err != nil {
 		httplib.WriteJSON(w, http.StatusNotFound, ErrorResponse{
 			Error:   "User not found",
@@ -150,8 +165,11 @@ func (e *Endpoints) RefreshTokenHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Call service
-	response, err := e.service.RefreshToken(r.Context(), req)
+	response, err := e.service.RefreshToken(r.Context(), req, clientInfo(r))
 	if err != nil {
+		if writeAccountRestricted(w, err) {
+			return
+		}
 		httplib.WriteJSON(w, http.StatusUnauthorized, ErrorResponse{
 			Error:   "Token refresh failed",
 			Message: err.Error(),
@@ -162,6 +180,302 @@ func (e *Endpoints) RefreshTokenHandler(w http.ResponseWriter, r *http.Request)
 	httplib.WriteJSON(w, http.StatusOK, response)
 }
 
+// VerifyEmailHandler confirms a user's email address from the token in their verification link
+func (e *Endpoints) VerifyEmailHandler(w http.ResponseWriter, r *http.Request) {
+	var req VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: "Failed to decode request body",
+		})
+		return
+	}
+	if req.Token == "" {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Message: "Token is required",
+		})
+		return
+	}
+
+	user, err := e.service.VerifyEmail(r.Context(), req.Token)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrInvalidVerificationToken) {
+			status = http.StatusBadRequest
+		}
+		httplib.WriteJSON(w, status, ErrorResponse{
+			Error:   "Verification failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, VerifyEmailResponse{
+		Message: "Email verified successfully",
+		User:    *user,
+	})
+}
+
+// ResendVerificationEmailHandler sends the current user a new verification link
+func (e *Endpoints) ResendVerificationEmailHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(httplib.ContextKey("userId")).(string)
+	if !ok {
+		httplib.WriteJSON(w, http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User ID not found in context",
+		})
+		return
+	}
+
+	if err := e.service.ResendVerificationEmail(r.Context(), userID); err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, ErrUserNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, ErrEmailAlreadyVerified):
+			status = http.StatusConflict
+		}
+		httplib.WriteJSON(w, status, ErrorResponse{
+			Error:   "Could not send verification email",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, map[string]string{
+		"message": "Verification email sent",
+	})
+}
+
+// ForgotPasswordHandler emails a password reset link. It answers the same way whether
+// or not the email has an account.
+func (e *Endpoints) ForgotPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var req ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: "Failed to decode request body",
+		})
+		return
+	}
+	if req.Email == "" || !isValidEmail(req.Email) {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Message: "A valid email is required",
+		})
+		return
+	}
+
+	if err := e.service.ForgotPassword(r.Context(), req.Email); err != nil {
+		httplib.WriteJSON(w, http.StatusInternalServerError, ErrorResponse{
+			Error:   "Password reset failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, map[string]string{
+		"message": "If an account exists for that email, a password reset link has been sent",
+	})
+}
+
+// ResetPasswordHandler sets a new password from a reset link token
+func (e *Endpoints) ResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var req ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: "Failed to decode request body",
+		})
+		return
+	}
+	err := validatePassword(req.NewPassword)
+	if req.Token == "" {
+		err = fmt.Errorf("token is required")
+	}
+	if err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := e.service.ResetPassword(r.Context(), req); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrInvalidResetToken) {
+			status = http.StatusBadRequest
+		}
+		httplib.WriteJSON(w, status, ErrorResponse{
+			Error:   "Password reset failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, map[string]string{
+		"message": "Password reset successfully. Log in with your new password.",
+	})
+}
+
+// ChangePasswordHandler changes the current user's password (requires the old one)
+func (e *Endpoints) ChangePasswordHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(httplib.ContextKey("userId")).(string)
+	if !ok {
+		httplib.WriteJSON(w, http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User ID not found in context",
+		})
+		return
+	}
+
+	var req ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: "Failed to decode request body",
+		})
+		return
+	}
+	err := validatePassword(req.NewPassword)
+	if req.OldPassword == "" {
+		err = fmt.Errorf("old password is required")
+	}
+	if err != nil {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	response, err := e.service.ChangePassword(r.Context(), userID, req, clientInfo(r))
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, ErrIncorrectPassword):
+			status = http.StatusUnauthorized
+		case errors.Is(err, ErrSamePassword):
+			status = http.StatusBadRequest
+		case errors.Is(err, ErrUserNotFound):
+			status = http.StatusNotFound
+		}
+		httplib.WriteJSON(w, status, ErrorResponse{
+			Error:   "Password change failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, response)
+}
+
+// ListSessionsHandler lists the devices the current user is signed in on
+func (e *Endpoints) ListSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(httplib.ContextKey("userId")).(string)
+	if !ok {
+		httplib.WriteJSON(w, http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User ID not found in context",
+		})
+		return
+	}
+	sessionID, _ := r.Context().Value(httplib.ContextKey("sessionId")).(string)
+
+	sessions, err := e.service.ListSessions(r.Context(), userID, sessionID)
+	if err != nil {
+		httplib.WriteJSON(w, http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to list sessions",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, SessionsResponse{Sessions: sessions})
+}
+
+// RevokeSessionHandler signs the current user out of one of their sessions
+func (e *Endpoints) RevokeSessionHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(httplib.ContextKey("userId")).(string)
+	if !ok {
+		httplib.WriteJSON(w, http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User ID not found in context",
+		})
+		return
+	}
+
+	if err := e.service.RevokeSession(r.Context(), userID, r.PathValue("session_id"), models.SessionUserRevoked); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrSessionNotFound) {
+			status = http.StatusNotFound
+		}
+		httplib.WriteJSON(w, status, ErrorResponse{
+			Error:   "Failed to revoke session",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, map[string]string{
+		"message": "Session signed out",
+	})
+}
+
+// RevokeOtherSessionsHandler signs the current user out everywhere except this session
+func (e *Endpoints) RevokeOtherSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(httplib.ContextKey("userId")).(string)
+	if !ok {
+		httplib.WriteJSON(w, http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User ID not found in context",
+		})
+		return
+	}
+	sessionID, _ := r.Context().Value(httplib.ContextKey("sessionId")).(string)
+
+	revoked, err := e.service.RevokeOtherSessions(r.Context(), userID, sessionID)
+	if err != nil {
+		httplib.WriteJSON(w, http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to revoke sessions",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, RevokeSessionsResponse{
+		Message: "Signed out of other sessions",
+		Revoked: revoked,
+	})
+}
+
+// LogoutHandler ends the session the request was made from
+func (e *Endpoints) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(httplib.ContextKey("userId")).(string)
+	if !ok {
+		httplib.WriteJSON(w, http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User ID not found in context",
+		})
+		return
+	}
+	sessionID, _ := r.Context().Value(httplib.ContextKey("sessionId")).(string)
+
+	err := e.service.RevokeSession(r.Context(), userID, sessionID, models.SessionLogout)
+	if err != nil && !errors.Is(err, ErrSessionNotFound) {
+		httplib.WriteJSON(w, http.StatusInternalServerError, ErrorResponse{
+			Error:   "Logout failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, map[string]string{
+		"message": "Logged out",
+	})
+}
+
 // EventsVerifyHandler handles WebSocket events verification
 func (e *Endpoints) EventsVerifyHandler(w http.ResponseWriter, r *http.Request) {
 	var req EventsVerifyRequest
@@ -313,18 +627,36 @@ func (e *Endpoints) RegisterRoutes(mux *http.ServeMux, dbPool *pgxpool.Pool) {
 	mux.Handle("POST /api/users/signup", httplib.JSONRequestDecoder(http.HandlerFunc(e.SignupHandler)))
 	mux.Handle("POST /api/users/login", httplib.JSONRequestDecoder(http.HandlerFunc(e.LoginHandler)))
 	mux.Handle("POST /api/users/refresh", httplib.JSONRequestDecoder(http.HandlerFunc(e.RefreshTokenHandler)))
+	mux.Handle("POST /api/users/verify-email", httplib.JSONRequestDecoder(http.HandlerFunc(e.VerifyEmailHandler)))
+	mux.Handle("POST /api/users/password/forgot", httplib.JSONRequestDecoder(http.HandlerFunc(e.ForgotPasswordHandler)))
+	mux.Handle("POST /api/users/password/reset", httplib.JSONRequestDecoder(http.HandlerFunc(e.ResetPasswordHandler)))
 
 	// All other routes require auth + role injection by default
 	mux.Handle("GET /api/users/profile", protected(http.HandlerFunc(e.GetUserHandler)))
 	mux.Handle("PUT /api/users/profile", protected(http.HandlerFunc(e.UpdateUserHandler)))
 	mux.Handle("GET /api/users/search", protected(http.HandlerFunc(e.SearchUsersHandler)))
+	mux.Handle("POST /api/users/verify-email/resend", protected(http.HandlerFunc(e.ResendVerificationEmailHandler)))
+	mux.Handle("POST /api/users/password/change", protected(http.HandlerFunc(e.ChangePasswordHandler)))
+	mux.Handle("POST /api/users/logout", protected(http.HandlerFunc(e.LogoutHandler)))
+	mux.Handle("GET /api/users/sessions", protected(http.HandlerFunc(e.ListSessionsHandler)))
+	mux.Handle("DELETE /api/users/sessions", protected(http.HandlerFunc(e.RevokeOtherSessionsHandler)))
+	mux.Handle("DELETE /api/users/sessions/{session_id}", protected(http.HandlerFunc(e.RevokeSessionHandler)))
 
 	// Admin-only routes: get user by ID and delete user
 	mux.Handle("GET /api/users/{id}", protected(http.HandlerFunc(e.GetUserByIDHandler)))
 	mux.Handle("DELETE /api/users/{id}", protected(http.HandlerFunc(e.DeleteUserHandler)))
 
-	// Events verification endpoint (requires auth but not role injection)
-	mux.Handle("POST /api/events/verify", httplib.AuthMiddleWare(httplib.JSONRequestDecoder(http.HandlerFunc(e.EventsVerifyHandler))))
+	// Admin-only account status routes
+	mux.Handle("POST /api/users/{id}/suspend", protected(http.HandlerFunc(e.SuspendUserHandler)))
+	mux.Handle("POST /api/users/{id}/unsuspend", protected(http.HandlerFunc(e.UnsuspendUserHandler)))
+	mux.Handle("POST /api/users/{id}/ban", protected(http.HandlerFunc(e.BanUserHandler)))
+	mux.Handle("GET /api/users/{id}/status-history", protected(http.HandlerFunc(e.GetAccountStatusHistoryHandler)))
+
+	// Events verification endpoint (requires auth but not role injection). The WebSocket
+	// carries chat, so users have to verify their email before connecting.
+	mux.Handle("POST /api/events/verify", httplib.AuthMiddleWare(
+		httplib.VerifiedEmailMiddleWare(dbPool)(httplib.JSONRequestDecoder(http.HandlerFunc(e.EventsVerifyHandler))),
+	))
 }
 
 func (e *Endpoints) UpdateUserHandler(w http.ResponseWriter, r *http.Request) {
@@ -350,6 +682,13 @@ func (e *Endpoints) UpdateUserHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Call service
 	response, err := e.service.UpdateUser(r.Context(), req)
+	if errors.Is(err, ErrEmailDomainNotAllowed) {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Message: err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		httplib.WriteJSON(w, http.StatusInternalServerError, ErrorResponse{
 			Error:   "Update failed",
@@ -402,10 +741,15 @@ func validateSignupRequest(req SignupRequest) error {
 	if !isValidEmail(req.Email) {
 		return fmt.Errorf("invalid email format")
 	}
-	if req.Password == "" {
+	return validatePassword(req.Password)
+}
+
+// validatePassword checks a new password against the signup rules
+func validatePassword(password string) error {
+	if password == "" {
 		return fmt.Errorf("password is required")
 	}
-	if len(req.Password) < 6 {
+	if len(password) < 6 {
 		return fmt.Errorf("password must be at least 6 characters")
 	}
 	return nil
@@ -466,7 +810,7 @@ func (e *Endpoints) GetUserByIDHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Call service
-	user, err := e.service.GetUserByID(r.Context(), userID)
+	user, err := e.service.GetUserProfile(r.Context(), userID)
 	if err != nil {
 		httplib.WriteJSON(w, http.StatusNotFound, ErrorResponse{
 			Error:   "User not found",
@@ -526,3 +870,209 @@ func (e *Endpoints) DeleteUserHandler(w http.ResponseWriter, r *http.Request) {
 
 	httplib.WriteJSON(w, http.StatusOK, response)
 }
+
+// writeAccountRestricted answers with 403 when err is an AccountRestrictedError
+func writeAccountRestricted(w http.ResponseWriter, err error) bool {
+	var restricted *AccountRestrictedError
+	if !errors.As(err, &restricted) {
+		return false
+	}
+	title := "Account banned"
+	if restricted.Status == models.AccountSuspended {
+		title = "Account suspended"
+	}
+	httplib.WriteJSON(w, http.StatusForbidden, ErrorResponse{
+		Error:   title,
+		Message: restricted.Message,
+	})
+	return true
+}
+
+// clientInfo describes the device behind a request. Behind a proxy the client is the
+// first X-Forwarded-For address.
+func clientInfo(r *http.Request) ClientInfo {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+		ip = strings.TrimSpace(strings.Split(fwd, ",")[0])
+	}
+	return ClientInfo{UserAgent: r.UserAgent(), IPAddress: ip}
+}
+
+// accountStatusTarget checks the caller is an admin acting on someone else and returns
+// the admin's and the target user's IDs
+func accountStatusTarget(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	isAdmin, _ := checkAdminRole(r)
+	if !isAdmin {
+		httplib.WriteJSON(w, http.StatusForbidden, ErrorResponse{
+			Error:   "Forbidden",
+			Message: "Admin access required",
+		})
+		return "", "", false
+	}
+
+	userID := r.PathValue("id")
+	if userID == "" {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: "User ID is required",
+		})
+		return "", "", false
+	}
+
+	adminID, _ := r.Context().Value(httplib.ContextKey("userId")).(string)
+	if adminID == userID {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: "Cannot change your own account status",
+		})
+		return "", "", false
+	}
+	return adminID, userID, true
+}
+
+// decodeAccountStatusRequest reads the request body, requiring a reason when required is set
+func decodeAccountStatusRequest(w http.ResponseWriter, r *http.Request, required bool) (AccountStatusRequest, bool) {
+	var req AccountStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && (required || !errors.Is(err, io.EOF)) {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: "Failed to decode request body",
+		})
+		return req, false
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if required && req.Reason == "" {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Message: "reason is required",
+		})
+		return req, false
+	}
+	return req, true
+}
+
+// writeAccountStatusResult maps the outcome of an account status change to a response
+func writeAccountStatusResult(w http.ResponseWriter, user *models.User, err error, message string) {
+	switch {
+	case errors.Is(err, ErrUserNotFound):
+		httplib.WriteJSON(w, http.StatusNotFound, ErrorResponse{
+			Error:   "User not found",
+			Message: err.Error(),
+		})
+	case errors.Is(err, ErrTargetIsAdmin):
+		httplib.WriteJSON(w, http.StatusForbidden, ErrorResponse{
+			Error:   "Forbidden",
+			Message: err.Error(),
+		})
+	case errors.Is(err, ErrAccountNotRestricted):
+		httplib.WriteJSON(w, http.StatusConflict, ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+	case err != nil:
+		httplib.WriteJSON(w, http.StatusInternalServerError, ErrorResponse{
+			Error:   "Account status update failed",
+			Message: err.Error(),
+		})
+	default:
+		httplib.WriteJSON(w, http.StatusOK, AccountStatusResponse{
+			Message: message,
+			User:    *user,
+		})
+	}
+}
+
+// SuspendUserHandler suspends a user until a given time or indefinitely (admin only)
+func (e *Endpoints) SuspendUserHandler(w http.ResponseWriter, r *http.Request) {
+	adminID, userID, ok := accountStatusTarget(w, r)
+	if !ok {
+		return
+	}
+	req, ok := decodeAccountStatusRequest(w, r, true)
+	if !ok {
+		return
+	}
+
+	until := req.Until
+	if until == nil && req.DurationHours != nil {
+		if *req.DurationHours <= 0 {
+			httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error:   "Validation error",
+				Message: "duration_hours must be positive",
+			})
+			return
+		}
+		t := time.Now().Add(time.Duration(*req.DurationHours) * time.Hour)
+		until = &t
+	}
+	if until != nil && !until.After(time.Now()) {
+		httplib.WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Message: "until must be in the future",
+		})
+		return
+	}
+
+	user, err := e.service.SuspendUser(r.Context(), adminID, userID, until, req.Reason)
+	writeAccountStatusResult(w, user, err, "User suspended successfully")
+}
+
+// UnsuspendUserHandler lifts a suspension or ban (admin only)
+func (e *Endpoints) UnsuspendUserHandler(w http.ResponseWriter, r *http.Request) {
+	adminID, userID, ok := accountStatusTarget(w, r)
+	if !ok {
+		return
+	}
+	req, ok := decodeAccountStatusRequest(w, r, false)
+	if !ok {
+		return
+	}
+
+	user, err := e.service.UnsuspendUser(r.Context(), adminID, userID, req.Reason)
+	writeAccountStatusResult(w, user, err, "User reinstated successfully")
+}
+
+// BanUserHandler bans a user permanently (admin only)
+func (e *Endpoints) BanUserHandler(w http.ResponseWriter, r *http.Request) {
+	adminID, userID, ok := accountStatusTarget(w, r)
+	if !ok {
+		return
+	}
+	req, ok := decodeAccountStatusRequest(w, r, true)
+	if !ok {
+		return
+	}
+
+	user, err := e.service.BanUser(r.Context(), adminID, userID, req.Reason)
+	writeAccountStatusResult(w, user, err, "User banned successfully")
+}
+
+// GetAccountStatusHistoryHandler returns the audit trail of admin actions on a user (admin only)
+func (e *Endpoints) GetAccountStatusHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	isAdmin, _ := checkAdminRole(r)
+	if !isAdmin {
+		httplib.WriteJSON(w, http.StatusForbidden, ErrorResponse{
+			Error:   "Forbidden",
+			Message: "Admin access required",
+		})
+		return
+	}
+
+	userID := r.PathValue("id")
+	events, err := e.service.GetAccountStatusHistory(r.Context(), userID)
+	if err != nil {
+		httplib.WriteJSON(w, http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to fetch account status history",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	httplib.WriteJSON(w, http.StatusOK, AccountStatusHistoryResponse{
+		UserID: userID,
+		Events: events,
+	})
+}
@@ -0,0 +1,119 @@
+package users
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kunal768/cmpe202/orchestrator/internal/mail"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvalidResetToken = errors.New("reset link is invalid, expired or already used")
+	ErrIncorrectPassword = errors.New("current password is incorrect")
+	ErrSamePassword      = errors.New("new password must be different from the current password")
+)
+
+// hashResetToken is what password_reset_tokens stores in place of the emailed token
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ForgotPassword emails a single-use password reset link to the account with email.
+// It reports success for unknown addresses too, so callers can't probe which emails
+// have accounts.
+func (s *svc) ForgotPassword(ctx context.Context, email string) error {
+	user, err := s.repo.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to look up user: %w", err)
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return fmt.Errorf("failed to generate reset token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	if err := s.repo.CreatePasswordResetToken(ctx, user.UserId, hashResetToken(token), time.Now().Add(s.email.ResetTTL)); err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Hi %s,\n\nSomeone asked to reset the password for your account. Choose a new password here:\n\n%s\n\nThe link expires in %s and works once. If you didn't ask for this, you can ignore this email; your password hasn't changed.\n",
+		user.UserName, withToken(s.email.ResetURL, token), s.email.ResetTTL)
+	// Failing here would tell the caller the account exists, so it is only logged
+	if err := s.email.Mailer.Send(ctx, mail.Message{To: user.Email, Subject: "Reset your password", Body: body}); err != nil {
+		log.Printf("Failed to send password reset email to user %s: %v", user.UserId, err)
+	}
+	return nil
+}
+
+// ResetPassword sets a new password using the token from a reset email. The token is
+// used up, and the user is signed out everywhere.
+func (s *svc) ResetPassword(ctx context.Context, req ResetPasswordRequest) error {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	userID, revoked, err := s.repo.ResetPassword(ctx, hashResetToken(req.Token), string(hashed))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrInvalidResetToken
+	}
+	if err != nil {
+		return fmt.Errorf("failed to reset password: %w", err)
+	}
+	s.cacheRevoked(ctx, revoked)
+	log.Printf("Password reset for user %s", userID)
+	return nil
+}
+
+// ChangePassword replaces the password of a signed-in user who knows the current one.
+// Every existing session is revoked and a new one is returned for the caller.
+func (s *svc) ChangePassword(ctx context.Context, userID string, req ChangePasswordRequest, client ClientInfo) (*ChangePasswordResponse, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	userAuth, err := s.repo.GetUserAuthByUserID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(userAuth.Password), []byte(req.OldPassword)); err != nil {
+		return nil, ErrIncorrectPassword
+	}
+	if req.OldPassword == req.NewPassword {
+		return nil, ErrSamePassword
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+	revoked, err := s.repo.ChangePassword(ctx, userID, string(hashed))
+	if err != nil {
+		return nil, fmt.Errorf("failed to change password: %w", err)
+	}
+	s.cacheRevoked(ctx, revoked)
+
+	accessToken, refreshToken, err := s.startSession(ctx, user, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ChangePasswordResponse{
+		Message:      "Password changed successfully",
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
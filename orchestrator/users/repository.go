@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/kunal768/cmpe202/orchestrator/models"
 )
@@ -18,22 +20,34 @@ type Repository interface {
 	CreateUser(ctx context.Context, user *models.User) error
 	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
 	GetUserByID(ctx context.Context, userID string) (*models.User, error)
+	GetSellerRating(ctx context.Context, userID string) (*models.RatingSummary, error)
 	UpdateUser(ctx context.Context, user *models.User) (*models.User, error)
 	DeleteUser(ctx context.Context, userID string) error
 	SearchUsers(ctx context.Context, query string, excludeUserID string, limit int, offset int) ([]models.User, error)
+	MarkEmailVerified(ctx context.Context, userID string, email string) (time.Time, error)
+
+	// Account status operations
+	SetAccountStatus(ctx context.Context, change AccountStatusChange) ([]string, error)
+	ListAccountStatusEvents(ctx context.Context, userID string) ([]models.AccountStatusEvent, error)
 
 	// UserAuth operations
 	CreateUserAuth(ctx context.Context, userAuth *models.UserAuth) error
 	GetUserAuthByUserID(ctx context.Context, userID string) (*models.UserAuth, error)
 	UpdateUserAuth(ctx context.Context, userAuth *models.UserAuth) error
 	DeleteUserAuth(ctx context.Context, userID string) error
-
-	// UserLoginAuth operations
-	CreateUserLoginAuth(ctx context.Context, userLoginAuth *models.UserLoginAuth) error
-	GetUserLoginAuthByUserID(ctx context.Context, userID string) (*models.UserLoginAuth, error)
-	GetUserLoginAuthByRefreshToken(ctx context.Context, refreshToken string) (*models.UserLoginAuth, error)
-	UpdateUserLoginAuth(ctx context.Context, userLoginAuth *models.UserLoginAuth) error
-	DeleteUserLoginAuth(ctx context.Context, userID string) error
+	ChangePassword(ctx context.Context, userID string, passwordHash string) ([]string, error)
+
+	// Password reset operations
+	CreatePasswordResetToken(ctx context.Context, userID string, tokenHash string, expiresAt time.Time) error
+	ResetPassword(ctx context.Context, tokenHash string, passwordHash string) (string, []string, error)
+
+	// Session operations
+	CreateSession(ctx context.Context, session *models.UserSession) error
+	GetSession(ctx context.Context, sessionID string) (*models.UserSession, error)
+	RotateSession(ctx context.Context, session *models.UserSession, oldTokenID string) (bool, error)
+	ListSessions(ctx context.Context, userID string) ([]models.UserSession, error)
+	RevokeSession(ctx context.Context, userID string, sessionID string, reason models.SessionRevokeReason) (bool, error)
+	RevokeSessions(ctx context.Context, userID string, exceptSessionID string, reason models.SessionRevokeReason) ([]string, error)
 }
 
 func NewRepository(db *pgxpool.Pool) Repository {
@@ -70,7 +84,8 @@ func (r *repo) CreateUser(ctx context.Context, user *models.User) error {
 // GetUserByEmail retrieves a user by email
 func (r *repo) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
 	query := `
-		SELECT user_id, user_name, email, role, contact, created_at, updated_at
+		SELECT user_id, user_name, email, role, contact, created_at, updated_at,
+			account_status, suspended_until, status_reason, email_verified_at
 		FROM users 
 		WHERE email = $1
 	`
@@ -86,6 +101,10 @@ func (r *repo) GetUserByEmail(ctx context.Context, email string) (*models.User,
 		&contactJSON,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.AccountStatus,
+		&user.SuspendedUntil,
+		&user.StatusReason,
+		&user.EmailVerifiedAt,
 	)
 
 	if err != nil {
@@ -103,8 +122,9 @@ func (r *repo) GetUserByEmail(ctx context.Context, email string) (*models.User,
 // GetUserByID retrieves a user by ID
 func (r *repo) GetUserByID(ctx context.Context, userID string) (*models.User, error) {
 	query := `
-		SELECT user_id, user_name, email, role, contact, created_at, updated_at
-		FROM users 
+		SELECT user_id, user_name, email, role, contact, created_at, updated_at,
+			account_status, suspended_until, status_reason, email_verified_at
+		FROM users
 		WHERE user_id = $1
 	`
 
@@ -119,6 +139,10 @@ func (r *repo) GetUserByID(ctx context.Context, userID string) (*models.User, er
 		&contactJSON,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.AccountStatus,
+		&user.SuspendedUntil,
+		&user.StatusReason,
+		&user.EmailVerifiedAt,
 	)
 
 	if err != nil {
@@ -133,13 +157,29 @@ func (r *repo) GetUserByID(ctx context.Context, userID string) (*models.User, er
 	return &user, nil
 }
 
-// UpdateUser updates an existing user
+// GetSellerRating aggregates the reviews buyers have left for a user as a seller
+func (r *repo) GetSellerRating(ctx context.Context, userID string) (*models.RatingSummary, error) {
+	query := `
+		SELECT COALESCE(AVG(rating), 0)::float8, COUNT(*)::int
+		FROM reviews
+		WHERE reviewee_user_id = $1 AND reviewer_role = 'BUYER'
+	`
+
+	var rating models.RatingSummary
+	if err := r.db.QueryRow(ctx, query, userID).Scan(&rating.Average, &rating.ReviewCount); err != nil {
+		return nil, err
+	}
+	return &rating, nil
+}
+
+// UpdateUser updates an existing user. Changing the email clears its verification.
 func (r *repo) UpdateUser(ctx context.Context, user *models.User) (*models.User, error) {
 	query := `
 		UPDATE users 
-		SET user_name = $2, email = $3, contact = $4, updated_at = now()
+		SET user_name = $2, email = $3, contact = $4, updated_at = now(),
+			email_verified_at = CASE WHEN lower(email) = lower($3) THEN email_verified_at END
 		WHERE user_id = $1
-		RETURNING user_id, user_name, email, contact, created_at, updated_at
+		RETURNING user_id, user_name, email, contact, created_at, updated_at, email_verified_at
 	`
 
 	contactJSON, err := json.Marshal(user.Contact)
@@ -159,6 +199,7 @@ func (r *repo) UpdateUser(ctx context.Context, user *models.User) (*models.User,
 		&updatedUser.Contact,
 		&updatedUser.CreatedAt,
 		&updatedUser.UpdatedAt,
+		&updatedUser.EmailVerifiedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update user: %w", err)
@@ -167,6 +208,19 @@ func (r *repo) UpdateUser(ctx context.Context, user *models.User) (*models.User,
 	return &updatedUser, nil
 }
 
+// MarkEmailVerified records that userID confirmed email, provided it is still their
+// address, and returns when it was verified
+func (r *repo) MarkEmailVerified(ctx context.Context, userID string, email string) (time.Time, error) {
+	var verifiedAt time.Time
+	err := r.db.QueryRow(ctx, `
+		UPDATE users
+		SET email_verified_at = COALESCE(email_verified_at, now()), updated_at = now()
+		WHERE user_id = $1 AND email = $2
+		RETURNING email_verified_at
+	`, userID, email).Scan(&verifiedAt)
+	return verifiedAt, err
+}
+
 // DeleteUser deletes a user by ID
 func (r *repo) DeleteUser(ctx context.Context, userID string) error {
 	query := `DELETE FROM users WHERE user_id = $1`
@@ -241,103 +295,212 @@ func (r *repo) DeleteUserAuth(ctx context.Context, userID string) error {
 	return err
 }
 
-// UserLoginAuth methods
+// ChangePassword replaces a user's password hash, revokes their sessions and any
+// outstanding reset links, and returns the revoked session IDs
+func (r *repo) ChangePassword(ctx context.Context, userID string, passwordHash string) ([]string, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
 
-// CreateUserLoginAuth creates a new user login authentication record
-func (r *repo) CreateUserLoginAuth(ctx context.Context, userLoginAuth *models.UserLoginAuth) error {
-	query := `
-		INSERT INTO user_login_auth (user_id, access_token, refresh_token, expires_at, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
-	`
+	revoked, err := setPassword(ctx, tx, userID, passwordHash)
+	if err != nil {
+		return nil, err
+	}
+	return revoked, tx.Commit(ctx)
+}
 
-	_, err := r.db.Exec(ctx, query,
-		userLoginAuth.UserId,
-		userLoginAuth.AccessToken,
-		userLoginAuth.RefreshToken,
-		userLoginAuth.ExpiresAt,
-		userLoginAuth.CreatedAt,
-		userLoginAuth.UpdatedAt,
-	)
+// CreatePasswordResetToken stores a reset token hash for userID. Earlier links the user
+// hasn't used stop working, so only the latest email can reset the password.
+func (r *repo) CreatePasswordResetToken(ctx context.Context, userID string, tokenHash string, expiresAt time.Time) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
 
-	return err
+	if _, err := tx.Exec(ctx, `UPDATE password_reset_tokens SET used_at = now() WHERE user_id = $1 AND used_at IS NULL`, userID); err != nil {
+		return fmt.Errorf("failed to expire reset tokens: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO password_reset_tokens (user_id, token_hash, expires_at)
+		VALUES ($1, $2, $3)
+	`, userID, tokenHash, expiresAt); err != nil {
+		return fmt.Errorf("failed to store reset token: %w", err)
+	}
+	return tx.Commit(ctx)
 }
 
-// GetUserLoginAuthByUserID retrieves user login authentication by user ID
-func (r *repo) GetUserLoginAuthByUserID(ctx context.Context, userID string) (*models.UserLoginAuth, error) {
-	query := `
-		SELECT user_id, access_token, refresh_token, expires_at, created_at, updated_at
-		FROM user_login_auth 
-		WHERE user_id = $1
-	`
-
-	var userLoginAuth models.UserLoginAuth
+// ResetPassword uses up the reset token with tokenHash and sets the password of the
+// user it belongs to, returning their ID and the sessions it revoked. An unknown, used
+// or expired token returns pgx.ErrNoRows.
+func (r *repo) ResetPassword(ctx context.Context, tokenHash string, passwordHash string) (string, []string, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var userID string
+	err = tx.QueryRow(ctx, `
+		UPDATE password_reset_tokens SET used_at = now()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > now()
+		RETURNING user_id
+	`, tokenHash).Scan(&userID)
+	if err != nil {
+		return "", nil, err
+	}
 
-	err := r.db.QueryRow(ctx, query, userID).Scan(
-		&userLoginAuth.UserId,
-		&userLoginAuth.AccessToken,
-		&userLoginAuth.RefreshToken,
-		&userLoginAuth.ExpiresAt,
-		&userLoginAuth.CreatedAt,
-		&userLoginAuth.UpdatedAt,
-	)
+	revoked, err := setPassword(ctx, tx, userID, passwordHash)
+	if err != nil {
+		return "", nil, err
+	}
+	return userID, revoked, tx.Commit(ctx)
+}
 
+// setPassword stores a new password hash and ends everything the old password was
+// good for: login sessions and unused reset links. It returns the revoked session IDs.
+func setPassword(ctx context.Context, tx pgx.Tx, userID string, passwordHash string) ([]string, error) {
+	tag, err := tx.Exec(ctx, `UPDATE user_auth SET password = $2, updated_at = now() WHERE user_id = $1`, userID, passwordHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update password: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, pgx.ErrNoRows
+	}
+	revoked, err := revokeSessions(ctx, tx, userID, "", models.SessionPasswordChanged)
 	if err != nil {
 		return nil, err
 	}
-
-	return &userLoginAuth, nil
+	if _, err := tx.Exec(ctx, `UPDATE password_reset_tokens SET used_at = now() WHERE user_id = $1 AND used_at IS NULL`, userID); err != nil {
+		return nil, fmt.Errorf("failed to expire reset tokens: %w", err)
+	}
+	return revoked, nil
 }
 
-// GetUserLoginAuthByRefreshToken retrieves user login authentication by refresh token
-func (r *repo) GetUserLoginAuthByRefreshToken(ctx context.Context, refreshToken string) (*models.UserLoginAuth, error) {
-	query := `
-		SELECT user_id, access_token, refresh_token, expires_at, created_at, updated_at
-		FROM user_login_auth 
-		WHERE refresh_token = $1
-	`
+// Session methods
 
-	var userLoginAuth models.UserLoginAuth
+// CreateSession stores a new login session
+func (r *repo) CreateSession(ctx context.Context, session *models.UserSession) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO user_sessions (session_id, user_id, refresh_token_id, user_agent, ip_address, created_at, last_used_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6, $7)
+	`, session.SessionID, session.UserId, session.RefreshTokenID, session.UserAgent, session.IPAddress, session.CreatedAt, session.ExpiresAt)
+	return err
+}
 
-	err := r.db.QueryRow(ctx, query, refreshToken).Scan(
-		&userLoginAuth.UserId,
-		&userLoginAuth.AccessToken,
-		&userLoginAuth.RefreshToken,
-		&userLoginAuth.ExpiresAt,
-		&userLoginAuth.CreatedAt,
-		&userLoginAuth.UpdatedAt,
+// GetSession retrieves a session by ID, revoked or not
+func (r *repo) GetSession(ctx context.Context, sessionID string) (*models.UserSession, error) {
+	var session models.UserSession
+	err := r.db.QueryRow(ctx, `
+		SELECT session_id, user_id, refresh_token_id, COALESCE(user_agent, ''), COALESCE(ip_address, ''),
+			created_at, last_used_at, expires_at, revoked_at
+		FROM user_sessions
+		WHERE session_id = $1
+	`, sessionID).Scan(
+		&session.SessionID,
+		&session.UserId,
+		&session.RefreshTokenID,
+		&session.UserAgent,
+		&session.IPAddress,
+		&session.CreatedAt,
+		&session.LastUsedAt,
+		&session.ExpiresAt,
+		&session.RevokedAt,
 	)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// RotateSession moves a live session from refresh token oldTokenID to newTokenID and
+// records where it was used from. It returns false when the session no longer accepts
+// oldTokenID, e.g. because another refresh rotated it first.
+func (r *repo) RotateSession(ctx context.Context, session *models.UserSession, oldTokenID string) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE user_sessions
+		SET refresh_token_id = $3, user_agent = $4, ip_address = $5, last_used_at = now(), expires_at = $6
+		WHERE session_id = $1 AND refresh_token_id = $2 AND revoked_at IS NULL
+	`, session.SessionID, oldTokenID, session.RefreshTokenID, session.UserAgent, session.IPAddress, session.ExpiresAt)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
 
+// ListSessions returns a user's live sessions, most recently used first
+func (r *repo) ListSessions(ctx context.Context, userID string) ([]models.UserSession, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT session_id, user_id, COALESCE(user_agent, ''), COALESCE(ip_address, ''), created_at, last_used_at, expires_at
+		FROM user_sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > now()
+		ORDER BY last_used_at DESC
+	`, userID)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	return &userLoginAuth, nil
+	sessions := []models.UserSession{}
+	for rows.Next() {
+		var session models.UserSession
+		if err := rows.Scan(
+			&session.SessionID,
+			&session.UserId,
+			&session.UserAgent,
+			&session.IPAddress,
+			&session.CreatedAt,
+			&session.LastUsedAt,
+			&session.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
 }
 
-// UpdateUserLoginAuth updates user login authentication
-func (r *repo) UpdateUserLoginAuth(ctx context.Context, userLoginAuth *models.UserLoginAuth) error {
-	query := `
-		UPDATE user_login_auth 
-		SET access_token = $2, refresh_token = $3, expires_at = $4, updated_at = $5
-		WHERE user_id = $1
-	`
+// RevokeSession ends one of a user's live sessions. It returns false when the user
+// has no such live session.
+func (r *repo) RevokeSession(ctx context.Context, userID string, sessionID string, reason models.SessionRevokeReason) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE user_sessions SET revoked_at = now(), revoked_reason = $3
+		WHERE session_id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`, sessionID, userID, reason)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
 
-	_, err := r.db.Exec(ctx, query,
-		userLoginAuth.UserId,
-		userLoginAuth.AccessToken,
-		userLoginAuth.RefreshToken,
-		userLoginAuth.ExpiresAt,
-		userLoginAuth.UpdatedAt,
-	)
+// RevokeSessions ends every live session of a user except exceptSessionID (which may be
+// empty) and returns the IDs it ended
+func (r *repo) RevokeSessions(ctx context.Context, userID string, exceptSessionID string, reason models.SessionRevokeReason) ([]string, error) {
+	return revokeSessions(ctx, r.db, userID, exceptSessionID, reason)
+}
 
-	return err
+// querier is a pool or a transaction
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
 }
 
-// DeleteUserLoginAuth deletes user login authentication by user ID
-func (r *repo) DeleteUserLoginAuth(ctx context.Context, userID string) error {
-	query := `DELETE FROM user_login_auth WHERE user_id = $1`
-	_, err := r.db.Exec(ctx, query, userID)
-	return err
+// revokeSessions is RevokeSessions for use inside a transaction
+func revokeSessions(ctx context.Context, q querier, userID string, exceptSessionID string, reason models.SessionRevokeReason) ([]string, error) {
+	rows, err := q.Query(ctx, `
+		UPDATE user_sessions SET revoked_at = now(), revoked_reason = $3
+		WHERE user_id = $1 AND revoked_at IS NULL AND ($2 = '' OR session_id::text <> $2)
+		RETURNING session_id::text
+	`, userID, exceptSessionID, reason)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return ids, nil
 }
 
 // SearchUsers searches users by ID, username, or email with pagination
@@ -392,3 +555,84 @@ func (r *repo) SearchUsers(ctx context.Context, query string, excludeUserID stri
 
 	return results, nil
 }
+
+// AccountStatusChange is an admin action that moves a user to a new account status
+type AccountStatusChange struct {
+	UserID         string
+	AdminUserID    string
+	Action         models.AccountAction
+	Status         models.AccountStatus
+	SuspendedUntil *time.Time
+	Reason         *string
+}
+
+// SetAccountStatus applies change and appends it to the user's audit trail in one
+// transaction. Restricting an account also ends its login sessions, whose IDs it returns.
+func (r *repo) SetAccountStatus(ctx context.Context, change AccountStatusChange) ([]string, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var previous models.AccountStatus
+	err = tx.QueryRow(ctx, `SELECT account_status FROM users WHERE user_id = $1 FOR UPDATE`, change.UserID).Scan(&previous)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE users
+		SET account_status = $2, suspended_until = $3, status_reason = $4,
+			status_changed_by = $5, status_changed_at = now(), updated_at = now()
+		WHERE user_id = $1
+	`, change.UserID, change.Status, change.SuspendedUntil, change.Reason, change.AdminUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update account status: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO account_status_events (user_id, action, previous_status, new_status, suspended_until, reason, admin_user_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, change.UserID, change.Action, previous, change.Status, change.SuspendedUntil, change.Reason, change.AdminUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record account status event: %w", err)
+	}
+
+	var revoked []string
+	if change.Status != models.AccountActive {
+		revoked, err = revokeSessions(ctx, tx, change.UserID, "", models.SessionAccountRestricted)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return revoked, tx.Commit(ctx)
+}
+
+// ListAccountStatusEvents returns a user's account status history, newest first
+func (r *repo) ListAccountStatusEvents(ctx context.Context, userID string) ([]models.AccountStatusEvent, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, user_id::text, action, previous_status, new_status, suspended_until, reason, admin_user_id::text, created_at
+		FROM account_status_events
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list account status events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.AccountStatusEvent{}
+	for rows.Next() {
+		var e models.AccountStatusEvent
+		if err := rows.Scan(&e.ID, &e.UserID, &e.Action, &e.PreviousStatus, &e.NewStatus, &e.SuspendedUntil, &e.Reason, &e.AdminUserID, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan account status event: %w", err)
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating account status events: %w", err)
+	}
+	return events, nil
+}
@@ -1,6 +1,8 @@
 package users
 
 import (
+	"time"
+
 	"github.com/kunal768/cmpe202/orchestrator/models"
 )
 
@@ -53,6 +55,49 @@ type EventsVerifyResponse struct {
 	Valid   bool   `json:"valid"`
 }
 
+// Email Verification Request/Response
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type VerifyEmailResponse struct {
+	Message string      `json:"message"`
+	User    models.User `json:"user"`
+}
+
+// Password Request/Response
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=6"`
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=6"`
+}
+
+// ChangePasswordResponse carries a fresh session, since changing the password signs
+// out every existing one
+type ChangePasswordResponse struct {
+	Message      string `json:"message"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Session Responses
+type SessionsResponse struct {
+	Sessions []models.UserSession `json:"sessions"`
+}
+
+type RevokeSessionsResponse struct {
+	Message string `json:"message"`
+	Revoked int    `json:"revoked"`
+}
+
 // Error Response
 type ErrorResponse struct {
 	Error   string `json:"error"`
@@ -82,3 +127,22 @@ type UpdateUserResponse struct {
 type DeleteUserResponse struct {
 	Message string `json:"message"`
 }
+
+// AccountStatusRequest is the body of the admin suspend, unsuspend and ban endpoints.
+// Reason is required to suspend or ban. A suspension ends at Until, or after
+// DurationHours, or lasts until lifted when neither is given.
+type AccountStatusRequest struct {
+	Reason        string     `json:"reason"`
+	Until         *time.Time `json:"until,omitempty"`
+	DurationHours *int       `json:"duration_hours,omitempty"`
+}
+
+type AccountStatusResponse struct {
+	Message string      `json:"message"`
+	User    models.User `json:"user"`
+}
+
+type AccountStatusHistoryResponse struct {
+	UserID string                      `json:"user_id"`
+	Events []models.AccountStatusEvent `json:"events"`
+}
@@ -2,41 +2,90 @@ package users
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	httplib "github.com/kunal768/cmpe202/http-lib"
+	"github.com/kunal768/cmpe202/http-lib/clients"
 	"github.com/kunal768/cmpe202/orchestrator/internal/queue"
+	"github.com/kunal768/cmpe202/orchestrator/internal/sessions"
 	"github.com/kunal768/cmpe202/orchestrator/models"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type svc struct {
-	repo      Repository
-	publisher queue.Publisher
+	repo        Repository
+	publisher   queue.Publisher
+	email       EmailConfig
+	revocations sessions.Revocations
 }
 
 type Service interface {
-	Signup(ctx context.Context, req SignupRequest) (*SignupResponse, error)
-	Login(ctx context.Context, req LoginRequest) (*LoginResponse, error)
-	RefreshToken(ctx context.Context, req RefreshTokenRequest) (*RefreshTokenResponse, error)
+	Signup(ctx context.Context, req SignupRequest, client ClientInfo) (*SignupResponse, error)
+	Login(ctx context.Context, req LoginRequest, client ClientInfo) (*LoginResponse, error)
+	RefreshToken(ctx context.Context, req RefreshTokenRequest, client ClientInfo) (*RefreshTokenResponse, error)
 	GetUserByID(ctx context.Context, userID string) (*models.User, error)
+	GetUserProfile(ctx context.Context, userID string) (*models.User, error)
 	SearchUsers(ctx context.Context, query string, excludeUserID string, page int, limit int) ([]models.User, error)
 	UpdateUser(ctx context.Context, req UpdateUserRequest) (*UpdateUserResponse, error)
 	DeleteUser(ctx context.Context, userID string) error
+	SuspendUser(ctx context.Context, adminID string, userID string, until *time.Time, reason string) (*models.User, error)
+	UnsuspendUser(ctx context.Context, adminID string, userID string, reason string) (*models.User, error)
+	BanUser(ctx context.Context, adminID string, userID string, reason string) (*models.User, error)
+	GetAccountStatusHistory(ctx context.Context, userID string) ([]models.AccountStatusEvent, error)
+	VerifyEmail(ctx context.Context, token string) (*models.User, error)
+	ResendVerificationEmail(ctx context.Context, userID string) error
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, req ResetPasswordRequest) error
+	ChangePassword(ctx context.Context, userID string, req ChangePasswordRequest, client ClientInfo) (*ChangePasswordResponse, error)
+	ListSessions(ctx context.Context, userID string, currentSessionID string) ([]models.UserSession, error)
+	RevokeSession(ctx context.Context, userID string, sessionID string, reason models.SessionRevokeReason) error
+	RevokeOtherSessions(ctx context.Context, userID string, currentSessionID string) (int, error)
 }
 
-func NewService(repo Repository, publisher queue.Publisher) Service {
+var (
+	ErrUserNotFound         = errors.New("user not found")
+	ErrTargetIsAdmin        = errors.New("cannot change the account status of an admin")
+	ErrAccountNotRestricted = errors.New("account is not suspended or banned")
+)
+
+// AccountRestrictedError is returned by Login and RefreshToken for suspended and banned users
+type AccountRestrictedError struct {
+	Status  models.AccountStatus
+	Message string
+}
+
+func (e *AccountRestrictedError) Error() string { return e.Message }
+
+func accountRestrictedError(user *models.User) error {
+	state := clients.AccountState{
+		Status:         string(user.AccountStatus),
+		SuspendedUntil: user.SuspendedUntil,
+		Reason:         user.StatusReason,
+	}
+	return &AccountRestrictedError{Status: user.AccountStatus, Message: state.Message()}
+}
+
+func NewService(repo Repository, publisher queue.Publisher, email EmailConfig, revocations sessions.Revocations) Service {
 	return &svc{
-		repo:      repo,
-		publisher: publisher,
+		repo:        repo,
+		publisher:   publisher,
+		email:       email,
+		revocations: revocations,
 	}
 }
 
 // Signup creates a new user account
-func (s *svc) Signup(ctx context.Context, req SignupRequest) (*SignupResponse, error) {
+func (s *svc) Signup(ctx context.Context, req SignupRequest, client ClientInfo) (*SignupResponse, error) {
+	// Only campus addresses may sign up
+	if err := s.email.checkDomain(req.Email); err != nil {
+		return nil, err
+	}
+
 	// Check if user already exists
 	existingUser, err := s.repo.GetUserByEmail(ctx, req.Email)
 	if err == nil && existingUser != nil {
@@ -88,35 +137,20 @@ func (s *svc) Signup(ctx context.Context, req SignupRequest) (*SignupResponse, e
 		return nil, fmt.Errorf("failed to create user authentication: %w", err)
 	}
 
-	// Generate access token
-	accessToken, err := httplib.GenerateJWT(userID)
+	// Open the first login session
+	accessToken, refreshToken, err := s.startSession(ctx, user, client)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate access token: %w", err)
+		return nil, err
 	}
 
-	// Generate refresh token
-	refreshToken, err := httplib.GenerateRefreshToken(userID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
-	}
-
-	// Create user login authentication
-	userLoginAuth := &models.UserLoginAuth{
-		UserId:       userID,
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresAt:    now.Add(24 * time.Hour), // Token expires in 24 hours
-		CreatedAt:    now,
-		UpdatedAt:    now,
-	}
-
-	// Create login auth record
-	if err := s.repo.CreateUserLoginAuth(ctx, userLoginAuth); err != nil {
-		return nil, fmt.Errorf("failed to create user login authentication: %w", err)
+	// The account works without it, so a failed send only means the user has to ask
+	// for another link
+	if err := s.sendVerificationEmail(ctx, user); err != nil {
+		log.Printf("Failed to send verification email to user %s: %v", userID, err)
 	}
 
 	return &SignupResponse{
-		Message:      "User created successfully",
+		Message:      "User created successfully. Check your email to verify your account.",
 		Token:        accessToken,
 		RefreshToken: refreshToken,
 		User:         *user,
@@ -124,7 +158,7 @@ func (s *svc) Signup(ctx context.Context, req SignupRequest) (*SignupResponse, e
 }
 
 // Login authenticates a user and returns a JWT token
-func (s *svc) Login(ctx context.Context, req LoginRequest) (*LoginResponse, error) {
+func (s *svc) Login(ctx context.Context, req LoginRequest, client ClientInfo) (*LoginResponse, error) {
 	// Get user by email
 	user, err := s.repo.GetUserByEmail(ctx, req.Email)
 	if err != nil {
@@ -142,42 +176,15 @@ func (s *svc) Login(ctx context.Context, req LoginRequest) (*LoginResponse, erro
 		return nil, fmt.Errorf("invalid email or password")
 	}
 
-	// Generate access token
-	accessToken, err := httplib.GenerateJWT(user.UserId)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate access token: %w", err)
-	}
-
-	// Generate new refresh token
-	refreshToken, err := httplib.GenerateRefreshToken(user.UserId)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	// The password was right, so it's safe to say why the account can't sign in
+	if user.Restricted(time.Now()) {
+		return nil, accountRestrictedError(user)
 	}
 
-	// Create or update user login authentication
-	now := time.Now()
-	userLoginAuth := &models.UserLoginAuth{
-		UserId:       user.UserId,
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresAt:    now.Add(24 * time.Hour), // Token expires in 24 hours
-		CreatedAt:    now,
-		UpdatedAt:    now,
-	}
-
-	// Check if login auth already exists for this user
-	existingLoginAuth, err := s.repo.GetUserLoginAuthByUserID(ctx, user.UserId)
+	// Each login is its own session, so other devices stay signed in
+	accessToken, refreshToken, err := s.startSession(ctx, user, client)
 	if err != nil {
-		// Create new login auth
-		if err := s.repo.CreateUserLoginAuth(ctx, userLoginAuth); err != nil {
-			return nil, fmt.Errorf("failed to create user login authentication: %w", err)
-		}
-	} else {
-		// Update existing login auth
-		userLoginAuth.CreatedAt = existingLoginAuth.CreatedAt
-		if err := s.repo.UpdateUserLoginAuth(ctx, userLoginAuth); err != nil {
-			return nil, fmt.Errorf("failed to update user login authentication: %w", err)
-		}
+		return nil, err
 	}
 
 	return &LoginResponse{
@@ -188,52 +195,61 @@ func (s *svc) Login(ctx context.Context, req LoginRequest) (*LoginResponse, erro
 	}, nil
 }
 
-// RefreshToken handles token refresh
-func (s *svc) RefreshToken(ctx context.Context, req RefreshTokenRequest) (*RefreshTokenResponse, error) {
+// RefreshToken rotates a session's refresh token. A refresh token the session has
+// already rotated past can only be a copy, so presenting one revokes the session.
+func (s *svc) RefreshToken(ctx context.Context, req RefreshTokenRequest, client ClientInfo) (*RefreshTokenResponse, error) {
 	// Validate refresh token
-	_, err := httplib.ValidateRefreshToken(req.RefreshToken)
+	claims, err := httplib.ValidateRefreshToken(req.RefreshToken)
 	if err != nil {
 		return nil, fmt.Errorf("invalid refresh token: %w", err)
 	}
 
-	// Get user login auth by refresh token from database
-	userLoginAuth, err := s.repo.GetUserLoginAuthByRefreshToken(ctx, req.RefreshToken)
-	if err != nil {
-		return nil, fmt.Errorf("invalid refresh token: %w", err)
+	session, err := s.repo.GetSession(ctx, claims.SessionID)
+	if err != nil || session.UserId != claims.UserID {
+		return nil, fmt.Errorf("invalid refresh token: session not found")
 	}
-
-	// Check if token is expired
-	if time.Now().After(userLoginAuth.ExpiresAt) {
+	if session.RevokedAt != nil {
+		return nil, fmt.Errorf("session has been signed out")
+	}
+	if time.Now().After(session.ExpiresAt) {
 		return nil, fmt.Errorf("refresh token expired")
 	}
+	if session.RefreshTokenID != claims.TokenID {
+		s.revokeForReuse(ctx, session)
+		return nil, ErrRefreshTokenReused
+	}
 
 	// Get user details
-	user, err := s.repo.GetUserByID(ctx, userLoginAuth.UserId)
+	user, err := s.repo.GetUserByID(ctx, session.UserId)
 	if err != nil {
 		return nil, fmt.Errorf("user not found: %w", err)
 	}
+	if user.Restricted(time.Now()) {
+		return nil, accountRestrictedError(user)
+	}
 
-	// Generate new access token
-	accessToken, err := httplib.GenerateJWT(user.UserId)
+	// Rotate to a new refresh token
+	session.RefreshTokenID = uuid.NewString()
+	session.UserAgent = client.UserAgent
+	session.IPAddress = client.IPAddress
+	session.ExpiresAt = time.Now().Add(httplib.RefreshTokenTTL)
+	rotated, err := s.repo.RotateSession(ctx, session, claims.TokenID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate access token: %w", err)
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	if !rotated {
+		// Someone else used this refresh token between our read and write
+		s.revokeForReuse(ctx, session)
+		return nil, ErrRefreshTokenReused
 	}
 
-	// Generate new refresh token
-	newRefreshToken, err := httplib.GenerateRefreshToken(user.UserId)
+	accessToken, err := httplib.GenerateJWT(user.UserId, session.SessionID, user.EmailVerifiedAt != nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
-
-	// Update user login authentication
-	now := time.Now()
-	userLoginAuth.AccessToken = accessToken
-	userLoginAuth.RefreshToken = newRefreshToken
-	userLoginAuth.ExpiresAt = now.Add(24 * time.Hour) // Token expires in 24 hours
-	userLoginAuth.UpdatedAt = now
-
-	if err := s.repo.UpdateUserLoginAuth(ctx, userLoginAuth); err != nil {
-		return nil, fmt.Errorf("failed to update user login authentication: %w", err)
+	newRefreshToken, err := httplib.GenerateRefreshToken(user.UserId, session.SessionID, session.RefreshTokenID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
 	}
 
 	return &RefreshTokenResponse{
@@ -254,6 +270,22 @@ func (s *svc) GetUserByID(ctx context.Context, userID string) (*models.User, err
 	return user, nil
 }
 
+// GetUserProfile retrieves a user by ID along with their seller rating
+func (s *svc) GetUserProfile(ctx context.Context, userID string) (*models.User, error) {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rating, err := s.repo.GetSellerRating(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rating: %w", err)
+	}
+	user.Rating = rating
+
+	return user, nil
+}
+
 // SearchUsers searches users by ID, username, or email with pagination
 func (s *svc) SearchUsers(ctx context.Context, query string, excludeUserID string, page int, limit int) ([]models.User, error) {
 	trimmedQuery := strings.TrimSpace(query)
@@ -280,6 +312,13 @@ func (s *svc) UpdateUser(ctx context.Context, req UpdateUserRequest) (*UpdateUse
 		return nil, fmt.Errorf("user not found: %w", err)
 	}
 
+	emailChanged := !strings.EqualFold(user.Email, req.Email)
+	if emailChanged {
+		if err := s.email.checkDomain(req.Email); err != nil {
+			return nil, err
+		}
+	}
+
 	// Update user
 	user.UserName = req.UserName
 	user.Email = req.Email
@@ -292,6 +331,13 @@ func (s *svc) UpdateUser(ctx context.Context, req UpdateUserRequest) (*UpdateUse
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
 
+	// A new address has to be verified again
+	if emailChanged {
+		if err := s.sendVerificationEmail(ctx, updatedUser); err != nil {
+			log.Printf("Failed to send verification email to user %s: %v", updatedUser.UserId, err)
+		}
+	}
+
 	return &UpdateUserResponse{
 		Message: "User updated successfully",
 		User:    *updatedUser,
@@ -306,10 +352,11 @@ func (s *svc) DeleteUser(ctx context.Context, userID string) error {
 		return fmt.Errorf("user not found: %w", err)
 	}
 
-	// Delete user login auth
-	if err := s.repo.DeleteUserLoginAuth(ctx, userID); err != nil {
-		// Log but don't fail if login auth doesn't exist
-		fmt.Printf("Warning: failed to delete user login auth for user %s: %v\n", userID, err)
+	// End the user's sessions; deleting the user removes them from the database
+	if revoked, err := s.repo.RevokeSessions(ctx, userID, "", models.SessionAccountRestricted); err != nil {
+		fmt.Printf("Warning: failed to revoke sessions for user %s: %v\n", userID, err)
+	} else {
+		s.cacheRevoked(ctx, revoked)
 	}
 
 	// Delete user auth
@@ -326,6 +373,69 @@ func (s *svc) DeleteUser(ctx context.Context, userID string) error {
 	return nil
 }
 
+// SuspendUser locks a user out until the given time, or until an admin lifts it when until is nil
+func (s *svc) SuspendUser(ctx context.Context, adminID string, userID string, until *time.Time, reason string) (*models.User, error) {
+	return s.changeAccountStatus(ctx, AccountStatusChange{
+		UserID:         userID,
+		AdminUserID:    adminID,
+		Action:         models.AccountActionSuspend,
+		Status:         models.AccountSuspended,
+		SuspendedUntil: until,
+		Reason:         &reason,
+	})
+}
+
+// UnsuspendUser restores a suspended or banned user to ACTIVE
+func (s *svc) UnsuspendUser(ctx context.Context, adminID string, userID string, reason string) (*models.User, error) {
+	change := AccountStatusChange{
+		UserID:      userID,
+		AdminUserID: adminID,
+		Action:      models.AccountActionUnsuspend,
+		Status:      models.AccountActive,
+	}
+	if reason != "" {
+		change.Reason = &reason
+	}
+	return s.changeAccountStatus(ctx, change)
+}
+
+// BanUser locks a user out permanently
+func (s *svc) BanUser(ctx context.Context, adminID string, userID string, reason string) (*models.User, error) {
+	return s.changeAccountStatus(ctx, AccountStatusChange{
+		UserID:      userID,
+		AdminUserID: adminID,
+		Action:      models.AccountActionBan,
+		Status:      models.AccountBanned,
+		Reason:      &reason,
+	})
+}
+
+func (s *svc) changeAccountStatus(ctx context.Context, change AccountStatusChange) (*models.User, error) {
+	user, err := s.repo.GetUserByID(ctx, change.UserID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if user.Role == models.ADMIN {
+		return nil, ErrTargetIsAdmin
+	}
+	if change.Action == models.AccountActionUnsuspend && !user.Restricted(time.Now()) {
+		return nil, ErrAccountNotRestricted
+	}
+
+	revoked, err := s.repo.SetAccountStatus(ctx, change)
+	if err != nil {
+		return nil, fmt.Errorf("failed to change account status: %w", err)
+	}
+	s.cacheRevoked(ctx, revoked)
+
+	return s.repo.GetUserByID(ctx, change.UserID)
+}
+
+// GetAccountStatusHistory returns the audit trail of admin actions on a user's account
+func (s *svc) GetAccountStatusHistory(ctx context.Context, userID string) ([]models.AccountStatusEvent, error) {
+	return s.repo.ListAccountStatusEvents(ctx, userID)
+}
+
 // generateUserID generates a unique user ID
 func generateUserID() (string, error) {
 	id, err := uuid.NewRandom()
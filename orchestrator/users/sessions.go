@@ -0,0 +1,113 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	httplib "github.com/kunal768/cmpe202/http-lib"
+	"github.com/kunal768/cmpe202/orchestrator/models"
+)
+
+var (
+	ErrSessionNotFound    = errors.New("session not found")
+	ErrRefreshTokenReused = errors.New("refresh token was already used; the session has been signed out")
+)
+
+// ClientInfo describes the device a request comes from
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// startSession opens a login session for user and returns its access and refresh tokens
+func (s *svc) startSession(ctx context.Context, user *models.User, client ClientInfo) (string, string, error) {
+	now := time.Now()
+	session := &models.UserSession{
+		SessionID:      uuid.NewString(),
+		UserId:         user.UserId,
+		RefreshTokenID: uuid.NewString(),
+		UserAgent:      client.UserAgent,
+		IPAddress:      client.IPAddress,
+		CreatedAt:      now,
+		ExpiresAt:      now.Add(httplib.RefreshTokenTTL),
+	}
+
+	accessToken, err := httplib.GenerateJWT(user.UserId, session.SessionID, user.EmailVerifiedAt != nil)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate access token: %w", err)
+	}
+	refreshToken, err := httplib.GenerateRefreshToken(user.UserId, session.SessionID, session.RefreshTokenID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	if err := s.repo.CreateSession(ctx, session); err != nil {
+		return "", "", fmt.Errorf("failed to create session: %w", err)
+	}
+	return accessToken, refreshToken, nil
+}
+
+// cacheRevoked tells AuthMiddleWare about revoked sessions so their access tokens stop
+// working right away. The sessions are already revoked in the database, so a failure
+// here only lets those access tokens live out their hour.
+func (s *svc) cacheRevoked(ctx context.Context, sessionIDs []string) {
+	if s.revocations == nil || len(sessionIDs) == 0 {
+		return
+	}
+	if err := s.revocations.Revoke(ctx, sessionIDs...); err != nil {
+		log.Printf("Failed to cache revoked sessions %v: %v", sessionIDs, err)
+	}
+}
+
+// revokeForReuse ends a session whose refresh token was replayed
+func (s *svc) revokeForReuse(ctx context.Context, session *models.UserSession) {
+	log.Printf("Refresh token reuse detected for session %s of user %s; revoking it", session.SessionID, session.UserId)
+	if _, err := s.repo.RevokeSession(ctx, session.UserId, session.SessionID, models.SessionRefreshTokenReuse); err != nil {
+		log.Printf("Failed to revoke session %s: %v", session.SessionID, err)
+		return
+	}
+	s.cacheRevoked(ctx, []string{session.SessionID})
+}
+
+// ListSessions returns the user's live sessions, marking the one currentSessionID belongs to
+func (s *svc) ListSessions(ctx context.Context, userID string, currentSessionID string) ([]models.UserSession, error) {
+	sessions, err := s.repo.ListSessions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].SessionID == currentSessionID
+	}
+	return sessions, nil
+}
+
+// RevokeSession signs out one of the user's sessions
+func (s *svc) RevokeSession(ctx context.Context, userID string, sessionID string, reason models.SessionRevokeReason) error {
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return ErrSessionNotFound
+	}
+	ok, err := s.repo.RevokeSession(ctx, userID, sessionID, reason)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	if !ok {
+		return ErrSessionNotFound
+	}
+	s.cacheRevoked(ctx, []string{sessionID})
+	return nil
+}
+
+// RevokeOtherSessions signs out every session of the user except the current one and
+// returns how many it ended
+func (s *svc) RevokeOtherSessions(ctx context.Context, userID string, currentSessionID string) (int, error) {
+	revoked, err := s.repo.RevokeSessions(ctx, userID, currentSessionID, models.SessionUserRevoked)
+	if err != nil {
+		return 0, err
+	}
+	s.cacheRevoked(ctx, revoked)
+	return len(revoked), nil
+}
@@ -0,0 +1,78 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	httplib "github.com/kunal768/cmpe202/http-lib"
+	"github.com/kunal768/cmpe202/orchestrator/internal/mail"
+	"github.com/kunal768/cmpe202/orchestrator/models"
+)
+
+var (
+	ErrEmailDomainNotAllowed    = errors.New("email domain is not allowed")
+	ErrEmailAlreadyVerified     = errors.New("email is already verified")
+	ErrInvalidVerificationToken = errors.New("verification link is invalid or has expired")
+)
+
+// sendVerificationEmail mails user a link that verifies their current email address
+func (s *svc) sendVerificationEmail(ctx context.Context, user *models.User) error {
+	token, err := httplib.GenerateEmailVerificationToken(user.UserId, user.Email, s.email.VerifyTTL)
+	if err != nil {
+		return fmt.Errorf("failed to generate verification token: %w", err)
+	}
+
+	link := withToken(s.email.VerifyURL, token)
+	body := fmt.Sprintf("Hi %s,\n\nConfirm your campus email address to start listing items and chatting with other students:\n\n%s\n\nThe link expires in %s. If you didn't sign up, you can ignore this email.\n",
+		user.UserName, link, s.email.VerifyTTL)
+
+	return s.email.Mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Verify your campus email",
+		Body:    body,
+	})
+}
+
+// ResendVerificationEmail sends a fresh verification link to an unverified user
+func (s *svc) ResendVerificationEmail(ctx context.Context, userID string) error {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+	if err := s.sendVerificationEmail(ctx, user); err != nil {
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+	return nil
+}
+
+// VerifyEmail marks the email address a verification token was issued for as verified.
+// A token for an address the user has since changed is rejected. Verifying twice is not
+// an error.
+func (s *svc) VerifyEmail(ctx context.Context, token string) (*models.User, error) {
+	userID, email, err := httplib.ValidateEmailVerificationToken(token)
+	if err != nil {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil || !strings.EqualFold(user.Email, email) {
+		return nil, ErrInvalidVerificationToken
+	}
+	if user.EmailVerifiedAt != nil {
+		return user, nil
+	}
+
+	verifiedAt, err := s.repo.MarkEmailVerified(ctx, userID, user.Email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify email: %w", err)
+	}
+	user.EmailVerifiedAt = &verifiedAt
+	log.Printf("Verified email %s for user %s", user.Email, userID)
+	return user, nil
+}